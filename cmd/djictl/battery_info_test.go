@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/djiwifi"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+func TestBatteryInfoWatchWithEmulator(t *testing.T) {
+	ctx := newTestContext(t, logger.LevelWarning)
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoAction4)
+	camera.BatteryPushInterval = 10 * time.Millisecond
+	addr := serveEmulatorUDP(ctx, t, camera)
+
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	defer watchCancel()
+	runner := func(c *cli.Context, action func(ctx context.Context, conn duml.Conn) error) error {
+		ctrl, err := djiwifi.NewController(watchCtx, addr)
+		if err != nil {
+			return err
+		}
+		defer ctrl.Close()
+		return action(watchCtx, ctrl)
+	}
+	newApp := func() (*cli.App, *syncBuffer) {
+		out := &syncBuffer{}
+		return &cli.App{
+			Name:     "djictl",
+			Writer:   out,
+			Commands: connCommands(runner),
+		}, out
+	}
+
+	app, out := newApp()
+	require.NoError(t, app.Run([]string{"djictl", "battery-info"}))
+	require.Equal(t, "Battery capacity: 87%\nRaw: "+fmt.Sprintf("%X", camera.BatteryStatusMessage().Payload)+"\n", string(out.Bytes()),
+		"the experimental fields should not be printed without --experimental")
+
+	app, out = newApp()
+	go func() {
+		for watchCtx.Err() == nil && !strings.Contains(string(out.Bytes()), "charging: false") {
+			time.Sleep(time.Millisecond)
+		}
+		// a few identical pushes should not be printed
+		time.Sleep(50 * time.Millisecond)
+		camera.SetBatteryCharging(true)
+		for watchCtx.Err() == nil && !strings.Contains(string(out.Bytes()), "charging: true") {
+			time.Sleep(time.Millisecond)
+		}
+		watchCancel()
+	}()
+	err := app.Run([]string{"djictl", "battery-info", "--watch", "--experimental"})
+	require.ErrorIs(t, err, context.Canceled)
+
+	lines := strings.Split(strings.TrimSpace(string(out.Bytes())), "\n")
+	require.Len(t, lines, 2, string(out.Bytes()))
+	require.Contains(t, lines[0], "capacity: 87%")
+	require.Contains(t, lines[0], "current: -600mA")
+	require.Contains(t, lines[1], "charging state: charging")
+}
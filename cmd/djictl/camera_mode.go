@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"github.com/xaionaro-go/djictl/pkg/djiapi"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// cameraModeCommand returns the command getting and switching the camera mode.
+func cameraModeCommand(run connRunner) *cli.Command {
+	return &cli.Command{
+		Name:      "camera-mode",
+		Usage:     "Print the camera mode or switch it (allowed values: video, photo, slow-motion, timelapse, hyperlapse; not every model supports every mode) [experimental]",
+		ArgsUsage: "[MODE]",
+		Flags: []cli.Flag{
+			experimentalFlag,
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Value: 5 * time.Second,
+				Usage: "Time to wait for the camera to report the mode",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() > 1 {
+				return fmt.Errorf("expected at most one argument (the mode), got %d", c.NArg())
+			}
+			if err := checkExperimental(c); err != nil {
+				return err
+			}
+			mode := duml.CameraModeUndefined
+			if c.NArg() == 1 {
+				mode = duml.CameraModeFromString(c.Args().First())
+				if mode == duml.CameraModeUndefined {
+					return fmt.Errorf("unknown camera mode '%s'", c.Args().First())
+				}
+			}
+			return run(c, func(ctx context.Context, conn duml.Conn) error {
+				ctx, cancel := context.WithTimeout(ctx, c.Duration("timeout"))
+				defer cancel()
+				camera := djiapi.AppToCamera(conn).WithExperimental()
+				var err error
+				if mode == duml.CameraModeUndefined {
+					mode, err = camera.GetMode(ctx)
+				} else {
+					mode, err = camera.SetMode(ctx, mode)
+				}
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(c.App.Writer, "Camera mode: %s\n", mode)
+				return nil
+			})
+		},
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+func TestCameraModeWithEmulator(t *testing.T) {
+	ctx := newTestContext(t, logger.LevelWarning)
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoPocket3)
+	app, out := newEmulatorConnApp(ctx, t, camera)
+
+	require.NoError(t, app.Run([]string{"djictl", "camera-mode", "--experimental"}))
+	require.NoError(t, app.Run([]string{"djictl", "camera-mode", "--experimental", "slow-motion"}))
+	require.Equal(t, "Camera mode: video\nCamera mode: slow-motion\n", string(out.Bytes()))
+	received := camera.State().Received
+	// set (0x01), 1 item: key 0x0002, 1 byte: 0x03
+	require.Equal(t, []byte{0x01, 0x01, 0x02, 0x00, 0x01, 0x03}, received[len(received)-1].Payload)
+	require.Equal(t, []byte{0x03}, camera.State().Settings[duml.KeyValueKeyCameraMode])
+
+	require.ErrorContains(t, app.Run([]string{"djictl", "camera-mode", "--experimental", "hyperlapse"}), "not supported by osmo-pocket-3")
+	require.ErrorContains(t, app.Run([]string{"djictl", "camera-mode", "--experimental", "panorama"}), "unknown camera mode")
+	require.Len(t, camera.State().Received, len(received), "nothing should be sent for an unsupported mode")
+}
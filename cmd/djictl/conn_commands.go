@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"github.com/xaionaro-go/djictl/pkg/djiapi"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// connRunner connects to a device (using the flags of the command) and
+// calls the action on the established connection.
+type connRunner func(c *cli.Context, action func(ctx context.Context, conn duml.Conn) error) error
+
+// experimentalFlag allows running an experimental command (see djiapi.ErrExperimental).
+var experimentalFlag = &cli.BoolFlag{
+	Name:  "experimental",
+	Usage: "Run the command even though its encoding is assumed, not confirmed (a real device may do something else)",
+}
+
+// checkExperimental returns an error if the command is not allowed by experimentalFlag.
+func checkExperimental(c *cli.Context) error {
+	if !c.Bool(experimentalFlag.Name) {
+		return fmt.Errorf("'%s' is experimental, use --%s to run it anyway", c.Command.FullName(), experimentalFlag.Name)
+	}
+	return nil
+}
+
+// connCommands returns the commands that work over any transport.
+func connCommands(run connRunner) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "camera-ap-info",
+			Usage: "Get camera AP SSID and Password [does not work, yet]",
+			Action: func(c *cli.Context) error {
+				return run(c, func(ctx context.Context, conn duml.Conn) error {
+					err := djiapi.AppToWiFiGroundStation(conn).Pair(ctx)
+					if err != nil {
+						return fmt.Errorf("unable to pair: %w", err)
+					}
+					ssid, psk, err := djiapi.AppToWiFiGroundStation(conn).CameraAPInfo(ctx)
+					if err != nil {
+						return fmt.Errorf("unable to get camera AP info: %w", err)
+					}
+					fmt.Fprintf(c.App.Writer, "SSID: %s\nPSK: %s\n", ssid, psk)
+					return errDone
+				})
+			},
+		},
+		{
+			Name:  "fcc-enable",
+			Usage: "Enable FCC mode [does not work, yet]",
+			Action: func(c *cli.Context) error {
+				return run(c, func(ctx context.Context, conn duml.Conn) error {
+					_, err := djiapi.AppToCamera(conn).SetFCCEnable(ctx, true)
+					return err
+				})
+			},
+		},
+		{
+			Name:  "set-goggles-mode",
+			Usage: "Set Goggles mode [does not work, yet]",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "mode",
+					Value: "usb",
+					Usage: "Mode: usb or normal",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				var mode duml.GogglesMode
+				switch strings.ToLower(c.String("mode")) {
+				case "usb":
+					mode = duml.GogglesModeUSB
+				case "normal":
+					mode = duml.GogglesModeNormal
+				default:
+					return fmt.Errorf("invalid mode: %s", c.String("mode"))
+				}
+				return run(c, func(ctx context.Context, conn duml.Conn) error {
+					_, err := djiapi.AppToGoggles(conn).SetMode(ctx, mode)
+					return err
+				})
+			},
+		},
+		{
+			Name:  "remote-controller-simulator",
+			Usage: "Send Remote Controller simulator data [does not work, yet]",
+			Flags: []cli.Flag{
+				&cli.IntFlag{Name: "right-h", Value: 1024},
+				&cli.IntFlag{Name: "right-v", Value: 1024},
+				&cli.IntFlag{Name: "left-v", Value: 1024},
+				&cli.IntFlag{Name: "left-h", Value: 1024},
+			},
+			Action: func(c *cli.Context) error {
+				return run(c, func(ctx context.Context, conn duml.Conn) error {
+					data := duml.RemoteControllerSimulatorData{
+						RightStickHorizontal: uint16(c.Int("right-h")),
+						RightStickVertical:   uint16(c.Int("right-v")),
+						LeftStickVertical:    uint16(c.Int("left-v")),
+						LeftStickHorizontal:  uint16(c.Int("left-h")),
+					}
+					return djiapi.AppToRemoteController(conn).SendData(ctx, data)
+				})
+			},
+		},
+		{
+			Name:  "rtmp-broadcast",
+			Usage: "Configure RTMP broadcast [does not work, yet]",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "url", Usage: "RTMP URL", Required: true},
+				&cli.BoolFlag{Name: "disable", Usage: "Disable (instead of enable)"},
+			},
+			Action: func(c *cli.Context) error {
+				return run(c, func(ctx context.Context, conn duml.Conn) error {
+					_, err := djiapi.AppToVideoTransmission(conn).ConfigureRTMP(ctx, c.String("url"), !c.Bool("disable"))
+					return err
+				})
+			},
+		},
+		{
+			Name:  "battery-info",
+			Usage: "Request battery information (only the capacity is confirmed)",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "watch",
+					Usage: "Keep printing the battery status each time it changes (until interrupted)",
+				},
+				&cli.BoolFlag{
+					Name:  experimentalFlag.Name,
+					Usage: "Also print the charging state, the voltage, etc. (they are experimental, so the values may be wrong)",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				experimental := c.Bool(experimentalFlag.Name)
+				return run(c, func(ctx context.Context, conn duml.Conn) error {
+					if c.Bool("watch") {
+						var prev *duml.BatteryStatus
+						return djiapi.AppToBattery(conn).WatchStatus(ctx, func(status *duml.BatteryStatus) error {
+							if prev != nil && bytes.Equal(prev.Raw, status.Raw) {
+								return nil
+							}
+							prev = status
+							line := fmt.Sprintf("%s %s; raw: %X", time.Now().Format(time.TimeOnly), status, status.Raw)
+							if experimental {
+								line += fmt.Sprintf("; experimental: charging: %t, %s", status.IsCharging(), status.ExperimentalString())
+							}
+							fmt.Fprintln(c.App.Writer, line)
+							return nil
+						})
+					}
+					status, err := djiapi.AppToBattery(conn).GetInfo(ctx)
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(c.App.Writer, "Battery capacity: %s\n", status.Capacity)
+					fmt.Fprintf(c.App.Writer, "Raw: %X\n", status.Raw)
+					if experimental {
+						fmt.Fprintf(c.App.Writer, "Charging (experimental): %t\n", status.IsCharging())
+						fmt.Fprintf(c.App.Writer, "Experimental: %s\n", status.ExperimentalString())
+					}
+					return nil
+				})
+			},
+		},
+		{
+			Name:  "firmware-version",
+			Usage: "Request firmware version of the camera",
+			Action: func(c *cli.Context) error {
+				return run(c, func(ctx context.Context, conn duml.Conn) error {
+					version, err := djiapi.AppToCamera(conn).GetVersion(ctx)
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(c.App.Writer, "Firmware version: %s\n", version.FirmwareVersion)
+					return nil
+				})
+			},
+		},
+		{
+			Name:  "device-info",
+			Usage: "Request the firmware versions, serial numbers and product IDs of the components",
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{
+					Name:  "component",
+					Usage: "Component to query (a name, e.g. camera, or a number); the default is the known components of the cameras",
+				},
+				&cli.DurationFlag{
+					Name:  "timeout",
+					Value: 2 * time.Second,
+					Usage: "Time to wait for each response (not every component answers every query)",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				var components []duml.ComponentID
+				for _, s := range c.StringSlice("component") {
+					component, err := duml.ParseComponentID(s)
+					if err != nil {
+						return err
+					}
+					components = append(components, component)
+				}
+				return run(c, func(ctx context.Context, conn duml.Conn) error {
+					fmt.Fprintf(c.App.Writer, "Device type: %s\n", conn.DeviceType())
+					for _, info := range djiapi.GetDeviceInfo(ctx, conn, components, duml.RequestTimeout(c.Duration("timeout"))) {
+						printComponentInfo(c.App.Writer, info)
+					}
+					return nil
+				})
+			},
+		},
+		recordCommand(run),
+		cameraModeCommand(run),
+		videoFormatCommand(run),
+		exposureCommand(run),
+		gimbalCommand(run),
+		rawCommand(run),
+	}
+}
+
+func recordCommand(run connRunner) *cli.Command {
+	timeoutFlag := &cli.DurationFlag{
+		Name:  "timeout",
+		Value: 5 * time.Second,
+		Usage: "Time to wait for the device to confirm the recording state",
+	}
+	recordAction := func(action duml.RecordAction) cli.ActionFunc {
+		return func(c *cli.Context) error {
+			if err := checkExperimental(c); err != nil {
+				return err
+			}
+			return run(c, func(ctx context.Context, conn duml.Conn) error {
+				ctx, cancel := context.WithTimeout(ctx, c.Duration("timeout"))
+				defer cancel()
+				camera := djiapi.AppToCamera(conn).WithExperimental()
+				var (
+					status *duml.RecordingStatus
+					err    error
+				)
+				switch action {
+				case duml.RecordActionStart:
+					status, err = camera.StartRecording(ctx)
+				case duml.RecordActionStop:
+					status, err = camera.StopRecording(ctx)
+				default:
+					if err := camera.TakeRecord(ctx, action); err != nil {
+						return err
+					}
+					fmt.Fprintf(c.App.Writer, "%s: done\n", action)
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(c.App.Writer, "%s\n", status)
+				return nil
+			})
+		}
+	}
+	return &cli.Command{
+		Name:  "record",
+		Usage: "Control the recording of the camera",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "start",
+				Usage:  "Start recording and wait for the camera to confirm it [experimental]",
+				Flags:  []cli.Flag{experimentalFlag, timeoutFlag},
+				Action: recordAction(duml.RecordActionStart),
+			},
+			{
+				Name:   "stop",
+				Usage:  "Stop recording and wait for the camera to confirm it [experimental]",
+				Flags:  []cli.Flag{experimentalFlag, timeoutFlag},
+				Action: recordAction(duml.RecordActionStop),
+			},
+			{
+				Name:   "photo",
+				Usage:  "Take a photo [experimental]",
+				Flags:  []cli.Flag{experimentalFlag, timeoutFlag},
+				Action: recordAction(duml.RecordActionTakePhoto),
+			},
+			{
+				Name:  "watch",
+				Usage: "Print the recording status each time the camera reports it (until interrupted)",
+				Action: func(c *cli.Context) error {
+					return run(c, func(ctx context.Context, conn duml.Conn) error {
+						return djiapi.AppToCamera(conn).WatchRecordingStatus(ctx, func(status *duml.RecordingStatus) error {
+							fmt.Fprintf(c.App.Writer, "%s %s\n", time.Now().Format(time.TimeOnly), status)
+							return nil
+						})
+					})
+				},
+			},
+		},
+	}
+}
+
+func printComponentInfo(w io.Writer, info djiapi.ComponentInfo) {
+	fmt.Fprintf(w, "%s:\n", info.Component)
+	if info.VersionErr != nil {
+		fmt.Fprintf(w, "  version: <error: %v>\n", info.VersionErr)
+	} else {
+		fmt.Fprintf(w, "  firmware version: %s\n", info.Version.FirmwareVersion)
+		fmt.Fprintf(w, "  loader version: %s\n", info.Version.LoaderVersion)
+		fmt.Fprintf(w, "  hardware version: %s\n", info.Version.HardwareVersion)
+	}
+	if info.SerialNumberErr != nil {
+		fmt.Fprintf(w, "  serial number: <error: %v>\n", info.SerialNumberErr)
+	} else {
+		fmt.Fprintf(w, "  serial number: %s\n", info.SerialNumber)
+	}
+	if info.ProductIDErr != nil {
+		fmt.Fprintf(w, "  product ID: <error: %v>\n", info.ProductIDErr)
+	} else {
+		fmt.Fprintf(w, "  product ID: %s\n", info.ProductID)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/djiwifi"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// serveEmulatorUDP serves the camera over UDP until the end of the test (waiting
+// for the emulator to stop) and returns the address to connect to.
+func serveEmulatorUDP(ctx context.Context, t *testing.T, camera *djiemu.Camera) string {
+	ctx, cancel := context.WithCancel(ctx)
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		camera.ServeUDP(ctx, conn)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-served
+	})
+	return conn.LocalAddr().String()
+}
+
+// newEmulatorConnApp serves the camera over UDP (until the end of the test) and
+// returns the app running connCommands on a djiwifi.Controller connected to it.
+func newEmulatorConnApp(ctx context.Context, t *testing.T, camera *djiemu.Camera) (*cli.App, *syncBuffer) {
+	addr := serveEmulatorUDP(ctx, t, camera)
+	runner := func(c *cli.Context, action func(ctx context.Context, conn duml.Conn) error) error {
+		ctrl, err := djiwifi.NewController(ctx, addr)
+		if err != nil {
+			return err
+		}
+		defer ctrl.Close()
+		ctrl.Type = camera.Type
+		return action(ctx, ctrl)
+	}
+	out := &syncBuffer{}
+	return &cli.App{
+		Name:     "djictl",
+		Writer:   out,
+		Commands: connCommands(runner),
+	}, out
+}
+
+func TestExperimentalCommandsWithEmulator(t *testing.T) {
+	ctx := newTestContext(t, logger.LevelWarning)
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoPocket3)
+	app, _ := newEmulatorConnApp(ctx, t, camera)
+
+	for _, args := range [][]string{
+		{"camera-mode"},
+		{"camera-mode", "slow-motion"},
+		{"video-format"},
+		{"video-format", "--resolution", "4k"},
+		{"exposure"},
+		{"exposure", "--iso", "800"},
+		{"gimbal", "mode", "tilt-locked"},
+		{"gimbal", "move", "--yaw", "30"},
+		{"gimbal", "recenter"},
+		{"record", "start"},
+		{"record", "stop"},
+		{"record", "photo"},
+	} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			require.ErrorContains(t, app.Run(append([]string{"djictl"}, args...)), "--experimental")
+			require.Empty(t, camera.State().Received, "nothing should be sent without --experimental")
+		})
+	}
+}
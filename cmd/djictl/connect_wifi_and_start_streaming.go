@@ -18,6 +18,11 @@ func connectWiFiAndStartStreaming(
 	fps duml.FPS,
 ) error {
 	logger.Infof(ctx, "found device %s; initializing...", dev)
+	if profile, ok := dev.Type.Profile(); ok {
+		if err := profile.CheckLiveStreamConfig(resolution, bitrateKbps, fps); err != nil {
+			logger.Warnf(ctx, "the device profile suggests the stream would not work (trying anyway): %v", err)
+		}
+	}
 
 	err := dev.Init(ctx)
 	if err != nil {
@@ -39,12 +44,13 @@ func connectWiFiAndStartStreaming(
 	if err != nil {
 		return fmt.Errorf("unable to make the device connect to our WiFi: %w", err)
 	}
-	switch dev.Type {
-	case duml.DeviceTypeOsmoAction4, duml.DeviceTypeOsmoAction5Pro:
+	profile, _ := dev.Type.Profile()
+	if profile.DefaultImageStabilization != "" {
+		stabilization := duml.ImageStabilizationFromString(profile.DefaultImageStabilization)
 		logger.Infof(ctx, "set image stabilization")
-		err = dev.AppToCamera().SetImageStabilization(ctx, duml.ImageStabilizationRockSteadyPlus)
+		err = dev.AppToCamera().SetImageStabilization(ctx, stabilization)
 		if err != nil {
-			return fmt.Errorf("unable to set image stabilization to RockSteadyPlus: %w", err)
+			return fmt.Errorf("unable to set image stabilization to %s: %w", stabilization, err)
 		}
 	}
 	logger.Infof(ctx, "start live stream")
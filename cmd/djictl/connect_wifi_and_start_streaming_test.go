@@ -6,7 +6,6 @@ import (
 	"context"
 	"errors"
 	"testing"
-	"time"
 
 	"github.com/facebookincubator/go-belt/tool/logger"
 	"github.com/xaionaro-go/djictl/pkg/djible"
@@ -15,8 +14,7 @@ import (
 )
 
 func TestConnectWiFiAndStartStreaming(t *testing.T) {
-	ctx := getContext(logger.LevelDebug, false, "")
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	ctx, cancel := context.WithCancel(newTestContext(t, logger.LevelDebug))
 	defer cancel()
 
 	// 1. Setup simulated device
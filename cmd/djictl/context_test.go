@@ -0,0 +1,21 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	xlogrus "github.com/facebookincubator/go-belt/tool/logger/implementation/logrus"
+)
+
+// newTestContext returns a context (cancelled at the end of the test) with a
+// logger of the given level.
+//
+// Unlike getContext it does not replace the global logger: the goroutines
+// of a test (e.g. the emulator) must not race with the next test.
+func newTestContext(t *testing.T, level logger.Level) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return logger.CtxWithLogger(ctx, xlogrus.Default().WithLevel(level))
+}
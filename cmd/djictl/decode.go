@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/djictl/pkg/btsnoop"
+	"github.com/xaionaro-go/djictl/pkg/djiwifi"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+	"github.com/xaionaro-go/djictl/pkg/pcap"
+)
+
+type frameDirection int
+
+const (
+	frameDirectionUnknown = frameDirection(iota)
+	frameDirectionToDevice
+	frameDirectionFromDevice
+)
+
+func (d frameDirection) String() string {
+	switch d {
+	case frameDirectionToDevice:
+		return "->"
+	case frameDirectionFromDevice:
+		return "<-"
+	default:
+		return "??"
+	}
+}
+
+type decodedFrame struct {
+	Timestamp time.Time
+	Direction frameDirection
+	Message   *duml.Message
+}
+
+type frameDecoder struct {
+	Decoders map[any]*duml.Decoder
+	Callback func(*decodedFrame) error
+}
+
+func newFrameDecoder(callback func(*decodedFrame) error) *frameDecoder {
+	return &frameDecoder{
+		Decoders: map[any]*duml.Decoder{},
+		Callback: callback,
+	}
+}
+
+// Feed passes the bytes to the duml.Decoder of the given stream, so that
+// fragmented frames are reassembled independently per stream.
+func (d *frameDecoder) Feed(
+	ctx context.Context,
+	streamKey any,
+	ts time.Time,
+	direction frameDirection,
+	b []byte,
+) error {
+	decoder := d.Decoders[streamKey]
+	if decoder == nil {
+		decoder = duml.NewDecoder()
+		d.Decoders[streamKey] = decoder
+	}
+	droppedBefore := decoder.DroppedBytes()
+	msgs := decoder.Decode(b)
+	if dropped := decoder.DroppedBytes() - droppedBefore; dropped > 0 {
+		logger.Warnf(ctx, "skipped %d bytes that are not a valid DUML frame (stream %v)", dropped, streamKey)
+	}
+	for _, msg := range msgs {
+		if err := d.Callback(&decodedFrame{
+			Timestamp: ts,
+			Direction: direction,
+			Message:   msg,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeInputs(
+	ctx context.Context,
+	inputs []string,
+	udpPort uint16,
+	callback func(*decodedFrame) error,
+) error {
+	d := newFrameDecoder(callback)
+	if len(inputs) == 0 {
+		return decodeHexText(ctx, d, "stdin", os.Stdin)
+	}
+	for _, input := range inputs {
+		if _, err := os.Stat(input); err != nil {
+			if err := decodeHexText(ctx, d, "args", strings.NewReader(input)); err != nil {
+				return fmt.Errorf("unable to decode '%s' neither as a file nor as a hex string: %w", input, err)
+			}
+			continue
+		}
+		if err := decodeFile(ctx, d, input, udpPort); err != nil {
+			return fmt.Errorf("unable to decode file '%s': %w", input, err)
+		}
+	}
+	return nil
+}
+
+func decodeFile(
+	ctx context.Context,
+	d *frameDecoder,
+	path string,
+	udpPort uint16,
+) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("unable to open: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	magic, err := r.Peek(8)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("unable to read the beginning of the file: %w", err)
+	}
+
+	switch {
+	case btsnoop.IsBTSnoop(magic):
+		return decodeBTSnoop(ctx, d, r)
+	case pcap.IsPCAP(magic):
+		return decodePCAP(ctx, d, path, r, udpPort)
+	default:
+		return decodeHexText(ctx, d, path, r)
+	}
+}
+
+// decodeHexText decodes lines of hex strings; spaces, colons and "0x" prefixes
+// are ignored, as well as anything after '#'.
+func decodeHexText(
+	ctx context.Context,
+	d *frameDecoder,
+	streamKey string,
+	r io.Reader,
+) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if idx := strings.IndexByte(line, '#'); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.NewReplacer("0x", "", " ", "", "\t", "", ":", "").Replace(line)
+		if line == "" {
+			continue
+		}
+		b, err := hex.DecodeString(line)
+		if err != nil {
+			return fmt.Errorf("unable to parse line %d as hex: %w", lineNum, err)
+		}
+		if err := d.Feed(ctx, streamKey, time.Time{}, frameDirectionUnknown, b); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func decodePCAP(
+	ctx context.Context,
+	d *frameDecoder,
+	path string,
+	r io.Reader,
+	udpPort uint16,
+) error {
+	pcapReader, err := pcap.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("unable to open as a pcap/pcapng file: %w", err)
+	}
+	for {
+		pkt, err := pcapReader.ReadPacket()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("unable to read a packet: %w", err)
+		}
+		udp, err := pkt.UDP()
+		if err != nil {
+			logger.Tracef(ctx, "skipping a non-UDP packet at %s: %v", pkt.Timestamp, err)
+			continue
+		}
+
+		var direction frameDirection
+		switch {
+		case udp.Dst.Port == int(udpPort):
+			direction = frameDirectionToDevice
+		case udp.Src.Port == int(udpPort):
+			direction = frameDirectionFromDevice
+		default:
+			continue
+		}
+
+		wifiPacket, err := djiwifi.ParsePacket(udp.Payload)
+		if err != nil {
+			logger.Debugf(ctx, "unable to parse the djiwifi.Packet at %s: %v", pkt.Timestamp, err)
+			continue
+		}
+		if len(wifiPacket.Payload) == 0 || wifiPacket.Payload[0] != duml.MessageStartMagicByte {
+			continue
+		}
+		streamKey := fmt.Sprintf("%s:%s->%s", path, &udp.Src, &udp.Dst)
+		if err := d.Feed(ctx, streamKey, pkt.Timestamp, direction, wifiPacket.Payload); err != nil {
+			return err
+		}
+	}
+}
+
+func decodeBTSnoop(
+	ctx context.Context,
+	d *frameDecoder,
+	r io.Reader,
+) error {
+	session, err := btsnoop.NewSession(r)
+	if err != nil {
+		return fmt.Errorf("unable to open as a btsnoop file: %w", err)
+	}
+	session.SetLogger(logger.FromCtx(ctx))
+	defer func() {
+		if dropped := session.DroppedBytes(); dropped > 0 {
+			logger.Warnf(ctx, "skipped %d bytes that are not a valid DUML frame", dropped)
+		}
+	}()
+	for {
+		pkt, err := session.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("unable to read a packet: %w", err)
+		}
+		if pkt.IsPairingRequest() {
+			logger.Infof(ctx, "%s: pairing request: %X", pkt.Timestamp.Format(time.RFC3339Nano), pkt.Value)
+			continue
+		}
+		direction := frameDirectionFromDevice
+		if pkt.Direction == btsnoop.DirectionSent {
+			direction = frameDirectionToDevice
+		}
+		if err := d.Callback(&decodedFrame{
+			Timestamp: pkt.Timestamp,
+			Direction: direction,
+			Message:   pkt.Message,
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+func formatFrame(frame *decodedFrame) string {
+	var buf bytes.Buffer
+	if !frame.Timestamp.IsZero() {
+		fmt.Fprintf(&buf, "%s ", frame.Timestamp.UTC().Format("2006-01-02T15:04:05.000000"))
+	}
+	msg := frame.Message
+	fmt.Fprintf(&buf, "%s %-30s ID:%04X %-36s [%s] payload:%s",
+		frame.Direction,
+		msg.Interface,
+		uint16(msg.ID),
+		msg.Type,
+		msg.Type.Flags,
+		formatPayload(msg),
+	)
+	return buf.String()
+}
+
+func formatPayload(msg *duml.Message) string {
+	p, err := msg.DecodePayload()
+	switch {
+	case err == nil:
+		return fmt.Sprintf("%+v", p)
+	case errors.Is(err, duml.ErrUnknownPayload):
+		return fmt.Sprintf("%X", msg.Payload)
+	default:
+		return fmt.Sprintf("%X (unable to decode: %v)", msg.Payload, err)
+	}
+}
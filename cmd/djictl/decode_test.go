@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+func TestDecodeInputs(t *testing.T) {
+	ctx := context.Background()
+
+	hexFile := filepath.Join(t.TempDir(), "frames.txt")
+	require.NoError(t, os.WriteFile(hexFile, []byte(
+		"# a capture copied from a log\n"+
+			"55 1f 04 4e 07 02 ea 94 c0 07 07 00 10 4f 73 6d 6f\n"+
+			"506f636b6574332d36303934ccc8 # the second half of the frame\n",
+	), 0644))
+
+	var frames []*decodedFrame
+	err := decodeInputs(ctx, []string{
+		"DEAD551204c70402f601",
+		"0x0004270000080000299d",
+		hexFile,
+	}, 9004, func(frame *decodedFrame) error {
+		frames = append(frames, frame)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, frames, 2)
+
+	require.Equal(t, duml.MessageTypeKeepAlive, frames[0].Message.Type)
+	require.Equal(t, duml.MessageID(0xF601), frames[0].Message.ID)
+
+	line := formatFrame(frames[1])
+	require.Contains(t, line, "camera_ap_info_result_ssid")
+	require.Contains(t, line, "OsmoPocket3-6094")
+
+	err = decodeInputs(ctx, []string{"not-hex-and-not-a-file"}, 9004, func(*decodedFrame) error { return nil })
+	require.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/djiwifi"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+func TestDeviceInfoWithEmulator(t *testing.T) {
+	ctx := newTestContext(t, logger.LevelWarning)
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoAction5Pro)
+	camera.FirmwareVersion = duml.NewFirmwareVersion(1, 2, 3, 4)
+	camera.SerialNumber = "SN0123456789"
+	addr := serveEmulatorUDP(ctx, t, camera)
+
+	runner := func(c *cli.Context, action func(ctx context.Context, conn duml.Conn) error) error {
+		ctrl, err := djiwifi.NewController(ctx, addr)
+		if err != nil {
+			return err
+		}
+		defer ctrl.Close()
+		return action(ctx, ctrl)
+	}
+	var out bytes.Buffer
+	app := &cli.App{
+		Name:     "djictl",
+		Writer:   &out,
+		Commands: connCommands(runner),
+	}
+
+	require.NoError(t, app.Run([]string{"djictl", "device-info", "--component", "camera", "--component", "battery"}))
+	require.Equal(t, `Device type: <undefined>
+camera:
+  firmware version: 01.02.03.04
+  loader version: 01.02.03.04
+  hardware version: emulator
+  serial number: SN0123456789
+  product ID: osmo-action-5-pro
+battery:
+  firmware version: 01.02.03.04
+  loader version: 01.02.03.04
+  hardware version: emulator
+  serial number: SN0123456789
+  product ID: osmo-action-5-pro
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, app.Run([]string{"djictl", "firmware-version"}))
+	require.Equal(t, "Firmware version: 01.02.03.04\n", out.String())
+
+	require.Error(t, app.Run([]string{"djictl", "device-info", "--component", "nonexistent"}))
+}
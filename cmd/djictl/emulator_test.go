@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"github.com/xaionaro-go/djictl/pkg/djible"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/djirecord"
+	"github.com/xaionaro-go/djictl/pkg/djiwifi"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+	"github.com/xaionaro-go/gatt"
+)
+
+func TestConnectWiFiAndStartStreamingWithEmulator(t *testing.T) {
+	ctx := newTestContext(t, logger.LevelWarning)
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoAction5Pro)
+	camera.PINApprovalDelay = 10 * time.Millisecond
+	bleDev := camera.NewBLEDevice(ctx)
+	defer bleDev.Close()
+	devCh, errCh, err := djible.ScanWithDevice(ctx, bleDev)
+	require.NoError(t, err)
+
+	var dev *djible.Device
+	select {
+	case dev = <-devCh:
+	case err := <-errCh:
+		t.Fatal(err)
+	case <-ctx.Done():
+		t.Fatal("the emulated device was not found")
+	}
+
+	streamCtx, streamCancel := context.WithCancel(ctx)
+	defer streamCancel()
+	go func() {
+		for streamCtx.Err() == nil && !camera.State().Streaming {
+			time.Sleep(time.Millisecond)
+		}
+		streamCancel()
+	}()
+	err = connectWiFiAndStartStreaming(streamCtx, dev, "test-ssid", "test-psk", "rtmp://test/live", duml.Resolution1080p, 6000, duml.FPS30)
+	require.True(t, errors.Is(err, context.Canceled), "%v", err)
+
+	state := camera.State()
+	require.True(t, state.Paired)
+	require.True(t, state.WiFiConnected)
+	require.True(t, state.Streaming)
+	require.Equal(t, "rtmp://test/live", state.LiveStream.URL)
+}
+
+func TestWiFiCommandsWithEmulator(t *testing.T) {
+	ctx := newTestContext(t, logger.LevelWarning)
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoPocket3)
+	addr := serveEmulatorUDP(ctx, t, camera)
+
+	// the same as runConnOnWiFi, but reusing the context of the test
+	runner := func(c *cli.Context, action func(ctx context.Context, conn duml.Conn) error) error {
+		ctrl, err := djiwifi.NewController(ctx, addr)
+		if err != nil {
+			return err
+		}
+		defer ctrl.Close()
+		if err := ctrl.SendHandshake(ctx); err != nil {
+			return err
+		}
+		return action(ctx, ctrl)
+	}
+	app := &cli.App{
+		Name:     "djictl",
+		Commands: connCommands(runner),
+	}
+
+	for _, args := range [][]string{
+		{"battery-info"},
+		{"fcc-enable"},
+		{"rtmp-broadcast", "--url", "rtmp://test/live"},
+		{"camera-ap-info"},
+	} {
+		err := app.Run(append([]string{"djictl"}, args...))
+		if errors.Is(err, errDone) {
+			err = nil
+		}
+		require.NoError(t, err, "%v", args)
+	}
+
+	var received []duml.MessageType
+	for _, msg := range camera.State().Received {
+		received = append(received, msg.Type)
+	}
+	require.Contains(t, received, duml.MessageTypeGetBatteryInfo)
+	require.Contains(t, received, duml.MessageTypeFCCSupport)
+	require.Contains(t, received, duml.MessageTypeOsmoBroadcastConfig)
+	require.Contains(t, received, duml.MessageTypeCameraAPInfo)
+}
+
+func TestConnectWiFiAndStartStreamingReplay(t *testing.T) {
+	ctx := newTestContext(t, logger.LevelWarning)
+
+	scan := func(d gatt.Device) *djible.Device {
+		devCh, errCh, err := djible.ScanWithDevice(ctx, d)
+		require.NoError(t, err)
+		select {
+		case dev := <-devCh:
+			return dev
+		case err := <-errCh:
+			t.Fatal(err)
+		case <-ctx.Done():
+			t.Fatal("the device was not found")
+		}
+		return nil
+	}
+	// run stops the streaming once the response to the start of the streaming
+	// (the first key-value set after the live stream configuration) is received
+	run := func(dev *djible.Device, onFrame djible.FrameHook) {
+		streamCtx, streamCancel := context.WithCancel(ctx)
+		defer streamCancel()
+		var configured atomic.Bool
+		dev.OnFrame = func(ctx context.Context, direction duml.Direction, handle uint16, b []byte) {
+			if onFrame != nil {
+				onFrame(ctx, direction, handle, b)
+			}
+			msg, err := duml.ParseMessage(b)
+			if err != nil || direction != duml.DirectionReceived || msg.Type.Flags&duml.MessageTypeFlagResponse == 0 {
+				return
+			}
+			switch msg.Type.WithFlags(duml.MessageTypeFlagAckRequired) {
+			case duml.MessageTypeConfigureStreaming:
+				configured.Store(true)
+			case duml.MessageTypeStartStopStreaming:
+				if configured.Load() {
+					streamCancel()
+				}
+			}
+		}
+		err := connectWiFiAndStartStreaming(streamCtx, dev, "test-ssid", "test-psk", "rtmp://test/live", duml.Resolution1080p, 6000, duml.FPS30)
+		require.True(t, errors.Is(err, context.Canceled), "%v", err)
+	}
+
+	// record a session with the emulator, as '--record' does with a real device
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoAction5Pro)
+	camera.PINApprovalDelay = 10 * time.Millisecond
+	var transcript bytes.Buffer
+	bleDev := camera.NewBLEDevice(ctx)
+	defer bleDev.Close()
+	run(scan(bleDev), djirecord.NewRecorder(&transcript).BLEFrameHook())
+	require.True(t, camera.State().Streaming)
+
+	// replay it without the emulator
+	entries, err := djirecord.ReadEntries(&transcript)
+	require.NoError(t, err)
+	replayer := djirecord.NewReplayer(duml.DeviceTypeOsmoAction5Pro, entries)
+	replayDev := djiemu.NewBLEDevice(ctx, replayer)
+	defer replayDev.Close()
+	run(scan(replayDev), nil)
+	require.Zero(t, replayer.Pending())
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"github.com/xaionaro-go/djictl/pkg/djiapi"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// exposureCommand returns the command getting and setting the exposure settings.
+func exposureCommand(run connRunner) *cli.Command {
+	return &cli.Command{
+		Name:  "exposure",
+		Usage: "Print the exposure settings or change them (the values not given are kept) [experimental]",
+		Flags: []cli.Flag{
+			experimentalFlag,
+			&cli.Float64Flag{
+				Name:  "ev",
+				Usage: "EV compensation, from -3.0 to 3.0",
+			},
+			&cli.StringFlag{
+				Name:  "iso",
+				Usage: "ISO: 'auto' or the value (e.g. 800)",
+			},
+			&cli.UintFlag{
+				Name:  "iso-max",
+				Usage: "The maximal ISO in the auto ISO mode (0 means not limited)",
+			},
+			&cli.StringFlag{
+				Name:  "shutter",
+				Usage: "Shutter speed: 'auto', a fraction of a second (e.g. 1/120) or a duration (e.g. 0.5s)",
+			},
+			&cli.StringFlag{
+				Name:  "white-balance",
+				Usage: "White balance: auto, sunny, cloudy, incandescent, fluorescent or a color temperature (e.g. 5600K)",
+			},
+			&cli.StringFlag{
+				Name:  "color-profile",
+				Usage: "Color profile (allowed values: normal, d-log-m)",
+			},
+			&cli.BoolFlag{
+				Name:  "watch",
+				Usage: "Keep printing the exposure settings each time the camera reports them (until interrupted)",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Value: 5 * time.Second,
+				Usage: "Time to wait for the camera to report the settings",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if err := checkExperimental(c); err != nil {
+				return err
+			}
+			change, err := exposureChangeFromFlags(c)
+			if err != nil {
+				return err
+			}
+			return run(c, func(ctx context.Context, conn duml.Conn) error {
+				camera := djiapi.AppToCamera(conn).WithExperimental()
+				if c.Bool("watch") {
+					return camera.WatchExposure(ctx, func(settings *duml.ExposureSettings) error {
+						fmt.Fprintf(c.App.Writer, "%s %s\n", time.Now().Format(time.TimeOnly), settings)
+						return nil
+					})
+				}
+				ctx, cancel := context.WithTimeout(ctx, c.Duration("timeout"))
+				defer cancel()
+				settings, err := camera.SetExposure(ctx, change)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(c.App.Writer, "Exposure: %s\n", settings)
+				return nil
+			})
+		},
+	}
+}
+
+// exposureChangeFromFlags returns the settings given by the flags; the other fields are nil.
+func exposureChangeFromFlags(c *cli.Context) (duml.ExposureSettings, error) {
+	var change duml.ExposureSettings
+	if c.IsSet("ev") {
+		ev, err := duml.EVCompensationFromFloat(c.Float64("ev"))
+		if err != nil {
+			return change, err
+		}
+		change.EV = &ev
+	}
+	switch s := strings.ToLower(c.String("iso")); {
+	case s == "auto" || (s == "" && c.IsSet("iso-max")):
+		change.ISO = &duml.ISOSetting{Mode: duml.ISOModeAuto, Max: uint16(c.Uint("iso-max"))}
+	case s != "":
+		if c.IsSet("iso-max") {
+			return change, fmt.Errorf("--iso-max is applicable only to the auto ISO mode")
+		}
+		v, err := strconv.ParseUint(s, 10, 16)
+		if err != nil {
+			return change, fmt.Errorf("invalid ISO '%s' (expected 'auto' or a number)", s)
+		}
+		change.ISO = &duml.ISOSetting{Mode: duml.ISOModeManual, Value: uint16(v)}
+	}
+	switch s := strings.ToLower(c.String("shutter")); s {
+	case "":
+	case "auto":
+		change.Shutter = &duml.ShutterSetting{Mode: duml.ShutterModeAuto}
+	default:
+		speed, err := duml.ParseShutterSpeed(s)
+		if err != nil {
+			return change, err
+		}
+		change.Shutter = &duml.ShutterSetting{Mode: duml.ShutterModeManual, Speed: speed}
+	}
+	if s := c.String("white-balance"); s != "" {
+		wb, err := duml.ParseWhiteBalance(s)
+		if err != nil {
+			return change, err
+		}
+		change.WhiteBalance = &wb
+	}
+	if s := c.String("color-profile"); s != "" {
+		profile, err := duml.ColorProfileFromString(s)
+		if err != nil {
+			return change, err
+		}
+		change.ColorProfile = &profile
+	}
+	return change, change.Validate()
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+func TestExposureWithEmulator(t *testing.T) {
+	ctx := newTestContext(t, logger.LevelWarning)
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoAction3)
+	app, out := newEmulatorConnApp(ctx, t, camera)
+
+	require.NoError(t, app.Run([]string{"djictl", "exposure", "--experimental"}))
+	require.NoError(t, app.Run([]string{"djictl", "exposure", "--experimental", "--ev", "-0.7", "--iso", "800", "--shutter", "1/120"}))
+	require.NoError(t, app.Run([]string{"djictl", "exposure", "--experimental", "--iso-max", "3200", "--white-balance", "5600K"}))
+	var set []byte
+	for _, msg := range camera.State().Received {
+		if msg.Type == duml.MessageTypeStartStopStreaming && msg.Payload[0] == byte(duml.KeyValueOpSet) {
+			set = msg.Payload
+		}
+	}
+	// set (0x01), 2 items: ISO (0x0005): auto, max 3200; white balance (0x0007): 5600K
+	require.Equal(t, []byte{0x01, 0x02, 0x05, 0x00, 0x05, 0x00, 0x00, 0x00, 0x80, 0x0C, 0x07, 0x00, 0x03, 0x05, 0xE0, 0x15}, set)
+	require.Equal(t, "Exposure: EV: +0.0; ISO: auto; shutter: auto; white balance: auto; color profile: normal\n"+
+		"Exposure: EV: -0.7; ISO: 800; shutter: 1/120; white balance: auto; color profile: normal\n"+
+		"Exposure: EV: -0.7; ISO: auto (max 3200); shutter: 1/120; white balance: 5600K; color profile: normal\n", string(out.Bytes()))
+
+	require.ErrorContains(t, app.Run([]string{"djictl", "exposure", "--experimental", "--color-profile", "d-log-m"}), "color profile d-log-m is not supported by osmo-action-3")
+	require.ErrorContains(t, app.Run([]string{"djictl", "exposure", "--experimental", "--iso", "50"}), "ISO 50 is out of the range")
+	require.ErrorContains(t, app.Run([]string{"djictl", "exposure", "--experimental", "--iso", "800", "--iso-max", "3200"}), "auto ISO mode")
+	require.ErrorContains(t, app.Run([]string{"djictl", "exposure", "--experimental", "--white-balance", "warm"}), "invalid white balance")
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"github.com/xaionaro-go/djictl/pkg/djiapi"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// gimbalCommand returns the command reading the attitude of the gimbal and controlling it.
+func gimbalCommand(run connRunner) *cli.Command {
+	timeoutFlag := &cli.DurationFlag{
+		Name:  "timeout",
+		Value: 5 * time.Second,
+		Usage: "Time to wait for the device to respond",
+	}
+	gimbalAction := func(name string, fn func(ctx context.Context, gimbal *djiapi.InterfaceAppToGimbal) error) func(c *cli.Context) error {
+		return func(c *cli.Context) error {
+			if err := checkExperimental(c); err != nil {
+				return err
+			}
+			return run(c, func(ctx context.Context, conn duml.Conn) error {
+				ctx, cancel := context.WithTimeout(ctx, c.Duration("timeout"))
+				defer cancel()
+				if err := fn(ctx, djiapi.AppToGimbal(conn).WithExperimental()); err != nil {
+					return err
+				}
+				fmt.Fprintf(c.App.Writer, "%s: done\n", name)
+				return nil
+			})
+		}
+	}
+	return &cli.Command{
+		Name:  "gimbal",
+		Usage: "Print the attitude of the gimbal or control it",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "status",
+				Usage: "Print the attitude and the mode of the gimbal",
+				Flags: []cli.Flag{
+					timeoutFlag,
+					&cli.BoolFlag{
+						Name:  "watch",
+						Usage: "Keep printing the status each time the gimbal reports it (until interrupted)",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return run(c, func(ctx context.Context, conn duml.Conn) error {
+						gimbal := djiapi.AppToGimbal(conn)
+						if c.Bool("watch") {
+							return gimbal.WatchStatus(ctx, func(status *duml.GimbalStatus) error {
+								fmt.Fprintf(c.App.Writer, "%s %s\n", time.Now().Format(time.TimeOnly), status)
+								return nil
+							})
+						}
+						ctx, cancel := context.WithTimeout(ctx, c.Duration("timeout"))
+						defer cancel()
+						status, err := gimbal.GetStatus(ctx)
+						if err != nil {
+							return err
+						}
+						fmt.Fprintf(c.App.Writer, "%s\n", status)
+						return nil
+					})
+				},
+			},
+			{
+				Name:      "mode",
+				Usage:     "Switch the gimbal mode [experimental]",
+				ArgsUsage: "<follow|tilt-locked|fpv>",
+				Flags:     []cli.Flag{experimentalFlag, timeoutFlag},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("expected exactly one argument: the gimbal mode")
+					}
+					mode, err := duml.GimbalModeFromString(c.Args().First())
+					if err != nil {
+						return err
+					}
+					return gimbalAction("mode "+mode.String(), func(ctx context.Context, gimbal *djiapi.InterfaceAppToGimbal) error {
+						return gimbal.SetMode(ctx, mode)
+					})(c)
+				},
+			},
+			{
+				Name:  "recenter",
+				Usage: "Return the gimbal to the center [experimental]",
+				Flags: []cli.Flag{experimentalFlag, timeoutFlag},
+				Action: gimbalAction("recenter", func(ctx context.Context, gimbal *djiapi.InterfaceAppToGimbal) error {
+					return gimbal.Recenter(ctx)
+				}),
+			},
+			{
+				Name:  "move",
+				Usage: "Turn the gimbal to the given angles (the axes not given are kept) [experimental]",
+				Flags: []cli.Flag{
+					experimentalFlag,
+					timeoutFlag,
+					&cli.Float64Flag{
+						Name:  "yaw",
+						Usage: "Yaw, in degrees",
+					},
+					&cli.Float64Flag{
+						Name:  "pitch",
+						Usage: "Pitch, in degrees",
+					},
+					&cli.Float64Flag{
+						Name:  "roll",
+						Usage: "Roll, in degrees",
+					},
+					&cli.BoolFlag{
+						Name:  "relative",
+						Usage: "Turn by the given angles instead of to them",
+					},
+					&cli.DurationFlag{
+						Name:  "duration",
+						Value: time.Second,
+						Usage: "Duration of the movement (rounded to tenths of a second)",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					req, err := gimbalMoveFromFlags(c)
+					if err != nil {
+						return err
+					}
+					return gimbalAction("move", func(ctx context.Context, gimbal *djiapi.InterfaceAppToGimbal) error {
+						return gimbal.Move(ctx, req)
+					})(c)
+				},
+			},
+		},
+	}
+}
+
+// gimbalMoveFromFlags returns the movement request given by the flags of 'gimbal move'.
+func gimbalMoveFromFlags(c *cli.Context) (*duml.GimbalMoveRequest, error) {
+	req := &duml.GimbalMoveRequest{Duration: c.Duration("duration")}
+	if c.Bool("relative") {
+		req.Flags |= duml.GimbalMoveFlagRelative
+	}
+	for _, axis := range []struct {
+		Name   string
+		Value  *duml.Decidegrees
+		Ignore duml.GimbalMoveFlags
+	}{
+		{"yaw", &req.Yaw, duml.GimbalMoveFlagIgnoreYaw},
+		{"pitch", &req.Pitch, duml.GimbalMoveFlagIgnorePitch},
+		{"roll", &req.Roll, duml.GimbalMoveFlagIgnoreRoll},
+	} {
+		if !c.IsSet(axis.Name) {
+			req.Flags |= axis.Ignore
+			continue
+		}
+		v, err := duml.DecidegreesFromFloat(c.Float64(axis.Name))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", axis.Name, err)
+		}
+		*axis.Value = v
+	}
+	const ignoreAll = duml.GimbalMoveFlagIgnoreYaw | duml.GimbalMoveFlagIgnorePitch | duml.GimbalMoveFlagIgnoreRoll
+	if req.Flags&ignoreAll == ignoreAll {
+		return nil, fmt.Errorf("at least one of --yaw, --pitch and --roll is required")
+	}
+	return req, req.Validate()
+}
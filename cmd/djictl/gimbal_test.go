@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+func TestGimbalWithEmulator(t *testing.T) {
+	ctx := newTestContext(t, logger.LevelWarning)
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoPocket3)
+	camera.BatteryPushInterval = 10 * time.Millisecond
+	app, out := newEmulatorConnApp(ctx, t, camera)
+
+	require.NoError(t, app.Run([]string{"djictl", "gimbal", "mode", "--experimental", "tilt-locked"}))
+	require.NoError(t, app.Run([]string{"djictl", "gimbal", "move", "--experimental", "--yaw", "30", "--pitch", "-15.5"}))
+	var move []byte
+	for _, msg := range camera.State().Received {
+		if msg.Type == duml.MessageTypeGimbalMove {
+			move = msg.Payload
+		}
+	}
+	// yaw 30.0° (300), pitch -15.5° (-155), roll 0, flags: ignore roll (0x04), 1s (10)
+	require.Equal(t, []byte{0x2C, 0x01, 0x00, 0x00, 0x65, 0xFF, 0x04, 0x0A}, move)
+	require.NoError(t, app.Run([]string{"djictl", "gimbal", "move", "--experimental", "--relative", "--yaw", "-45"}))
+	require.Equal(t, duml.GimbalStatus{
+		Attitude: duml.GimbalAttitude{Yaw: -150, Pitch: -155},
+		Mode:     duml.GimbalModeTiltLocked,
+	}, camera.State().Gimbal)
+	require.NoError(t, app.Run([]string{"djictl", "gimbal", "recenter", "--experimental"}))
+	require.Equal(t, duml.GimbalAttitude{}, camera.State().Gimbal.Attitude)
+	require.Equal(t, "mode tilt-locked: done\nmove: done\nmove: done\nrecenter: done\n", string(out.Bytes()))
+
+	require.ErrorContains(t, app.Run([]string{"djictl", "gimbal", "move", "--experimental"}), "at least one of")
+	require.ErrorContains(t, app.Run([]string{"djictl", "gimbal", "move", "--experimental", "--pitch", "200"}), "out of the range")
+	require.ErrorContains(t, app.Run([]string{"djictl", "gimbal", "mode", "--experimental", "locked"}), "unknown gimbal mode")
+}
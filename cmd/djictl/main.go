@@ -3,12 +3,17 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"strings"
 
 	"github.com/facebookincubator/go-belt/tool/logger"
 	"github.com/urfave/cli/v2"
 	"github.com/xaionaro-go/djictl/pkg/djible"
+	"github.com/xaionaro-go/djictl/pkg/djibridge"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/djirecord"
+	"github.com/xaionaro-go/djictl/pkg/djiserial"
 	"github.com/xaionaro-go/djictl/pkg/djiwifi"
 	"github.com/xaionaro-go/djictl/pkg/duml"
 )
@@ -25,6 +30,10 @@ func main() {
 				Value: "info",
 				Usage: "Log level (debug, info, warn, error, fatal, panic)",
 			},
+			&cli.StringFlag{
+				Name:  "device-profiles",
+				Usage: "YAML (or .json) file with a list of device profiles, to support new models without recompiling (overrides the embedded profiles of the same name)",
+			},
 		},
 		Commands: []*cli.Command{
 			{
@@ -36,18 +45,75 @@ func main() {
 						Value: "",
 						Usage: "Filter device by address",
 					},
+					&cli.StringFlag{
+						Name:  "record",
+						Usage: "Record a transcript of all the frames sent and received to the file (JSON lines, see 'emulator --replay')",
+					},
 				},
-				Subcommands: []*cli.Command{
+				Subcommands: append([]*cli.Command{
 					{
 						Name:  "scan",
-						Usage: "Scan for DJI devices",
+						Usage: "Scan for DJI devices (and show the model, RSSI and status advertised by each of them)",
 						Action: func(c *cli.Context) error {
 							return runOnBLE(c, func(ctx context.Context, dev *djible.Device) error {
-								fmt.Printf("Found device: %s\n", dev)
+								printScanResult(c.App.Writer, dev)
 								return nil
 							})
 						},
 					},
+					{
+						Name:  "monitor",
+						Usage: "Connect and print all the frames sent and received (the message types unknown to djictl are marked with '!')",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "pair",
+								Usage: "Pair with the device after connecting",
+							},
+							&cli.StringFlag{
+								Name:  "format",
+								Value: monitorFormatTable,
+								Usage: "Output format (allowed values: table, json)",
+							},
+							&cli.StringSliceFlag{
+								Name:  "component",
+								Usage: "Show only the messages sent or received by the component (a name, e.g. camera, or a number)",
+							},
+							&cli.StringSliceFlag{
+								Name:  "cmdset",
+								Usage: "Show only the messages of the command set (a name, e.g. WiFi, or a number)",
+							},
+							&cli.StringSliceFlag{
+								Name:  "cmdid",
+								Usage: "Show only the messages of the command ID (a number)",
+							},
+							&cli.BoolFlag{
+								Name:  "unknown-only",
+								Usage: "Show only the message types unknown to djictl",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							filter, err := newMonitorFilter(c.StringSlice("component"), c.StringSlice("cmdset"), c.StringSlice("cmdid"))
+							if err != nil {
+								return err
+							}
+							m, err := newMonitor(os.Stdout, c.String("format"), filter, c.Bool("unknown-only"))
+							if err != nil {
+								return err
+							}
+							return runOnBLE(c, func(ctx context.Context, dev *djible.Device) error {
+								return runMonitor(ctx, dev, m, c.Bool("pair"))
+							})
+						},
+					},
+					{
+						Name:  "shell",
+						Usage: "Connect and read commands from the standard input (see 'help' inside the shell)",
+						Action: func(c *cli.Context) error {
+							return runOnBLE(c, func(ctx context.Context, dev *djible.Device) error {
+								return runShell(ctx, dev, os.Stdin, os.Stdout)
+							})
+						},
+					},
 					{
 						Name:  "connect-wifi-and-start-streaming",
 						Usage: "Connect device to WiFi and start RTMP streaming",
@@ -106,148 +172,7 @@ func main() {
 							})
 						},
 					},
-					{
-						Name:  "camera-ap-info",
-						Usage: "Get camera AP SSID and Password [does not work, yet]",
-						Action: func(c *cli.Context) error {
-							return runOnBLE(c, func(ctx context.Context, dev *djible.Device) error {
-								err := dev.Init(ctx)
-								if err != nil {
-									return fmt.Errorf("unable to initialize: %w", err)
-								}
-								err = dev.AppToWiFiGroundStation().Pair(ctx)
-								if err != nil {
-									return fmt.Errorf("unable to pair: %w", err)
-								}
-								ssid, psk, err := dev.AppToWiFiGroundStation().CameraAPInfo(ctx)
-								if err != nil {
-									return fmt.Errorf("unable to get camera AP info: %w", err)
-								}
-								fmt.Printf("SSID: %s\nPSK: %s\n", ssid, psk)
-								return errDone
-							})
-						},
-					},
-					{
-						Name:  "fcc-enable",
-						Usage: "Enable FCC mode [does not work, yet]",
-						Action: func(c *cli.Context) error {
-							return runOnBLE(c, func(ctx context.Context, dev *djible.Device) error {
-								err := dev.Init(ctx)
-								if err != nil {
-									return err
-								}
-								_, err = dev.AppToCamera().SetFCCEnable(ctx, true)
-								return err
-							})
-						},
-					},
-					{
-						Name:  "set-goggles-mode",
-						Usage: "Set Goggles mode [does not work, yet]",
-						Flags: []cli.Flag{
-							&cli.StringFlag{
-								Name:  "mode",
-								Value: "usb",
-								Usage: "Mode: usb or normal",
-							},
-						},
-						Action: func(c *cli.Context) error {
-							return runOnBLE(c, func(ctx context.Context, dev *djible.Device) error {
-								err := dev.Init(ctx)
-								if err != nil {
-									return err
-								}
-								var mode duml.GogglesMode
-								switch strings.ToLower(c.String("mode")) {
-								case "usb":
-									mode = duml.GogglesModeUSB
-								case "normal":
-									mode = duml.GogglesModeNormal
-								default:
-									return fmt.Errorf("invalid mode: %s", c.String("mode"))
-								}
-								_, err = dev.AppToGoggles().SetMode(ctx, mode)
-								return err
-							})
-						},
-					},
-					{
-						Name:  "remote-controller-simulator",
-						Usage: "Send Remote Controller simulator data [does not work, yet]",
-						Flags: []cli.Flag{
-							&cli.IntFlag{Name: "right-h", Value: 1024},
-							&cli.IntFlag{Name: "right-v", Value: 1024},
-							&cli.IntFlag{Name: "left-v", Value: 1024},
-							&cli.IntFlag{Name: "left-h", Value: 1024},
-						},
-						Action: func(c *cli.Context) error {
-							return runOnBLE(c, func(ctx context.Context, dev *djible.Device) error {
-								err := dev.Init(ctx)
-								if err != nil {
-									return err
-								}
-								data := duml.RemoteControllerSimulatorData{
-									RightStickHorizontal: uint16(c.Int("right-h")),
-									RightStickVertical:   uint16(c.Int("right-v")),
-									LeftStickVertical:    uint16(c.Int("left-v")),
-									LeftStickHorizontal:  uint16(c.Int("left-h")),
-								}
-								return dev.AppToRemoteController().SendData(ctx, data)
-							})
-						},
-					},
-					{
-						Name:  "rtmp-broadcast",
-						Usage: "Configure RTMP broadcast [does not work, yet]",
-						Flags: []cli.Flag{
-							&cli.StringFlag{Name: "url", Usage: "RTMP URL", Required: true},
-							&cli.BoolFlag{Name: "disable", Usage: "Disable (instead of enable)"},
-						},
-						Action: func(c *cli.Context) error {
-							return runOnBLE(c, func(ctx context.Context, dev *djible.Device) error {
-								err := dev.Init(ctx)
-								if err != nil {
-									return err
-								}
-								_, err = dev.AppToVideoTransmission().ConfigureRTMP(ctx, c.String("url"), !c.Bool("disable"))
-								return err
-							})
-						},
-					},
-					{
-						Name:  "battery-info",
-						Usage: "Request battery information",
-						Action: func(c *cli.Context) error {
-							return runOnBLE(c, func(ctx context.Context, dev *djible.Device) error {
-								err := dev.Init(ctx)
-								if err != nil {
-									return err
-								}
-								status, err := dev.AppToBattery().GetInfo(ctx)
-								if err != nil {
-									return err
-								}
-								fmt.Printf("Battery capacity: %s\n", status.Capacity)
-								return nil
-							})
-						},
-					},
-					{
-						Name:  "firmware-version",
-						Usage: "Request firmware version [does not work, yet]",
-						Action: func(c *cli.Context) error {
-							return runOnBLE(c, func(ctx context.Context, dev *djible.Device) error {
-								err := dev.Init(ctx)
-								if err != nil {
-									return err
-								}
-								_, err = dev.AppToCamera().GetVersion(ctx)
-								return err
-							})
-						},
-					},
-				},
+				}, connCommands(runConnOnBLE)...),
 			},
 			{
 				Name:  "wifi",
@@ -258,8 +183,12 @@ func main() {
 						Value: "192.168.2.1:9004",
 						Usage: "Device UDP address",
 					},
+					&cli.StringFlag{
+						Name:  "record",
+						Usage: "Record a transcript of all the frames sent and received to the file (JSON lines, see 'emulator --replay')",
+					},
 				},
-				Subcommands: []*cli.Command{
+				Subcommands: append([]*cli.Command{
 					{
 						Name:  "start-video",
 						Usage: "Start video via WiFi [does not work, yet]",
@@ -272,29 +201,137 @@ func main() {
 							})
 						},
 					},
-					{
-						Name:  "fcc-enable",
-						Usage: "Enable FCC mode via WiFi [does not work, yet]",
-						Action: func(c *cli.Context) error {
-							return runOnWiFi(c, func(ctx context.Context, ctrl *djiwifi.Controller) error {
-								return ctrl.SendFCCEnable(ctx, true)
-							})
-						},
+				}, connCommands(runConnOnWiFi)...),
+			},
+			{
+				Name:  "serial",
+				Usage: "Serial-based commands (e.g. USB CDC-ACM of Goggles in USB mode)",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "port",
+						Usage:    "Path to the serial port (e.g. /dev/ttyACM0)",
+						Required: true,
 					},
-					{
-						Name:  "rtmp-broadcast",
-						Usage: "Configure RTMP broadcast via WiFi [does not work, yet]",
-						Flags: []cli.Flag{
-							&cli.StringFlag{Name: "url", Usage: "RTMP URL", Required: true},
-							&cli.BoolFlag{Name: "disable", Usage: "Disable (instead of enable)"},
-						},
-						Action: func(c *cli.Context) error {
-							return runOnWiFi(c, func(ctx context.Context, ctrl *djiwifi.Controller) error {
-								return ctrl.SendConfigureBroadcast(ctx, c.String("url"), !c.Bool("disable"))
-							})
-						},
+					&cli.UintFlag{
+						Name:  "baud-rate",
+						Value: djiserial.DefaultBaudRate,
+						Usage: "Baud rate of the serial port",
+					},
+				},
+				Subcommands: connCommands(runConnOnSerial),
+			},
+			{
+				Name:  "bridge",
+				Usage: "Connect to a device via BLE and share the connection with local clients via a TCP/Unix socket",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "filter-device-addr",
+						Value: "",
+						Usage: "Filter device by address",
+					},
+					&cli.StringFlag{
+						Name:  "listen",
+						Value: djibridge.DefaultAddr,
+						Usage: "Address to listen on: tcp:host:port or unix:/path/to/socket",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return runConnOnBLE(c, func(ctx context.Context, conn duml.Conn) error {
+						l, err := djibridge.Listen(c.String("listen"))
+						if err != nil {
+							return err
+						}
+						logger.Infof(ctx, "serving %s on %s", conn, c.String("listen"))
+						return djibridge.NewServer(conn).Serve(ctx, l)
+					})
+				},
+			},
+			{
+				Name:  "bridge-client",
+				Usage: "Commands sent through a running bridge (see 'bridge')",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "addr",
+						Value: djibridge.DefaultAddr,
+						Usage: "Address of the bridge: tcp:host:port or unix:/path/to/socket",
 					},
 				},
+				Subcommands: connCommands(runConnOnBridge),
+			},
+			{
+				Name:  "emulator",
+				Usage: "Emulate a DJI camera on a UDP socket, to test the WiFi-based commands without hardware",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "type",
+						Value: duml.DeviceTypeOsmoPocket3.String(),
+						Usage: "Device type (e.g. osmo-action-4, osmo-action-5-pro, osmo-pocket-3)",
+					},
+					&cli.StringFlag{
+						Name:  "listen",
+						Value: fmt.Sprintf("127.0.0.1:%d", djiwifi.DefaultUDPPort),
+						Usage: "UDP address to listen on",
+					},
+					&cli.BoolFlag{
+						Name:  "already-paired",
+						Usage: "Report the device as already paired",
+					},
+					&cli.StringFlag{
+						Name:  "replay",
+						Usage: "Instead of emulating, replay the transcript recorded with '--record'",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					ctx, err := newContext(c)
+					if err != nil {
+						return err
+					}
+					typ := duml.DeviceTypeFromString(c.String("type"))
+					if typ == duml.DeviceTypeUndefined {
+						return fmt.Errorf("invalid device type %q", c.String("type"))
+					}
+					var dev djiemu.Device
+					if path := c.String("replay"); path != "" {
+						entries, err := djirecord.ReadFile(path)
+						if err != nil {
+							return fmt.Errorf("unable to read the transcript: %w", err)
+						}
+						dev = djirecord.NewReplayer(typ, entries)
+					} else {
+						camera := djiemu.NewCamera(typ)
+						camera.AlreadyPaired = c.Bool("already-paired")
+						dev = camera
+					}
+
+					conn, err := net.ListenPacket("udp", c.String("listen"))
+					if err != nil {
+						return fmt.Errorf("unable to listen on %s: %w", c.String("listen"), err)
+					}
+					logger.Infof(ctx, "emulating %s on %s", dev.DeviceName(), conn.LocalAddr())
+					return djiemu.ServeUDP(ctx, conn, dev)
+				},
+			},
+			{
+				Name:      "decode",
+				Usage:     "Decode DUML frames from hex strings, pcap/pcapng or btsnoop files (no hardware required)",
+				ArgsUsage: "[hex-string|file ...] (stdin is read as hex strings if nothing is given)",
+				Flags: []cli.Flag{
+					&cli.UintFlag{
+						Name:  "udp-port",
+						Usage: "the UDP port of the device in pcap/pcapng files",
+						Value: djiwifi.DefaultUDPPort,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					ctx, err := newContext(c)
+					if err != nil {
+						return err
+					}
+					return decodeInputs(ctx, c.Args().Slice(), uint16(c.Uint("udp-port")), func(frame *decodedFrame) error {
+						fmt.Println(formatFrame(frame))
+						return nil
+					})
+				},
 			},
 		},
 	}
@@ -308,16 +345,38 @@ func main() {
 	}
 }
 
-func runOnBLE(c *cli.Context, action func(ctx context.Context, dev *djible.Device) error) error {
+func newContext(c *cli.Context) (context.Context, error) {
 	var loggerLevel logger.Level
 	if err := loggerLevel.Set(c.String("log-level")); err != nil {
-		return fmt.Errorf("invalid log level '%s': %w", c.String("log-level"), err)
+		return nil, fmt.Errorf("invalid log level '%s': %w", c.String("log-level"), err)
 	}
 
 	ctx := getContext(loggerLevel, false, "")
 	logger.Debugf(ctx, "log level: %s (raw value: '%s')", loggerLevel, c.String("log-level"))
+
+	if path := c.String("device-profiles"); path != "" {
+		types, err := duml.LoadDeviceProfilesFile(path)
+		if err != nil {
+			return nil, err
+		}
+		logger.Debugf(ctx, "loaded device profiles: %v", types)
+	}
+	return ctx, nil
+}
+
+func runOnBLE(c *cli.Context, action func(ctx context.Context, dev *djible.Device) error) error {
+	ctx, err := newContext(c)
+	if err != nil {
+		return err
+	}
 	filterDeviceAddr := c.String("filter-device-addr")
 
+	recorder, closeRecorder, err := openRecorder(c)
+	if err != nil {
+		return err
+	}
+	defer closeRecorder()
+
 	devCh, errCh, err := djible.Scan(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to start scanning: %w", err)
@@ -331,6 +390,9 @@ func runOnBLE(c *cli.Context, action func(ctx context.Context, dev *djible.Devic
 				logger.Infof(ctx, "found device %s; but skipping, because it's address does not match filter '%s'...", dev, filterDeviceAddr)
 				continue
 			}
+			if recorder != nil {
+				dev.OnFrame = recorder.BLEFrameHook()
+			}
 
 			if err := action(ctx, dev); err != nil {
 				return err
@@ -344,20 +406,88 @@ func runOnBLE(c *cli.Context, action func(ctx context.Context, dev *djible.Devic
 }
 
 func runOnWiFi(c *cli.Context, action func(ctx context.Context, ctrl *djiwifi.Controller) error) error {
-	var loggerLevel logger.Level
-	if err := loggerLevel.Set(c.String("log-level")); err != nil {
-		return fmt.Errorf("invalid log level '%s': %w", c.String("log-level"), err)
+	ctx, err := newContext(c)
+	if err != nil {
+		return err
 	}
-
-	ctx := getContext(loggerLevel, false, "")
-	logger.Debugf(ctx, "log level: %s (raw value: '%s')", loggerLevel, c.String("log-level"))
 	addr := c.String("addr")
 
+	recorder, closeRecorder, err := openRecorder(c)
+	if err != nil {
+		return err
+	}
+	defer closeRecorder()
+
 	ctrl, err := djiwifi.NewController(ctx, addr)
 	if err != nil {
 		return err
 	}
 	defer ctrl.Close()
+	if recorder != nil {
+		ctrl.OnPacket = recorder.WiFiPacketHook()
+	}
 
 	return action(ctx, ctrl)
 }
+
+// openRecorder opens the transcript file given by the "record" flag; the
+// returned Recorder is nil if the flag is not set.
+func openRecorder(c *cli.Context) (*djirecord.Recorder, func() error, error) {
+	path := c.String("record")
+	if path == "" {
+		return nil, func() error { return nil }, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create the transcript file '%s': %w", path, err)
+	}
+	return djirecord.NewRecorder(f), f.Close, nil
+}
+
+func runConnOnBLE(c *cli.Context, action func(ctx context.Context, conn duml.Conn) error) error {
+	return runOnBLE(c, func(ctx context.Context, dev *djible.Device) error {
+		if err := dev.Init(ctx); err != nil {
+			return fmt.Errorf("unable to initialize: %w", err)
+		}
+		return action(ctx, dev)
+	})
+}
+
+func runConnOnWiFi(c *cli.Context, action func(ctx context.Context, conn duml.Conn) error) error {
+	return runOnWiFi(c, func(ctx context.Context, ctrl *djiwifi.Controller) error {
+		if err := ctrl.SendHandshake(ctx); err != nil {
+			return fmt.Errorf("unable to send the handshake: %w", err)
+		}
+		return action(ctx, ctrl)
+	})
+}
+
+func runConnOnSerial(c *cli.Context, action func(ctx context.Context, conn duml.Conn) error) error {
+	ctx, err := newContext(c)
+	if err != nil {
+		return err
+	}
+
+	dev, err := djiserial.Open(ctx, c.String("port"), c.Uint("baud-rate"))
+	if err != nil {
+		return err
+	}
+	defer dev.Close()
+
+	return action(ctx, dev)
+}
+
+func runConnOnBridge(c *cli.Context, action func(ctx context.Context, conn duml.Conn) error) error {
+	ctx, err := newContext(c)
+	if err != nil {
+		return err
+	}
+
+	client, err := djibridge.Dial(ctx, c.String("addr"))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return action(ctx, client)
+}
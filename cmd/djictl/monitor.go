@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/djictl/pkg/djible"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+const (
+	monitorFormatTable = "table"
+	monitorFormatJSON  = "json"
+)
+
+// monitorRecord is a frame printed by the monitor in the JSON format.
+type monitorRecord struct {
+	Time      time.Time `json:"time"`
+	Direction string    `json:"direction"`
+	Interface string    `json:"interface"`
+	ID        uint16    `json:"id"`
+	Type      string    `json:"type"`
+	Known     bool      `json:"known"`
+	Flags     string    `json:"flags"`
+	CmdSet    uint8     `json:"cmd_set"`
+	CmdID     uint8     `json:"cmd_id"`
+	Payload   string    `json:"payload"`
+	Decoded   any       `json:"decoded,omitempty"`
+}
+
+// monitor prints the frames sent and received by a djible.Device (see OnFrame).
+type monitor struct {
+	Output      io.Writer
+	Format      string
+	Filter      duml.MessageFilter
+	UnknownOnly bool
+
+	locker  sync.Mutex
+	decoder *frameDecoder
+}
+
+func newMonitor(
+	output io.Writer,
+	format string,
+	filter duml.MessageFilter,
+	unknownOnly bool,
+) (*monitor, error) {
+	switch format {
+	case monitorFormatTable, monitorFormatJSON:
+	default:
+		return nil, fmt.Errorf("unknown format '%s', expected '%s' or '%s'", format, monitorFormatTable, monitorFormatJSON)
+	}
+	m := &monitor{
+		Output:      output,
+		Format:      format,
+		Filter:      filter,
+		UnknownOnly: unknownOnly,
+	}
+	m.decoder = newFrameDecoder(m.print)
+	return m, nil
+}
+
+// OnFrame is a djible.FrameHook.
+func (m *monitor) OnFrame(
+	ctx context.Context,
+	direction duml.Direction,
+	handle uint16,
+	b []byte,
+) {
+	if handle == djible.CharacteristicIDPairingRequestor {
+		logger.Infof(ctx, "pairing request: %X", b)
+		return
+	}
+
+	frameDir := frameDirectionFromDevice
+	if direction == duml.DirectionSent {
+		frameDir = frameDirectionToDevice
+	}
+
+	m.locker.Lock()
+	defer m.locker.Unlock()
+	streamKey := fmt.Sprintf("%s:%04X", direction, handle)
+	if err := m.decoder.Feed(ctx, streamKey, time.Now(), frameDir, b); err != nil {
+		logger.Errorf(ctx, "unable to print the frame: %v", err)
+	}
+}
+
+func (m *monitor) print(frame *decodedFrame) error {
+	msg := frame.Message
+	known := msg.Type.IsKnown()
+	if !m.Filter.Match(msg) || (m.UnknownOnly && known) {
+		return nil
+	}
+
+	if m.Format == monitorFormatJSON {
+		direction := duml.DirectionReceived
+		if frame.Direction == frameDirectionToDevice {
+			direction = duml.DirectionSent
+		}
+		record := monitorRecord{
+			Time:      frame.Timestamp,
+			Direction: direction.String(),
+			Interface: msg.Interface.String(),
+			ID:        uint16(msg.ID),
+			Type:      msg.Type.String(),
+			Known:     known,
+			Flags:     msg.Type.Flags.String(),
+			CmdSet:    uint8(msg.Type.CmdSet),
+			CmdID:     uint8(msg.Type.CmdID),
+			Payload:   hex.EncodeToString(msg.Payload),
+		}
+		if p, err := msg.DecodePayload(); err == nil {
+			record.Decoded = p
+		}
+		b, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("unable to serialize the frame: %w", err)
+		}
+		_, err = fmt.Fprintf(m.Output, "%s\n", b)
+		return err
+	}
+
+	// the message types unknown to duml.MessageType.String are marked with '!'
+	marker := " "
+	if !known {
+		marker = "!"
+	}
+	_, err := fmt.Fprintf(m.Output, "%s %s\n", marker, formatFrame(frame))
+	return err
+}
+
+// newMonitorFilter returns the filter matching the messages sent or received by
+// any of the components, of any of the command sets and of any of the command IDs
+// (an empty list matches anything).
+func newMonitorFilter(components, cmdSets, cmdIDs []string) (duml.MessageFilter, error) {
+	var filters []duml.MessageFilter
+
+	var componentFilters []duml.MessageFilter
+	for _, s := range components {
+		id, err := duml.ParseComponentID(s)
+		if err != nil {
+			return nil, err
+		}
+		componentFilters = append(componentFilters, duml.FilterSender(id), duml.FilterReceiver(id))
+	}
+	if len(componentFilters) > 0 {
+		filters = append(filters, duml.FilterOr(componentFilters...))
+	}
+
+	var cmdSetFilters []duml.MessageFilter
+	for _, s := range cmdSets {
+		cmdSet, err := duml.ParseCommandSet(s)
+		if err != nil {
+			return nil, err
+		}
+		cmdSetFilters = append(cmdSetFilters, duml.FilterCommandSet(cmdSet))
+	}
+	if len(cmdSetFilters) > 0 {
+		filters = append(filters, duml.FilterOr(cmdSetFilters...))
+	}
+
+	var cmdIDFilters []duml.MessageFilter
+	for _, s := range cmdIDs {
+		cmdID, err := duml.ParseCommandID(s)
+		if err != nil {
+			return nil, err
+		}
+		cmdIDFilters = append(cmdIDFilters, func(msg *duml.Message) bool {
+			return msg.Type.CmdID == cmdID
+		})
+	}
+	if len(cmdIDFilters) > 0 {
+		filters = append(filters, duml.FilterOr(cmdIDFilters...))
+	}
+
+	if len(filters) == 0 {
+		return duml.FilterAny(), nil
+	}
+	return duml.FilterAnd(filters...), nil
+}
+
+// runMonitor initializes the device (and pairs with it, if requested) and
+// prints the frames until the device disconnects or the context is cancelled.
+func runMonitor(
+	ctx context.Context,
+	dev *djible.Device,
+	m *monitor,
+	pair bool,
+) error {
+	prevOnFrame := dev.OnFrame
+	dev.OnFrame = func(ctx context.Context, direction duml.Direction, handle uint16, b []byte) {
+		if prevOnFrame != nil {
+			prevOnFrame(ctx, direction, handle, b)
+		}
+		m.OnFrame(ctx, direction, handle, b)
+	}
+
+	if err := dev.Init(ctx); err != nil {
+		return fmt.Errorf("unable to initialize: %w", err)
+	}
+	if pair {
+		if err := dev.AppToWiFiGroundStation().Pair(ctx); err != nil {
+			return fmt.Errorf("unable to pair: %w", err)
+		}
+	}
+
+	// matches nothing, used only to wait for the disconnection
+	sub := dev.Subscribe(ctx, func(*duml.Message) bool { return false })
+	defer sub.Close()
+	_, err := sub.Receive(ctx)
+	if errors.Is(err, duml.ErrDisconnected) {
+		logger.Infof(ctx, "the device disconnected")
+	}
+	return err
+}
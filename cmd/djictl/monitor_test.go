@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/djictl/pkg/djible"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+func TestMonitorWithEmulator(t *testing.T) {
+	ctx := newTestContext(t, logger.LevelWarning)
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoPocket3)
+	camera.PINApprovalDelay = 10 * time.Millisecond
+	camera.BatteryPushInterval = 10 * time.Millisecond
+	bleDev := camera.NewBLEDevice(ctx)
+	defer bleDev.Close()
+	devCh, errCh, err := djible.ScanWithDevice(ctx, bleDev)
+	require.NoError(t, err)
+	var dev *djible.Device
+	select {
+	case dev = <-devCh:
+	case err := <-errCh:
+		t.Fatal(err)
+	case <-ctx.Done():
+		t.Fatal("the emulated device was not found")
+	}
+
+	filter, err := newMonitorFilter([]string{"wifi_ground_station", "battery"}, nil, nil)
+	require.NoError(t, err)
+	// the pushes may still be printed while the output is read
+	var out syncBuffer
+	m, err := newMonitor(&out, monitorFormatJSON, filter, false)
+	require.NoError(t, err)
+
+	monitorCtx, monitorCancel := context.WithCancel(ctx)
+	go func() {
+		for monitorCtx.Err() == nil && !camera.State().Paired {
+			time.Sleep(time.Millisecond)
+		}
+		// let a few battery pushes through
+		time.Sleep(50 * time.Millisecond)
+		monitorCancel()
+	}()
+	err = runMonitor(monitorCtx, dev, m, true)
+	require.ErrorIs(t, err, context.Canceled)
+
+	types := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(out.Bytes()))
+	for scanner.Scan() {
+		var record monitorRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		require.True(t, record.Known, record.Type)
+		require.True(t, strings.Contains(record.Interface, "wifi_ground_station") || strings.Contains(record.Interface, "battery"), record.Interface)
+		types[record.Type] = record.Direction
+	}
+	require.Equal(t, "sent", types[duml.MessageTypeSetPairingPIN.String()])
+	require.Equal(t, "received", types[duml.MessageTypeBatteryStatus.String()])
+}
+
+func TestMonitorTableMarksUnknownTypes(t *testing.T) {
+	ctx := context.Background()
+	var out bytes.Buffer
+	m, err := newMonitor(&out, monitorFormatTable, duml.FilterAny(), false)
+	require.NoError(t, err)
+
+	unknown := &duml.Message{
+		Interface: duml.InterfaceIDAppToCamera,
+		ID:        1,
+		Type:      duml.MessageTypeRequest(duml.CommandSetCamera, 0xFE),
+	}
+	m.OnFrame(ctx, duml.DirectionSent, djible.CharacteristicIDSender, unknown.Bytes())
+	battery, err := duml.NewMessage(
+		duml.InterfaceID{Sender: duml.ComponentIDBattery, Receiver: duml.ComponentIDApp},
+		2,
+		duml.MessageTypeBatteryStatus,
+		&duml.BatteryStatus{Capacity: 50},
+	)
+	require.NoError(t, err)
+	m.OnFrame(ctx, duml.DirectionReceived, djible.CharacteristicIDReceiver, battery.Bytes())
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	require.True(t, strings.HasPrefix(lines[0], "! "), lines[0])
+	require.True(t, strings.HasPrefix(lines[1], "  "), lines[1])
+
+	_, err = newMonitor(&out, "xml", nil, false)
+	require.Error(t, err)
+}
+
+func TestNewMonitorFilter(t *testing.T) {
+	filter, err := newMonitorFilter([]string{"camera"}, []string{"WiFi", "0x0d"}, []string{"0x47"})
+	require.NoError(t, err)
+	require.True(t, filter.Match(&duml.Message{Interface: duml.InterfaceIDAppToCamera, Type: duml.MessageTypeConnectToWiFi}))
+	require.False(t, filter.Match(&duml.Message{Interface: duml.InterfaceIDAppToBattery, Type: duml.MessageTypeConnectToWiFi}))
+	require.False(t, filter.Match(&duml.Message{Interface: duml.InterfaceIDAppToCamera, Type: duml.MessageTypeSetPairingPIN}))
+
+	_, err = newMonitorFilter([]string{"nonexistent"}, nil, nil)
+	require.Error(t, err)
+	_, err = newMonitorFilter(nil, nil, []string{"256"})
+	require.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// rawCommand returns the command sending an arbitrary DUML message.
+func rawCommand(run connRunner) *cli.Command {
+	return &cli.Command{
+		Name:  "raw",
+		Usage: "Send an arbitrary DUML message and print the response (if the message requires an ACK)",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "interface",
+				Value: duml.InterfaceIDAppToCamera.String(),
+				Usage: "Sender and receiver, e.g. app->camera (a component is a name or a number)",
+			},
+			&cli.StringFlag{
+				Name:  "type",
+				Usage: "Message type name, e.g. get_version (alternatively use --cmdset and --cmdid)",
+			},
+			&cli.StringFlag{
+				Name:  "cmdset",
+				Usage: "Command set (a name, e.g. Camera, or a number)",
+			},
+			&cli.StringFlag{
+				Name:  "cmdid",
+				Usage: "Command ID (a number)",
+			},
+			&cli.StringFlag{
+				Name:  "flags",
+				Value: duml.MessageTypeFlagAckRequired.String(),
+				Usage: "Flags, e.g. Request|AckRequired (overrides the flags of --type, if set explicitly)",
+			},
+			&cli.StringFlag{
+				Name:  "id",
+				Usage: "Message ID (allocated automatically if not set)",
+			},
+			&cli.StringFlag{
+				Name:  "payload",
+				Usage: "Payload in hex",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Value: duml.DefaultRequestTimeout,
+				Usage: "Time to wait for a response to each transmission",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			msg, err := newRawMessage(c)
+			if err != nil {
+				return err
+			}
+			return run(c, func(ctx context.Context, conn duml.Conn) error {
+				if msg.Type.Flags&duml.MessageTypeFlagAckRequired == 0 {
+					if err := conn.SendMessage(ctx, msg); err != nil {
+						return fmt.Errorf("unable to send the message: %w", err)
+					}
+					fmt.Fprintln(c.App.Writer, formatFrame(&decodedFrame{Direction: frameDirectionToDevice, Message: msg}))
+					return nil
+				}
+
+				resp, err := conn.Request(ctx, msg, duml.RequestTimeout(c.Duration("timeout")))
+				fmt.Fprintln(c.App.Writer, formatFrame(&decodedFrame{Direction: frameDirectionToDevice, Message: msg}))
+				if err != nil {
+					return fmt.Errorf("unable to get a response: %w", err)
+				}
+				fmt.Fprintln(c.App.Writer, formatFrame(&decodedFrame{Direction: frameDirectionFromDevice, Message: resp}))
+				fmt.Fprintf(c.App.Writer, "%X\n", resp.Bytes())
+				return nil
+			})
+		},
+	}
+}
+
+// newRawMessage builds the message from the flags of rawCommand.
+func newRawMessage(c *cli.Context) (*duml.Message, error) {
+	iface, err := duml.ParseInterfaceID(c.String("interface"))
+	if err != nil {
+		return nil, err
+	}
+
+	var typ duml.MessageType
+	switch {
+	case c.String("type") != "":
+		if c.IsSet("cmdset") || c.IsSet("cmdid") {
+			return nil, fmt.Errorf("--type and --cmdset/--cmdid are mutually exclusive")
+		}
+		typ, err = duml.ParseMessageType(c.String("type"))
+		if err != nil {
+			return nil, err
+		}
+	case c.IsSet("cmdset") && c.IsSet("cmdid"):
+		typ.CmdSet, err = duml.ParseCommandSet(c.String("cmdset"))
+		if err != nil {
+			return nil, err
+		}
+		typ.CmdID, err = duml.ParseCommandID(c.String("cmdid"))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("either --type or both --cmdset and --cmdid are required")
+	}
+	if c.IsSet("flags") || c.String("type") == "" {
+		typ.Flags, err = duml.ParseMessageTypeFlags(c.String("flags"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var id duml.MessageID
+	if c.IsSet("id") {
+		v, err := strconv.ParseUint(c.String("id"), 0, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid message ID '%s': %w", c.String("id"), err)
+		}
+		id = duml.MessageID(v)
+	}
+
+	payload, err := hex.DecodeString(strings.NewReplacer(" ", "", ":", "").Replace(c.String("payload")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	return &duml.Message{
+		Interface: iface,
+		ID:        id,
+		Type:      typ,
+		Payload:   payload,
+		AutoID:    !c.IsSet("id"),
+	}, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/djiwifi"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+func TestRawWithEmulator(t *testing.T) {
+	ctx := newTestContext(t, logger.LevelWarning)
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoPocket3)
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go camera.ServeUDP(ctx, conn)
+
+	runner := func(c *cli.Context, action func(ctx context.Context, conn duml.Conn) error) error {
+		ctrl, err := djiwifi.NewController(ctx, conn.LocalAddr().String())
+		if err != nil {
+			return err
+		}
+		defer ctrl.Close()
+		return action(ctx, ctrl)
+	}
+	var out bytes.Buffer
+	app := &cli.App{
+		Name:     "djictl",
+		Writer:   &out,
+		Commands: []*cli.Command{rawCommand(runner)},
+	}
+
+	require.NoError(t, app.Run([]string{"djictl", "raw", "--interface", "app->battery", "--type", "get_battery_info"}))
+	require.Contains(t, out.String(), "-> app->battery")
+	require.Contains(t, out.String(), "<- battery->app")
+
+	out.Reset()
+	require.NoError(t, app.Run([]string{"djictl", "raw", "--interface", "app->0x01", "--cmdset", "Info", "--cmdid", "0x1e", "--id", "0x1234", "--payload", "00 01"}))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Contains(t, lines[0], "ID:1234")
+	require.Contains(t, lines[0], duml.MessageTypeGetVersion.String())
+	require.Contains(t, lines[1], "ID:1234")
+
+	// a notification is sent without waiting
+	out.Reset()
+	require.NoError(t, app.Run([]string{"djictl", "raw", "--cmdset", "0x02", "--cmdid", "0xFE", "--flags", "Request"}))
+	require.Len(t, strings.Split(strings.TrimSpace(out.String()), "\n"), 1)
+
+	received := camera.State().Received
+	require.Equal(t, duml.MessageTypeGetBatteryInfo, received[0].Type)
+	require.Equal(t, duml.MessageID(0x1234), received[1].ID)
+	require.Equal(t, []byte{0x00, 0x01}, received[1].Payload)
+	require.Eventually(t, func() bool { return len(camera.State().Received) == 3 }, time.Second, time.Millisecond)
+
+	require.Error(t, app.Run([]string{"djictl", "raw", "--cmdset", "Info"}))
+	require.Error(t, app.Run([]string{"djictl", "raw", "--type", "nonexistent"}))
+	require.Error(t, app.Run([]string{"djictl", "raw", "--type", "get_version", "--payload", "xyz"}))
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+func TestRecordWithEmulator(t *testing.T) {
+	ctx := newTestContext(t, logger.LevelWarning)
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoAction5Pro)
+	app, out := newEmulatorConnApp(ctx, t, camera)
+
+	require.NoError(t, app.Run([]string{"djictl", "record", "start", "--experimental"}))
+	require.True(t, camera.State().Recording)
+	require.NoError(t, app.Run([]string{"djictl", "record", "photo", "--experimental"}))
+	require.Equal(t, 1, camera.State().PhotosTaken)
+	require.NoError(t, app.Run([]string{"djictl", "record", "stop", "--experimental"}))
+	require.False(t, camera.State().Recording)
+
+	var actions [][]byte
+	for _, msg := range camera.State().Received {
+		if msg.Type == duml.MessageTypeTakeRecord {
+			actions = append(actions, msg.Payload)
+		}
+	}
+	// start (0x01), photo (0x02), stop (0x00)
+	require.Equal(t, [][]byte{{0x01}, {0x02}, {0x00}}, actions)
+	require.Equal(t, "recording: true; duration: 0s\nphoto: done\nrecording: false\n", string(out.Bytes()))
+}
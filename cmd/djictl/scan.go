@@ -0,0 +1,12 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/xaionaro-go/djictl/pkg/djible"
+)
+
+func printScanResult(w io.Writer, dev *djible.Device) {
+	fmt.Fprintf(w, "Found device: %s: %s\n", dev, dev.Advertisement)
+}
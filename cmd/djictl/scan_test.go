@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/djictl/pkg/djible"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+func TestScanAdvertisementWithEmulator(t *testing.T) {
+	ctx := newTestContext(t, logger.LevelWarning)
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoPocket3)
+	camera.AdvertisementStatus = 0x02
+	bleDev := camera.NewBLEDevice(ctx)
+	defer bleDev.Close()
+	devCh, errCh, err := djible.ScanWithDevice(ctx, bleDev)
+	require.NoError(t, err)
+
+	var dev *djible.Device
+	select {
+	case dev = <-devCh:
+	case err := <-errCh:
+		t.Fatal(err)
+	case <-ctx.Done():
+		t.Fatal("the emulated device was not found")
+	}
+
+	require.Equal(t, duml.DeviceTypeOsmoPocket3, dev.Advertisement.DeviceType)
+	require.Equal(t, camera.Name, dev.Advertisement.LocalName)
+	require.True(t, dev.Advertisement.HasStatus)
+	require.Equal(t, duml.AdvertisementStatus(0x02), dev.Advertisement.Status)
+
+	var out bytes.Buffer
+	printScanResult(&out, dev)
+	require.Contains(t, out.String(), "model: osmo-pocket-3; variant: 0; RSSI: 0dBm; status: 0x02 (undecoded)\n")
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/urfave/cli/v2"
+	"github.com/xaionaro-go/djictl/pkg/djiapi"
+	"github.com/xaionaro-go/djictl/pkg/djible"
+	"github.com/xaionaro-go/djictl/pkg/djirecord"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+const (
+	shellPrompt = "djictl> "
+
+	// shellPushesLimit is the amount of the recent pushes kept for the "pushes" command.
+	shellPushesLimit = 256
+)
+
+var errShellExit = errors.New("exit")
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	locker sync.Mutex
+	buf    bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	return bytes.Clone(b.buf.Bytes())
+}
+
+// shell is an interactive console over an established connection to a djible.Device.
+type shell struct {
+	Device *djible.Device
+
+	transcript syncBuffer
+
+	pushesLocker sync.Mutex
+	pushes       []*decodedFrame
+}
+
+// newShell records the transcript of the session (see the "save" command)
+// and collects the pushes (see the "pushes" command); it should be called before Init.
+func newShell(ctx context.Context, dev *djible.Device) *shell {
+	sh := &shell{Device: dev}
+
+	record := djirecord.NewRecorder(&sh.transcript).BLEFrameHook()
+	prevOnFrame := dev.OnFrame
+	dev.OnFrame = func(ctx context.Context, direction duml.Direction, handle uint16, b []byte) {
+		if prevOnFrame != nil {
+			prevOnFrame(ctx, direction, handle, b)
+		}
+		record(ctx, direction, handle, b)
+	}
+
+	sub := dev.Subscribe(ctx, duml.FilterResponses(false), duml.SubscribeOverflowPolicy(duml.OverflowPolicyDropOldest))
+	go func() {
+		defer sub.Close()
+		for {
+			msg, err := sub.Receive(ctx)
+			if err != nil {
+				logger.Debugf(ctx, "stopped collecting the pushes: %v", err)
+				return
+			}
+			sh.pushesLocker.Lock()
+			sh.pushes = append(sh.pushes, &decodedFrame{
+				Timestamp: time.Now(),
+				Direction: frameDirectionFromDevice,
+				Message:   msg,
+			})
+			if len(sh.pushes) > shellPushesLimit {
+				sh.pushes = sh.pushes[len(sh.pushes)-shellPushesLimit:]
+			}
+			sh.pushesLocker.Unlock()
+		}
+	}()
+	return sh
+}
+
+// Run reads the commands line by line until "exit", the end of the input or
+// the cancellation of the context.
+func (sh *shell) Run(
+	ctx context.Context,
+	in io.Reader,
+	out io.Writer,
+) error {
+	app := sh.newApp(ctx, out)
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, shellPrompt)
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		args, err := splitShellArgs(scanner.Text())
+		if err != nil {
+			fmt.Fprintf(out, "Error: %v\n", err)
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		err = app.Run(append([]string{""}, args...))
+		switch {
+		case errors.Is(err, errShellExit):
+			return nil
+		case err == nil, errors.Is(err, errDone):
+		default:
+			fmt.Fprintf(out, "Error: %v\n", err)
+		}
+	}
+}
+
+func (sh *shell) newApp(ctx context.Context, out io.Writer) *cli.App {
+	dev := sh.Device
+	run := func(c *cli.Context, action func(ctx context.Context, conn duml.Conn) error) error {
+		return action(ctx, dev)
+	}
+	return &cli.App{
+		Name:      "",
+		Usage:     "commands over the established connection",
+		Writer:    out,
+		ErrWriter: out,
+		CommandNotFound: func(c *cli.Context, command string) {
+			fmt.Fprintf(out, "unknown command '%s', see 'help'\n", command)
+		},
+		Commands: append([]*cli.Command{
+			{
+				Name:  "pair",
+				Usage: "Pair with the device",
+				Action: func(c *cli.Context) error {
+					return dev.AppToWiFiGroundStation().Pair(ctx)
+				},
+			},
+			{
+				Name:  "wifi-connect",
+				Usage: "Make the device connect to a WiFi network",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "ssid", Usage: "WiFi SSID", Required: true},
+					&cli.StringFlag{Name: "psk", Usage: "WiFi Password", Required: true},
+				},
+				Action: func(c *cli.Context) error {
+					return dev.AppToWiFiGroundStation().ConnectToWiFi(ctx, c.String("ssid"), c.String("psk"))
+				},
+			},
+			{
+				Name:  "stream-start",
+				Usage: "Configure and start the live stream (without waiting for it to end)",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "rtmp-url", Usage: "RTMP URL", Required: true},
+					&cli.StringFlag{Name: "resolution", Usage: "Video resolution (allowed values: 480p, 720p, 1080p)", Value: "1080p"},
+					&cli.UintFlag{Name: "bitrate-kbps", Usage: "bitrate in Kbps", Value: 6000},
+					&cli.UintFlag{Name: "fps", Usage: "frames per second (allowed values: 25, 30)", Value: 30},
+				},
+				Action: func(c *cli.Context) error {
+					resolution := duml.ResolutionFromString(c.String("resolution"))
+					if resolution == duml.UndefinedResolution {
+						return fmt.Errorf("invalid resolution value %q", c.String("resolution"))
+					}
+					fps := duml.FPSFromUint(uint(c.Uint("fps")))
+					if fps == duml.UndefinedFPS {
+						return fmt.Errorf("invalid fps value %d", c.Uint("fps"))
+					}
+					videoTransmission := djiapi.AppToVideoTransmission(dev)
+					if _, err := videoTransmission.RequestConfigureLiveStream(ctx, resolution, uint16(c.Uint("bitrate-kbps")), fps, c.String("rtmp-url")); err != nil {
+						return fmt.Errorf("unable to configure the live stream: %w", err)
+					}
+					if _, err := videoTransmission.RequestStartLiveStream(ctx); err != nil {
+						return fmt.Errorf("unable to start the live stream: %w", err)
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "stream-stop",
+				Usage: "Stop the live stream",
+				Action: func(c *cli.Context) error {
+					return djiapi.AppToVideoTransmission(dev).StopLiveStream(ctx)
+				},
+			},
+			{
+				Name:  "pushes",
+				Usage: "List the recent messages pushed by the device",
+				Flags: []cli.Flag{
+					&cli.IntFlag{Name: "count", Usage: "Amount of the messages to list (negative for all)", Value: 20},
+					&cli.BoolFlag{Name: "clear", Usage: "Forget the listed messages"},
+				},
+				Action: func(c *cli.Context) error {
+					sh.pushesLocker.Lock()
+					defer sh.pushesLocker.Unlock()
+					pushes := sh.pushes
+					if count := c.Int("count"); count >= 0 && len(pushes) > count {
+						pushes = pushes[len(pushes)-count:]
+					}
+					for _, frame := range pushes {
+						fmt.Fprintln(out, formatFrame(frame))
+					}
+					if c.Bool("clear") {
+						sh.pushes = nil
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "parameters",
+				Usage:     "List the last known values of the parameters pushed by the device (all, if no names are given)",
+				ArgsUsage: "[NAME...]",
+				Action: func(c *cli.Context) error {
+					params := dev.Parameters.List()
+					if c.Args().Present() {
+						params = nil
+						for _, name := range c.Args().Slice() {
+							param, ok := dev.Parameters.Get(name)
+							if !ok {
+								fmt.Fprintf(out, "%s: <unknown>\n", name)
+								continue
+							}
+							params = append(params, param)
+						}
+					}
+					for _, param := range params {
+						fmt.Fprintf(out, "%s: %s (updated at %s)\n", param.Name, param.Value, param.UpdatedAt.Format(time.TimeOnly))
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "ack",
+				Usage:     "Enable or disable acknowledging the messages received from the device",
+				ArgsUsage: "on|off",
+				Action: func(c *cli.Context) error {
+					switch c.Args().First() {
+					case "on":
+						dev.NoAutoACK.Store(false)
+					case "off":
+						dev.NoAutoACK.Store(true)
+					case "":
+					default:
+						return fmt.Errorf("expected 'on' or 'off', got '%s'", c.Args().First())
+					}
+					state := "on"
+					if dev.NoAutoACK.Load() {
+						state = "off"
+					}
+					fmt.Fprintf(out, "ack: %s\n", state)
+					return nil
+				},
+			},
+			{
+				Name:      "save",
+				Usage:     "Save the transcript of the session (see 'emulator --replay')",
+				ArgsUsage: "FILE",
+				Action: func(c *cli.Context) error {
+					path := c.Args().First()
+					if path == "" {
+						return fmt.Errorf("the file is not specified")
+					}
+					if err := os.WriteFile(path, sh.transcript.Bytes(), 0644); err != nil {
+						return fmt.Errorf("unable to save the transcript: %w", err)
+					}
+					return nil
+				},
+			},
+			{
+				Name:    "exit",
+				Aliases: []string{"quit"},
+				Usage:   "Disconnect and exit",
+				Action: func(c *cli.Context) error {
+					return errShellExit
+				},
+			},
+		}, connCommands(run)...),
+	}
+}
+
+// splitShellArgs splits the line into arguments separated by whitespace;
+// single and double quotes group the arguments.
+func splitShellArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		cur     strings.Builder
+		inArg   bool
+		quoteCh rune
+	)
+	for _, ch := range line {
+		switch {
+		case quoteCh != 0:
+			if ch == quoteCh {
+				quoteCh = 0
+				continue
+			}
+			cur.WriteRune(ch)
+		case ch == '\'' || ch == '"':
+			quoteCh = ch
+			inArg = true
+		case ch == ' ' || ch == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(ch)
+			inArg = true
+		}
+	}
+	if quoteCh != 0 {
+		return nil, fmt.Errorf("unterminated quote %c", quoteCh)
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// runShell connects to the device and runs the shell until "exit".
+func runShell(
+	ctx context.Context,
+	dev *djible.Device,
+	in io.Reader,
+	out io.Writer,
+) error {
+	sh := newShell(ctx, dev)
+	if err := dev.Init(ctx); err != nil {
+		return fmt.Errorf("unable to initialize the device: %w", err)
+	}
+	if err := sh.Run(ctx, in, out); err != nil {
+		return err
+	}
+	return errDone
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/djictl/pkg/djible"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/djirecord"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+func TestShellWithEmulator(t *testing.T) {
+	ctx := newTestContext(t, logger.LevelWarning)
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoPocket3)
+	camera.PINApprovalDelay = 10 * time.Millisecond
+	camera.BatteryPushInterval = 10 * time.Millisecond
+	bleDev := camera.NewBLEDevice(ctx)
+	defer bleDev.Close()
+	devCh, errCh, err := djible.ScanWithDevice(ctx, bleDev)
+	require.NoError(t, err)
+	var dev *djible.Device
+	select {
+	case dev = <-devCh:
+	case err := <-errCh:
+		t.Fatal(err)
+	case <-ctx.Done():
+		t.Fatal("the emulated device was not found")
+	}
+
+	transcriptPath := filepath.Join(t.TempDir(), "transcript.jsonl")
+	in := strings.Join([]string{
+		"pair",
+		"battery-info",
+		"gimbal status",
+		"raw --interface app->battery --type get_battery_info",
+		"wifi-connect --ssid 'my network' --psk secret",
+		"stream-start --rtmp-url rtmp://127.0.0.1/live/test --resolution 720p",
+		"stream-stop",
+		"pushes --count -1 --clear",
+		"parameters product_shielded_config nonexistent",
+		"ack off",
+		"nonexistent",
+		"save " + transcriptPath,
+		"exit",
+		"battery-info",
+	}, "\n")
+	var out bytes.Buffer
+	require.ErrorIs(t, runShell(ctx, dev, strings.NewReader(in), &out), errDone)
+
+	require.Contains(t, out.String(), "Battery capacity: ")
+	require.Contains(t, out.String(), "<- battery->app")
+	require.Contains(t, out.String(), "yaw: 0.0°; pitch: 0.0°; roll: 0.0°; mode: follow")
+	require.Contains(t, out.String(), "ack: off")
+	require.Contains(t, out.String(), "product_shielded_config: 0 (0x00000000)")
+	require.Contains(t, out.String(), "nonexistent: <unknown>")
+	require.Contains(t, out.String(), duml.MessageTypeBatteryStatus.String())
+	require.Contains(t, out.String(), "unknown command 'nonexistent'")
+	require.Equal(t, 1, strings.Count(out.String(), "Battery capacity: "), "the commands after 'exit' should be ignored")
+	require.True(t, dev.NoAutoACK.Load())
+
+	state := camera.State()
+	require.True(t, state.Paired)
+	require.Equal(t, "my network", state.WiFiSSID)
+	require.Equal(t, "secret", state.WiFiPSK)
+	require.NotNil(t, state.LiveStream)
+	require.Equal(t, "rtmp://127.0.0.1/live/test", state.LiveStream.URL)
+
+	entries, err := djirecord.ReadFile(transcriptPath)
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+}
+
+func TestSplitShellArgs(t *testing.T) {
+	args, err := splitShellArgs(`raw  --payload "00 01" --id '' x`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"raw", "--payload", "00 01", "--id", "", "x"}, args)
+
+	args, err = splitShellArgs("  ")
+	require.NoError(t, err)
+	require.Empty(t, args)
+
+	_, err = splitShellArgs(`save "file`)
+	require.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"github.com/xaionaro-go/djictl/pkg/djiapi"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// videoFormatCommand returns the command getting and setting the format of the local recording.
+func videoFormatCommand(run connRunner) *cli.Command {
+	return &cli.Command{
+		Name:  "video-format",
+		Usage: "Print the format of the local recording or change it (the values not given are kept) [experimental]",
+		Flags: []cli.Flag{
+			experimentalFlag,
+			&cli.StringFlag{
+				Name:  "resolution",
+				Usage: "Resolution (allowed values: 1080p, 2.7k, 4k)",
+			},
+			&cli.UintFlag{
+				Name:  "fps",
+				Usage: "Frame rate (allowed values: 24, 25, 30, 48, 50, 60, 100, 120)",
+			},
+			&cli.StringFlag{
+				Name:  "aspect-ratio",
+				Usage: "Aspect ratio (allowed values: 16:9, 4:3)",
+			},
+			&cli.StringFlag{
+				Name:  "orientation",
+				Usage: "Orientation (allowed values: landscape, portrait)",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Value: 5 * time.Second,
+				Usage: "Time to wait for the camera to report the format",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if err := checkExperimental(c); err != nil {
+				return err
+			}
+			var change duml.VideoFormat
+			if s := c.String("resolution"); s != "" {
+				if change.Resolution = duml.VideoResolutionFromString(s); change.Resolution == duml.VideoResolutionUndefined {
+					return fmt.Errorf("unknown resolution '%s'", s)
+				}
+			}
+			if c.IsSet("fps") {
+				if change.FPS = duml.VideoFPSFromString(fmt.Sprint(c.Uint("fps"))); change.FPS == 0 {
+					return fmt.Errorf("unsupported FPS %d", c.Uint("fps"))
+				}
+			}
+			if s := c.String("aspect-ratio"); s != "" {
+				if change.AspectRatio = duml.AspectRatioFromString(s); change.AspectRatio == duml.AspectRatioUndefined {
+					return fmt.Errorf("unknown aspect ratio '%s'", s)
+				}
+			}
+			if s := c.String("orientation"); s != "" {
+				if change.Orientation = duml.OrientationFromString(s); change.Orientation == duml.OrientationUndefined {
+					return fmt.Errorf("unknown orientation '%s'", s)
+				}
+			}
+			return run(c, func(ctx context.Context, conn duml.Conn) error {
+				ctx, cancel := context.WithTimeout(ctx, c.Duration("timeout"))
+				defer cancel()
+				camera := djiapi.AppToCamera(conn).WithExperimental()
+				format, err := camera.GetVideoFormat(ctx)
+				if err != nil {
+					return err
+				}
+				if change != (duml.VideoFormat{}) {
+					format, err = camera.SetVideoFormat(ctx, mergeVideoFormat(*format, change))
+					if err != nil {
+						return err
+					}
+				}
+				fmt.Fprintf(c.App.Writer, "Video format: %s\n", format)
+				return nil
+			})
+		},
+	}
+}
+
+// mergeVideoFormat returns the format with the non-zero fields of change applied.
+func mergeVideoFormat(format, change duml.VideoFormat) duml.VideoFormat {
+	if change.Resolution != duml.VideoResolutionUndefined {
+		format.Resolution = change.Resolution
+	}
+	if change.FPS != 0 {
+		format.FPS = change.FPS
+	}
+	if change.AspectRatio != duml.AspectRatioUndefined {
+		format.AspectRatio = change.AspectRatio
+	}
+	if change.Orientation != duml.OrientationUndefined {
+		format.Orientation = change.Orientation
+	}
+	return format
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+func TestVideoFormatWithEmulator(t *testing.T) {
+	ctx := newTestContext(t, logger.LevelWarning)
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoAction4)
+	app, out := newEmulatorConnApp(ctx, t, camera)
+
+	require.NoError(t, app.Run([]string{"djictl", "video-format", "--experimental"}))
+	require.NoError(t, app.Run([]string{"djictl", "video-format", "--experimental", "--resolution", "4k", "--fps", "60"}))
+	received := camera.State().Received
+	// set (0x01), 1 item: key 0x0003, 4 bytes: 4k (0x16), 60fps (0x06), 16:9 (0x00), landscape (0x00)
+	require.Equal(t, []byte{0x01, 0x01, 0x03, 0x00, 0x04, 0x16, 0x06, 0x00, 0x00}, received[len(received)-1].Payload)
+	require.NoError(t, app.Run([]string{"djictl", "video-format", "--experimental", "--orientation", "portrait"}))
+	require.Equal(t, "Video format: 1080p, 30fps, 16:9, landscape\n"+
+		"Video format: 4k, 60fps, 16:9, landscape\n"+
+		"Video format: 4k, 60fps, 16:9, portrait\n", string(out.Bytes()))
+
+	require.ErrorContains(t, app.Run([]string{"djictl", "video-format", "--experimental", "--fps", "59"}), "unsupported FPS 59")
+	require.ErrorContains(t, app.Run([]string{"djictl", "video-format", "--experimental", "--resolution", "8k"}), "unknown resolution")
+}
@@ -0,0 +1,236 @@
+package btsnoop
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+)
+
+const (
+	aclHeaderLength   = 4
+	l2capHeaderLength = 4
+
+	aclPacketBoundaryContinuation = 0x01
+
+	// CIDATT is the L2CAP channel of the Attribute Protocol.
+	CIDATT = uint16(0x0004)
+)
+
+// ATTOpcode is the opcode of an Attribute Protocol PDU.
+type ATTOpcode uint8
+
+const (
+	ATTOpcodeWriteRequest            = ATTOpcode(0x12)
+	ATTOpcodeWriteResponse           = ATTOpcode(0x13)
+	ATTOpcodeHandleValueNotification = ATTOpcode(0x1B)
+	ATTOpcodeHandleValueIndication   = ATTOpcode(0x1D)
+	ATTOpcodeWriteCommand            = ATTOpcode(0x52)
+)
+
+func (op ATTOpcode) String() string {
+	switch op {
+	case ATTOpcodeWriteRequest:
+		return "write_request"
+	case ATTOpcodeWriteResponse:
+		return "write_response"
+	case ATTOpcodeHandleValueNotification:
+		return "handle_value_notification"
+	case ATTOpcodeHandleValueIndication:
+		return "handle_value_indication"
+	case ATTOpcodeWriteCommand:
+		return "write_command"
+	default:
+		return fmt.Sprintf("0x%02X", uint8(op))
+	}
+}
+
+// HasHandleValue returns true if the PDU is "<opcode> <handle:2> <value>".
+func (op ATTOpcode) HasHandleValue() bool {
+	switch op {
+	case ATTOpcodeWriteRequest, ATTOpcodeWriteCommand,
+		ATTOpcodeHandleValueNotification, ATTOpcodeHandleValueIndication:
+		return true
+	default:
+		return false
+	}
+}
+
+// L2CAPFrame is a reassembled L2CAP basic frame.
+type L2CAPFrame struct {
+	Timestamp        time.Time
+	Direction        Direction
+	ConnectionHandle uint16
+	ChannelID        uint16
+	Payload          []byte
+}
+
+// ATTPacket is an Attribute Protocol PDU that writes or notifies a value of a handle.
+type ATTPacket struct {
+	Timestamp        time.Time
+	Direction        Direction
+	ConnectionHandle uint16
+	Opcode           ATTOpcode
+	Handle           uint16
+	Value            []byte
+}
+
+type reassemblyKey struct {
+	Direction        Direction
+	ConnectionHandle uint16
+}
+
+type reassemblyBuffer struct {
+	timestamp time.Time
+	expected  int
+	data      []byte
+}
+
+// L2CAPReassembler reassembles L2CAP frames from (potentially fragmented) HCI ACL packets.
+type L2CAPReassembler struct {
+	partial map[reassemblyKey]*reassemblyBuffer
+}
+
+func NewL2CAPReassembler() *L2CAPReassembler {
+	return &L2CAPReassembler{
+		partial: map[reassemblyKey]*reassemblyBuffer{},
+	}
+}
+
+// Feed consumes a record and returns an L2CAP frame if one was completed.
+//
+// On an error the incomplete frame of the connection (if any) is discarded,
+// so the following start fragment is reassembled normally.
+func (a *L2CAPReassembler) Feed(rec *Record) (*L2CAPFrame, error) {
+	if rec.PacketType != HCIPacketTypeACLData {
+		return nil, nil
+	}
+	if len(rec.Data) < aclHeaderLength {
+		return nil, fmt.Errorf("ACL packet is too short: %d", len(rec.Data))
+	}
+	handleAndFlags := binary.LittleEndian.Uint16(rec.Data[0:2])
+	key := reassemblyKey{
+		Direction:        rec.Direction,
+		ConnectionHandle: handleAndFlags & 0x0FFF,
+	}
+	length := int(binary.LittleEndian.Uint16(rec.Data[2:4]))
+	data := rec.Data[aclHeaderLength:]
+	if len(data) < length {
+		delete(a.partial, key)
+		return nil, fmt.Errorf("ACL packet is truncated: %d < %d", len(data), length)
+	}
+	data = data[:length]
+
+	var buf *reassemblyBuffer
+	if (handleAndFlags>>12)&0x03 == aclPacketBoundaryContinuation {
+		buf = a.partial[key]
+		if buf == nil {
+			return nil, fmt.Errorf("received a continuation fragment without the start fragment")
+		}
+		buf.data = append(buf.data, data...)
+	} else {
+		// a start fragment discards any incomplete frame received before it
+		if len(data) < l2capHeaderLength {
+			delete(a.partial, key)
+			return nil, fmt.Errorf("L2CAP header is truncated: %d", len(data))
+		}
+		buf = &reassemblyBuffer{
+			timestamp: rec.Timestamp,
+			expected:  l2capHeaderLength + int(binary.LittleEndian.Uint16(data[0:2])),
+			data:      append([]byte{}, data...),
+		}
+		a.partial[key] = buf
+	}
+
+	if len(buf.data) < buf.expected {
+		return nil, nil
+	}
+	delete(a.partial, key)
+	if len(buf.data) > buf.expected {
+		return nil, fmt.Errorf("L2CAP frame is longer than expected: %d > %d", len(buf.data), buf.expected)
+	}
+	return &L2CAPFrame{
+		Timestamp:        buf.timestamp,
+		Direction:        key.Direction,
+		ConnectionHandle: key.ConnectionHandle,
+		ChannelID:        binary.LittleEndian.Uint16(buf.data[2:4]),
+		Payload:          buf.data[l2capHeaderLength:],
+	}, nil
+}
+
+// ParseATTPacket parses an L2CAP frame of the ATT channel. It returns nil
+// if the PDU is not a write/notification/indication.
+func ParseATTPacket(frame *L2CAPFrame) (*ATTPacket, error) {
+	if frame.ChannelID != CIDATT {
+		return nil, nil
+	}
+	if len(frame.Payload) < 1 {
+		return nil, fmt.Errorf("empty ATT PDU")
+	}
+	opcode := ATTOpcode(frame.Payload[0])
+	if !opcode.HasHandleValue() {
+		return nil, nil
+	}
+	if len(frame.Payload) < 3 {
+		return nil, fmt.Errorf("ATT PDU %s is too short: %d", opcode, len(frame.Payload))
+	}
+	return &ATTPacket{
+		Timestamp:        frame.Timestamp,
+		Direction:        frame.Direction,
+		ConnectionHandle: frame.ConnectionHandle,
+		Opcode:           opcode,
+		Handle:           binary.LittleEndian.Uint16(frame.Payload[1:3]),
+		Value:            frame.Payload[3:],
+	}, nil
+}
+
+// ATTReader reads ATT writes/notifications/indications from a btsnoop file.
+//
+// The malformed ACL fragments and ATT PDUs (e.g. the continuation fragments
+// of a capture started mid-stream) are logged to Logger and skipped.
+type ATTReader struct {
+	Reader      *Reader
+	Reassembler *L2CAPReassembler
+	Logger      logger.Logger
+}
+
+func NewATTReader(r io.Reader) (*ATTReader, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &ATTReader{
+		Reader:      reader,
+		Reassembler: NewL2CAPReassembler(),
+		Logger:      logger.Default(),
+	}, nil
+}
+
+// ReadATTPacket returns the next ATT packet or io.EOF.
+func (r *ATTReader) ReadATTPacket() (*ATTPacket, error) {
+	for {
+		rec, err := r.Reader.ReadRecord()
+		if err != nil {
+			return nil, err
+		}
+		frame, err := r.Reassembler.Feed(rec)
+		if err != nil {
+			r.Logger.Warnf("skipping the ACL fragment at %s (%X): %v", rec.Timestamp, rec.Data, err)
+			continue
+		}
+		if frame == nil {
+			continue
+		}
+		pkt, err := ParseATTPacket(frame)
+		if err != nil {
+			r.Logger.Warnf("skipping the ATT PDU at %s (%X): %v", frame.Timestamp, frame.Payload, err)
+			continue
+		}
+		if pkt == nil {
+			continue
+		}
+		return pkt, nil
+	}
+}
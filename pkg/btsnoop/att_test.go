@@ -0,0 +1,129 @@
+package btsnoop
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testRecord struct {
+	Timestamp time.Time
+	Direction Direction
+	Data      []byte // H4 packet, including the packet type byte
+}
+
+func testBTSnoopFile(records ...testRecord) []byte {
+	var buf bytes.Buffer
+	buf.Write(fileMagic)
+	buf.Write(binary.BigEndian.AppendUint32(nil, 1))
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(DataLinkTypeHCIUART)))
+	for _, rec := range records {
+		hdr := make([]byte, 24)
+		binary.BigEndian.PutUint32(hdr[0:4], uint32(len(rec.Data)))
+		binary.BigEndian.PutUint32(hdr[4:8], uint32(len(rec.Data)))
+		if rec.Direction == DirectionReceived {
+			binary.BigEndian.PutUint32(hdr[8:12], flagReceived)
+		}
+		binary.BigEndian.PutUint64(hdr[16:24], uint64(rec.Timestamp.UnixMicro()+epochOffset))
+		buf.Write(hdr)
+		buf.Write(rec.Data)
+	}
+	return buf.Bytes()
+}
+
+// testACLFragments wraps an ATT PDU into an L2CAP frame split into HCI ACL
+// packets with up to fragmentSize bytes of data each.
+func testACLFragments(connHandle uint16, attPDU []byte, fragmentSize int) [][]byte {
+	l2cap := binary.LittleEndian.AppendUint16(nil, uint16(len(attPDU)))
+	l2cap = binary.LittleEndian.AppendUint16(l2cap, CIDATT)
+	l2cap = append(l2cap, attPDU...)
+
+	var result [][]byte
+	for offset := 0; offset < len(l2cap); offset += fragmentSize {
+		end := min(offset+fragmentSize, len(l2cap))
+		handleAndFlags := connHandle
+		if offset > 0 {
+			handleAndFlags |= aclPacketBoundaryContinuation << 12
+		} else {
+			handleAndFlags |= 0x02 << 12
+		}
+		pkt := []byte{byte(HCIPacketTypeACLData)}
+		pkt = binary.LittleEndian.AppendUint16(pkt, handleAndFlags)
+		pkt = binary.LittleEndian.AppendUint16(pkt, uint16(end-offset))
+		pkt = append(pkt, l2cap[offset:end]...)
+		result = append(result, pkt)
+	}
+	return result
+}
+
+func testATTPDU(opcode ATTOpcode, handle uint16, value []byte) []byte {
+	pdu := []byte{byte(opcode)}
+	pdu = binary.LittleEndian.AppendUint16(pdu, handle)
+	return append(pdu, value...)
+}
+
+func TestATTReader(t *testing.T) {
+	ts := time.UnixMicro(1700000000123456)
+	value := bytes.Repeat([]byte{0xAB}, 40)
+
+	var records []testRecord
+	records = append(records, testRecord{
+		Timestamp: ts,
+		Direction: DirectionSent,
+		Data:      []byte{byte(HCIPacketTypeCommand), 0x01, 0x0C, 0x00},
+	})
+	// the capture started in the middle of a frame
+	orphan := testACLFragments(0x0040, testATTPDU(ATTOpcodeWriteCommand, 0x0030, value), 27)[1]
+	records = append(records, testRecord{Timestamp: ts, Direction: DirectionSent, Data: orphan})
+	// a truncated ACL packet
+	records = append(records, testRecord{Timestamp: ts, Direction: DirectionSent, Data: []byte{byte(HCIPacketTypeACLData), 0x40, 0x20, 0x10, 0x00, 0x01}})
+	for _, frag := range testACLFragments(0x0040, testATTPDU(ATTOpcodeWriteCommand, 0x0030, value), 27) {
+		records = append(records, testRecord{Timestamp: ts, Direction: DirectionSent, Data: frag})
+	}
+	for _, frag := range testACLFragments(0x0040, testATTPDU(ATTOpcodeHandleValueNotification, 0x002D, []byte{1, 2, 3}), 27) {
+		records = append(records, testRecord{Timestamp: ts.Add(time.Second), Direction: DirectionReceived, Data: frag})
+	}
+	for _, frag := range testACLFragments(0x0040, []byte{byte(ATTOpcodeWriteResponse)}, 27) {
+		records = append(records, testRecord{Timestamp: ts.Add(time.Second), Direction: DirectionReceived, Data: frag})
+	}
+	require.Len(t, records, 1+2+2+1+1)
+
+	r, err := NewATTReader(bytes.NewReader(testBTSnoopFile(records...)))
+	require.NoError(t, err)
+
+	pkt, err := r.ReadATTPacket()
+	require.NoError(t, err)
+	require.Equal(t, &ATTPacket{
+		Timestamp:        ts,
+		Direction:        DirectionSent,
+		ConnectionHandle: 0x0040,
+		Opcode:           ATTOpcodeWriteCommand,
+		Handle:           0x0030,
+		Value:            value,
+	}, pkt)
+
+	pkt, err = r.ReadATTPacket()
+	require.NoError(t, err)
+	require.Equal(t, DirectionReceived, pkt.Direction)
+	require.Equal(t, ATTOpcodeHandleValueNotification, pkt.Opcode)
+	require.Equal(t, uint16(0x002D), pkt.Handle)
+	require.Equal(t, []byte{1, 2, 3}, pkt.Value)
+
+	_, err = r.ReadATTPacket()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestL2CAPReassemblerOrphanContinuation(t *testing.T) {
+	frags := testACLFragments(0x0001, testATTPDU(ATTOpcodeWriteCommand, 0x0030, make([]byte, 30)), 20)
+	require.Len(t, frags, 2)
+
+	_, err := NewL2CAPReassembler().Feed(&Record{
+		PacketType: HCIPacketTypeACLData,
+		Data:       frags[1][1:],
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,179 @@
+// Package btsnoop implements a reader of btsnoop files (e.g. Android
+// "Bluetooth HCI snoop log"), including reassembly of ATT packets.
+package btsnoop
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+var fileMagic = []byte("btsnoop\x00")
+
+const (
+	// epochOffset is the amount of microseconds between 0000-01-01 (the btsnoop epoch) and 1970-01-01.
+	epochOffset = int64(0x00dcddb30f2f8000)
+
+	flagReceived       = 0x01
+	flagCommandOrEvent = 0x02
+)
+
+// DataLinkType is the type of the records in the file.
+type DataLinkType uint32
+
+const (
+	DataLinkTypeHCIUnencapsulated = DataLinkType(1001)
+	DataLinkTypeHCIUART           = DataLinkType(1002)
+	DataLinkTypeHCIBSCP           = DataLinkType(1003)
+	DataLinkTypeHCISerial         = DataLinkType(1004)
+)
+
+// HCIPacketType is the type of an HCI packet (as in the H4 UART transport).
+type HCIPacketType uint8
+
+const (
+	HCIPacketTypeCommand = HCIPacketType(0x01)
+	HCIPacketTypeACLData = HCIPacketType(0x02)
+	HCIPacketTypeSCOData = HCIPacketType(0x03)
+	HCIPacketTypeEvent   = HCIPacketType(0x04)
+	HCIPacketTypeISOData = HCIPacketType(0x05)
+)
+
+func (t HCIPacketType) String() string {
+	switch t {
+	case HCIPacketTypeCommand:
+		return "command"
+	case HCIPacketTypeACLData:
+		return "acl"
+	case HCIPacketTypeSCOData:
+		return "sco"
+	case HCIPacketTypeEvent:
+		return "event"
+	case HCIPacketTypeISOData:
+		return "iso"
+	default:
+		return fmt.Sprintf("0x%02X", uint8(t))
+	}
+}
+
+// Direction is the direction of a record relatively to the host that captured it.
+type Direction uint8
+
+const (
+	// DirectionSent is host->controller (e.g. phone->camera).
+	DirectionSent = Direction(iota)
+	// DirectionReceived is controller->host (e.g. camera->phone).
+	DirectionReceived
+)
+
+func (d Direction) String() string {
+	switch d {
+	case DirectionSent:
+		return "sent"
+	case DirectionReceived:
+		return "received"
+	default:
+		return fmt.Sprintf("unknown_direction_%d", uint8(d))
+	}
+}
+
+// Record is a single record of a btsnoop file.
+type Record struct {
+	Timestamp      time.Time
+	Direction      Direction
+	PacketType     HCIPacketType
+	OriginalLength uint32
+	Drops          uint32
+
+	// Data is the HCI packet (without the H4 packet type byte).
+	Data []byte
+}
+
+// Reader reads records from a btsnoop file.
+type Reader struct {
+	r            io.Reader
+	DataLinkType DataLinkType
+}
+
+// NewReader reads the file header and returns a Reader of the records.
+func NewReader(r io.Reader) (*Reader, error) {
+	r = bufio.NewReader(r)
+	var hdr [16]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, fmt.Errorf("unable to read the btsnoop header: %w", err)
+	}
+	if !bytes.Equal(hdr[:8], fileMagic) {
+		return nil, fmt.Errorf("invalid btsnoop magic: %X", hdr[:8])
+	}
+	if version := binary.BigEndian.Uint32(hdr[8:12]); version != 1 {
+		return nil, fmt.Errorf("unsupported btsnoop version: %d", version)
+	}
+	result := &Reader{
+		r:            r,
+		DataLinkType: DataLinkType(binary.BigEndian.Uint32(hdr[12:16])),
+	}
+	switch result.DataLinkType {
+	case DataLinkTypeHCIUnencapsulated, DataLinkTypeHCIUART:
+	default:
+		return nil, fmt.Errorf("unsupported datalink type: %d", result.DataLinkType)
+	}
+	return result, nil
+}
+
+// IsBTSnoop returns true if the given file beginning looks like a btsnoop file.
+func IsBTSnoop(b []byte) bool {
+	return bytes.HasPrefix(b, fileMagic)
+}
+
+// ReadRecord returns the next record or io.EOF.
+func (r *Reader) ReadRecord() (*Record, error) {
+	var hdr [24]byte
+	if _, err := io.ReadFull(r.r, hdr[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("unable to read the record header: %w", err)
+		}
+		return nil, err
+	}
+	origLen := binary.BigEndian.Uint32(hdr[0:4])
+	inclLen := binary.BigEndian.Uint32(hdr[4:8])
+	flags := binary.BigEndian.Uint32(hdr[8:12])
+	drops := binary.BigEndian.Uint32(hdr[12:16])
+	ts := int64(binary.BigEndian.Uint64(hdr[16:24])) - epochOffset
+
+	data := make([]byte, inclLen)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return nil, fmt.Errorf("unable to read the record of length %d: %w", inclLen, err)
+	}
+
+	rec := &Record{
+		Timestamp:      time.UnixMicro(ts),
+		OriginalLength: origLen,
+		Drops:          drops,
+		Data:           data,
+	}
+	if flags&flagReceived != 0 {
+		rec.Direction = DirectionReceived
+	}
+
+	switch r.DataLinkType {
+	case DataLinkTypeHCIUART:
+		if len(data) < 1 {
+			return nil, fmt.Errorf("empty H4 record")
+		}
+		rec.PacketType = HCIPacketType(data[0])
+		rec.Data = data[1:]
+	default:
+		switch {
+		case flags&flagCommandOrEvent == 0:
+			rec.PacketType = HCIPacketTypeACLData
+		case rec.Direction == DirectionSent:
+			rec.PacketType = HCIPacketTypeCommand
+		default:
+			rec.PacketType = HCIPacketTypeEvent
+		}
+	}
+	return rec, nil
+}
@@ -0,0 +1,171 @@
+package btsnoop
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// Handles are the ATT value handles of the characteristics of a DJI device.
+type Handles struct {
+	// Receiver is the characteristic the device notifies DUML messages on.
+	Receiver uint16
+
+	// PairingRequestor is the characteristic the app writes pairing requests to (not DUML).
+	PairingRequestor uint16
+
+	// Sender is the characteristic the app writes DUML messages to.
+	Sender uint16
+}
+
+// DefaultHandles are the handles used by DJI Osmo devices (the same as in package djible).
+var DefaultHandles = Handles{
+	Receiver:         0x002D,
+	PairingRequestor: 0x002E,
+	Sender:           0x0030,
+}
+
+// SessionPacket is a DUML message (or a pairing request) extracted from a btsnoop file.
+type SessionPacket struct {
+	Timestamp        time.Time
+	Direction        Direction
+	ConnectionHandle uint16
+	Handle           uint16
+
+	// Message is nil for writes to Handles.PairingRequestor; see Value for these.
+	Message *duml.Message
+
+	// Value is the raw value of a write to Handles.PairingRequestor.
+	Value []byte
+}
+
+// IsPairingRequest returns true if the packet is a write to the pairing requestor characteristic.
+func (p *SessionPacket) IsPairingRequest() bool {
+	return p.Message == nil
+}
+
+func (p *SessionPacket) String() string {
+	if p.IsPairingRequest() {
+		return fmt.Sprintf("%s %s handle:%04X pairing_request:%X",
+			p.Timestamp.UTC().Format(time.RFC3339Nano), p.Direction, p.Handle, p.Value)
+	}
+	return fmt.Sprintf("%s %s handle:%04X %s",
+		p.Timestamp.UTC().Format(time.RFC3339Nano), p.Direction, p.Handle, p.Message)
+}
+
+type sessionStreamKey struct {
+	ConnectionHandle uint16
+	Handle           uint16
+}
+
+// Session extracts DUML messages from ATT writes and notifications of a btsnoop file.
+//
+// DUML frames split across multiple ATT packets are reassembled (per connection
+// and handle); the timestamp of a message is the timestamp of its last fragment.
+type Session struct {
+	Handles Handles
+
+	reader   *ATTReader
+	decoders map[sessionStreamKey]*duml.Decoder
+	queue    []*SessionPacket
+}
+
+// NewSession reads the btsnoop header and returns a Session using DefaultHandles.
+func NewSession(r io.Reader) (*Session, error) {
+	reader, err := NewATTReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		Handles:  DefaultHandles,
+		reader:   reader,
+		decoders: map[sessionStreamKey]*duml.Decoder{},
+	}, nil
+}
+
+// SetLogger sets the logger of the skipped malformed packets (see ATTReader).
+func (s *Session) SetLogger(l logger.Logger) {
+	s.reader.Logger = l
+}
+
+// ReadSession reads all the packets of a btsnoop file.
+func ReadSession(r io.Reader) ([]*SessionPacket, error) {
+	s, err := NewSession(r)
+	if err != nil {
+		return nil, err
+	}
+	var result []*SessionPacket
+	for {
+		pkt, err := s.Next()
+		if err != nil {
+			if err == io.EOF {
+				return result, nil
+			}
+			return result, err
+		}
+		result = append(result, pkt)
+	}
+}
+
+// Next returns the next packet or io.EOF.
+func (s *Session) Next() (*SessionPacket, error) {
+	for len(s.queue) == 0 {
+		attPacket, err := s.reader.ReadATTPacket()
+		if err != nil {
+			return nil, err
+		}
+		s.feed(attPacket)
+	}
+	pkt := s.queue[0]
+	s.queue = s.queue[1:]
+	return pkt, nil
+}
+
+func (s *Session) feed(attPacket *ATTPacket) {
+	switch attPacket.Handle {
+	case s.Handles.PairingRequestor:
+		s.queue = append(s.queue, &SessionPacket{
+			Timestamp:        attPacket.Timestamp,
+			Direction:        attPacket.Direction,
+			ConnectionHandle: attPacket.ConnectionHandle,
+			Handle:           attPacket.Handle,
+			Value:            attPacket.Value,
+		})
+		return
+	case s.Handles.Receiver, s.Handles.Sender:
+	default:
+		return
+	}
+
+	key := sessionStreamKey{
+		ConnectionHandle: attPacket.ConnectionHandle,
+		Handle:           attPacket.Handle,
+	}
+	decoder := s.decoders[key]
+	if decoder == nil {
+		decoder = duml.NewDecoder()
+		s.decoders[key] = decoder
+	}
+	for _, msg := range decoder.Decode(attPacket.Value) {
+		s.queue = append(s.queue, &SessionPacket{
+			Timestamp:        attPacket.Timestamp,
+			Direction:        attPacket.Direction,
+			ConnectionHandle: attPacket.ConnectionHandle,
+			Handle:           attPacket.Handle,
+			Message:          msg,
+		})
+	}
+}
+
+// DroppedBytes returns the total amount of bytes on the DUML characteristics
+// that were skipped since they are not a part of a valid DUML frame.
+func (s *Session) DroppedBytes() uint64 {
+	var result uint64
+	for _, decoder := range s.decoders {
+		result += decoder.DroppedBytes()
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+package btsnoop
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+func TestSession(t *testing.T) {
+	ts := time.UnixMicro(1700000000000000)
+	request, err := hex.DecodeString("551204c70402f6010004270000080000299d")
+	require.NoError(t, err)
+	response, err := hex.DecodeString("551f044e0702ea94c0070700104f736d6f506f636b6574332d36303934ccc8")
+	require.NoError(t, err)
+
+	var records []testRecord
+	add := func(ts time.Time, dir Direction, opcode ATTOpcode, handle uint16, value []byte) {
+		for _, frag := range testACLFragments(0x0040, testATTPDU(opcode, handle, value), 251) {
+			records = append(records, testRecord{Timestamp: ts, Direction: dir, Data: frag})
+		}
+	}
+	add(ts, DirectionSent, ATTOpcodeWriteRequest, DefaultHandles.PairingRequestor, []byte{0x01, 0x00})
+	add(ts.Add(1*time.Millisecond), DirectionSent, ATTOpcodeWriteCommand, DefaultHandles.Sender, request)
+	// an unrelated characteristic:
+	add(ts.Add(2*time.Millisecond), DirectionReceived, ATTOpcodeHandleValueNotification, 0x0010, response)
+	// the response split across two notifications:
+	add(ts.Add(3*time.Millisecond), DirectionReceived, ATTOpcodeHandleValueNotification, DefaultHandles.Receiver, response[:10])
+	add(ts.Add(4*time.Millisecond), DirectionReceived, ATTOpcodeHandleValueNotification, DefaultHandles.Receiver, response[10:])
+
+	pkts, err := ReadSession(bytes.NewReader(testBTSnoopFile(records...)))
+	require.NoError(t, err)
+	require.Len(t, pkts, 3)
+
+	require.True(t, pkts[0].IsPairingRequest())
+	require.Equal(t, []byte{0x01, 0x00}, pkts[0].Value)
+
+	require.False(t, pkts[1].IsPairingRequest())
+	require.Equal(t, DirectionSent, pkts[1].Direction)
+	require.Equal(t, ts.Add(1*time.Millisecond), pkts[1].Timestamp)
+	require.Equal(t, duml.MessageTypeKeepAlive, pkts[1].Message.Type)
+	require.Equal(t, request, pkts[1].Message.Bytes())
+
+	require.Equal(t, DirectionReceived, pkts[2].Direction)
+	require.Equal(t, DefaultHandles.Receiver, pkts[2].Handle)
+	require.Equal(t, ts.Add(4*time.Millisecond), pkts[2].Timestamp)
+	require.Equal(t, response, pkts[2].Message.Bytes())
+}
@@ -0,0 +1,54 @@
+package djiapi
+
+import (
+	"context"
+
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// DefaultDeviceInfoComponents are the components queried by GetDeviceInfo by default.
+var DefaultDeviceInfoComponents = []duml.ComponentID{
+	duml.ComponentIDCamera,
+	duml.ComponentIDGimbal,
+	duml.ComponentIDWiFiGroundStation,
+	duml.ComponentIDVideoTransmission,
+	duml.ComponentIDBattery,
+}
+
+// ComponentInfo is the identification of a component; the errors are set
+// for the queries the component failed to answer.
+type ComponentInfo struct {
+	Component       duml.ComponentID
+	Version         *duml.VersionInfo
+	VersionErr      error
+	SerialNumber    string
+	SerialNumberErr error
+	ProductID       string
+	ProductIDErr    error
+}
+
+// GetDeviceInfo queries the version, the serial number and the product ID of each component
+// (DefaultDeviceInfoComponents if none are given).
+//
+// Not every component answers every query, so the failures are reported in ComponentInfo
+// instead of aborting; the options are applied to each request (e.g. to lower the timeout).
+func GetDeviceInfo(
+	ctx context.Context,
+	conn duml.Conn,
+	components []duml.ComponentID,
+	opts ...duml.RequestOption,
+) []ComponentInfo {
+	if len(components) == 0 {
+		components = DefaultDeviceInfoComponents
+	}
+	result := make([]ComponentInfo, 0, len(components))
+	for _, component := range components {
+		iface := AppToComponent(conn, component)
+		info := ComponentInfo{Component: component}
+		info.Version, info.VersionErr = iface.GetVersion(ctx, opts...)
+		info.SerialNumber, info.SerialNumberErr = iface.GetSerialNumber(ctx, opts...)
+		info.ProductID, info.ProductIDErr = iface.GetProductID(ctx, opts...)
+		result = append(result, info)
+	}
+	return result
+}
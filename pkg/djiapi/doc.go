@@ -0,0 +1,3 @@
+// Package djiapi implements the typed commands of DJI devices on top of
+// a duml.Conn, so that they work over any transport (BLE, WiFi, ...).
+package djiapi
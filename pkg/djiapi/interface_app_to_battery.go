@@ -0,0 +1,87 @@
+package djiapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+type InterfaceAppToBattery struct {
+	conn duml.Conn
+}
+
+func AppToBattery(conn duml.Conn) *InterfaceAppToBattery {
+	return &InterfaceAppToBattery{conn: conn}
+}
+
+func (s *InterfaceAppToBattery) InterfaceID() duml.InterfaceID {
+	return duml.InterfaceIDAppToBattery
+}
+
+func (s *InterfaceAppToBattery) Conn() duml.Conn {
+	return s.conn
+}
+
+func (s *InterfaceAppToBattery) GetInfo(ctx context.Context) (*duml.BatteryStatus, error) {
+	msg := &duml.Message{
+		Interface: s.InterfaceID(),
+		Type:      duml.MessageTypeGetBatteryInfo,
+		AutoID:    true,
+	}
+	statusSub := s.Conn().Subscribe(ctx, duml.FilterType(duml.MessageTypeBatteryStatus))
+	defer statusSub.Close()
+
+	_, err := s.Conn().Request(ctx, msg, duml.RequestRetries(duml.DefaultQueryRetries))
+	if err != nil {
+		return nil, fmt.Errorf("unable to send GetBatteryInfo message: %w", err)
+	}
+	logger.Debugf(ctx, "waiting for the battery status")
+
+	msg, err = statusSub.Receive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debugf(ctx, "received a report about battery info: %#+v", msg)
+	status, err := duml.ParseBatteryStatusForDevice(ctx, s.Conn().DeviceType(), msg.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse battery status: %w", err)
+	}
+	return status, nil
+}
+
+// WatchStatus requests the battery status and then calls the callback on each
+// received battery status push, until the context is cancelled or the callback
+// returns an error.
+func (s *InterfaceAppToBattery) WatchStatus(
+	ctx context.Context,
+	callback func(*duml.BatteryStatus) error,
+) error {
+	statusSub := s.Conn().Subscribe(ctx, duml.FilterType(duml.MessageTypeBatteryStatus), duml.SubscribeOverflowPolicy(duml.OverflowPolicyDropOldest))
+	defer statusSub.Close()
+
+	_, err := s.Conn().Request(ctx, &duml.Message{
+		Interface: s.InterfaceID(),
+		Type:      duml.MessageTypeGetBatteryInfo,
+		AutoID:    true,
+	}, duml.RequestRetries(duml.DefaultQueryRetries))
+	if err != nil {
+		return fmt.Errorf("unable to send GetBatteryInfo message: %w", err)
+	}
+
+	for {
+		msg, err := statusSub.Receive(ctx)
+		if err != nil {
+			return err
+		}
+		status, err := duml.ParseBatteryStatusForDevice(ctx, s.Conn().DeviceType(), msg.Payload)
+		if err != nil {
+			logger.Warnf(ctx, "unable to parse battery status: %v", err)
+			continue
+		}
+		if err := callback(status); err != nil {
+			return err
+		}
+	}
+}
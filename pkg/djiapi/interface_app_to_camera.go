@@ -0,0 +1,34 @@
+package djiapi
+
+import (
+	"errors"
+
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// ErrExperimental is returned by the commands whose encodings are experimental
+// (see duml.KeyValueKey.IsExperimental), unless they are allowed explicitly (see
+// InterfaceAppToCamera.WithExperimental and InterfaceAppToGimbal.WithExperimental).
+var ErrExperimental = errors.New("the command is experimental")
+
+type InterfaceAppToCamera struct {
+	conn         duml.Conn
+	experimental bool
+}
+
+func AppToCamera(conn duml.Conn) *InterfaceAppToCamera {
+	return &InterfaceAppToCamera{conn: conn}
+}
+
+// WithExperimental returns the interface allowing the experimental commands (see ErrExperimental).
+func (s *InterfaceAppToCamera) WithExperimental() *InterfaceAppToCamera {
+	return &InterfaceAppToCamera{conn: s.conn, experimental: true}
+}
+
+func (s *InterfaceAppToCamera) InterfaceID() duml.InterfaceID {
+	return duml.InterfaceIDAppToCamera
+}
+
+func (s *InterfaceAppToCamera) Conn() duml.Conn {
+	return s.conn
+}
@@ -0,0 +1,94 @@
+package djiapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// SetExposure changes the non-nil exposure settings in a single request and
+// returns all the exposure settings reported by the device afterwards. An error
+// is returned without sending anything if a setting is invalid or not supported
+// by the device type.
+func (s *InterfaceAppToCamera) SetExposure(
+	ctx context.Context,
+	settings duml.ExposureSettings,
+) (*duml.ExposureSettings, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	if settings.ColorProfile != nil {
+		if p, ok := s.Conn().DeviceType().Profile(); ok && !p.SupportsColorProfile(*settings.ColorProfile) {
+			return nil, fmt.Errorf("color profile %s is not supported by %s", *settings.ColorProfile, s.Conn().DeviceType())
+		}
+	}
+	items, err := settings.Items()
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return s.GetExposure(ctx)
+	}
+	if _, err := s.SetKeyValues(ctx, items...); err != nil {
+		return nil, fmt.Errorf("unable to set the exposure settings: %w", err)
+	}
+	return s.GetExposure(ctx)
+}
+
+// SetEVCompensation sets the exposure compensation (see SetExposure).
+func (s *InterfaceAppToCamera) SetEVCompensation(ctx context.Context, ev duml.EVCompensation) (*duml.ExposureSettings, error) {
+	return s.SetExposure(ctx, duml.ExposureSettings{EV: &ev})
+}
+
+// SetISO sets the ISO mode and value (see SetExposure).
+func (s *InterfaceAppToCamera) SetISO(ctx context.Context, iso duml.ISOSetting) (*duml.ExposureSettings, error) {
+	return s.SetExposure(ctx, duml.ExposureSettings{ISO: &iso})
+}
+
+// SetShutter sets the shutter mode and speed (see SetExposure).
+func (s *InterfaceAppToCamera) SetShutter(ctx context.Context, shutter duml.ShutterSetting) (*duml.ExposureSettings, error) {
+	return s.SetExposure(ctx, duml.ExposureSettings{Shutter: &shutter})
+}
+
+// SetWhiteBalance sets the white balance (see SetExposure).
+func (s *InterfaceAppToCamera) SetWhiteBalance(ctx context.Context, wb duml.WhiteBalance) (*duml.ExposureSettings, error) {
+	return s.SetExposure(ctx, duml.ExposureSettings{WhiteBalance: &wb})
+}
+
+// SetColorProfile sets the color profile (see SetExposure).
+func (s *InterfaceAppToCamera) SetColorProfile(ctx context.Context, profile duml.ColorProfile) (*duml.ExposureSettings, error) {
+	return s.SetExposure(ctx, duml.ExposureSettings{ColorProfile: &profile})
+}
+
+// GetExposure returns the current exposure settings.
+func (s *InterfaceAppToCamera) GetExposure(ctx context.Context) (*duml.ExposureSettings, error) {
+	values, err := s.GetKeyValues(ctx, duml.ExposureKeys...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the exposure settings: %w", err)
+	}
+	var settings duml.ExposureSettings
+	for _, key := range duml.ExposureKeys {
+		if err := settings.Update(duml.KeyValueItem{Key: key, Value: values[key]}); err != nil {
+			return nil, err
+		}
+	}
+	return &settings, nil
+}
+
+// WatchExposure calls the callback with the exposure settings each time the
+// device pushes any of them, until the context is cancelled or the callback
+// returns an error. The settings not pushed yet are nil.
+func (s *InterfaceAppToCamera) WatchExposure(
+	ctx context.Context,
+	callback func(*duml.ExposureSettings) error,
+) error {
+	var settings duml.ExposureSettings
+	return s.WatchKeyValues(ctx, func(push *duml.KeyValuePush) error {
+		if err := settings.Update(push.Items...); err != nil {
+			return fmt.Errorf("unable to parse the exposure settings: %w", err)
+		}
+		current := settings
+		return callback(&current)
+	}, duml.ExposureKeys...)
+}
@@ -1,4 +1,4 @@
-package djible
+package djiapi
 
 import (
 	"context"
@@ -12,5 +12,5 @@ func (s *InterfaceAppToCamera) SetFCCEnable(ctx context.Context, enable bool) (*
 	// but the original code was: msg := duml.NewFCCEnableMessage(); msg.Interface = duml.InterfaceIDAppToCamera
 	// I'll assume NewFCCEnableMessage() currently just returns the "enable" command.
 	msg.Interface = duml.InterfaceIDAppToCamera
-	return s.Device().Request(ctx, msg, true)
+	return s.Conn().Request(ctx, msg)
 }
@@ -0,0 +1,28 @@
+package djiapi
+
+import (
+	"context"
+
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+func (s *InterfaceAppToCamera) GetBatteryInfo(ctx context.Context) (*duml.Message, error) {
+	msg := &duml.Message{
+		Interface: s.InterfaceID(),
+		Type:      duml.MessageTypeGetBatteryInfo,
+		AutoID:    true,
+	}
+	return s.Conn().Request(ctx, msg, duml.RequestRetries(duml.DefaultQueryRetries))
+}
+
+func (s *InterfaceAppToCamera) GetVersion(ctx context.Context) (*duml.VersionInfo, error) {
+	return AppToComponent(s.Conn(), s.InterfaceID().Receiver).GetVersion(ctx)
+}
+
+func (s *InterfaceAppToCamera) GetSerialNumber(ctx context.Context) (string, error) {
+	return AppToComponent(s.Conn(), s.InterfaceID().Receiver).GetSerialNumber(ctx)
+}
+
+func (s *InterfaceAppToCamera) GetProductID(ctx context.Context) (string, error) {
+	return AppToComponent(s.Conn(), s.InterfaceID().Receiver).GetProductID(ctx)
+}
@@ -0,0 +1,150 @@
+package djiapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// SetKeyValues sets the camera settings and returns their effective values, as
+// pushed back by the device (see duml.MessageTypeKeyValuePush); the values not
+// pushed back within duml.DefaultRequestTimeout are returned as requested,
+// since the device has acknowledged them.
+//
+// The experimental keys (see duml.KeyValueKey.IsExperimental) are rejected
+// with ErrExperimental unless allowed by WithExperimental.
+func (s *InterfaceAppToCamera) SetKeyValues(
+	ctx context.Context,
+	items ...duml.KeyValueItem,
+) (map[duml.KeyValueKey][]byte, error) {
+	keys := make([]duml.KeyValueKey, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, item.Key)
+	}
+	return s.requestKeyValues(ctx, &duml.KeyValueRequest{Op: duml.KeyValueOpSet, Items: items}, keys)
+}
+
+// GetKeyValues requests the current values of the camera settings, as pushed
+// by the device within duml.DefaultRequestTimeout.
+//
+// The experimental keys are rejected the same way as by SetKeyValues.
+func (s *InterfaceAppToCamera) GetKeyValues(
+	ctx context.Context,
+	keys ...duml.KeyValueKey,
+) (map[duml.KeyValueKey][]byte, error) {
+	return s.requestKeyValues(ctx, duml.NewKeyValueGetRequest(keys...), keys)
+}
+
+func (s *InterfaceAppToCamera) requestKeyValues(
+	ctx context.Context,
+	req *duml.KeyValueRequest,
+	keys []duml.KeyValueKey,
+) (_ret map[duml.KeyValueKey][]byte, _err error) {
+	logger.Tracef(ctx, "requestKeyValues(ctx, %s, %v)", req.Op, keys)
+	defer func() { logger.Tracef(ctx, "/requestKeyValues(ctx, %s, %v): %X %v", req.Op, keys, _ret, _err) }()
+
+	if !s.experimental {
+		for _, key := range keys {
+			if key.IsExperimental() {
+				return nil, fmt.Errorf("unable to %s the key 0x%04X: %w", req.Op, uint16(key), ErrExperimental)
+			}
+		}
+	}
+
+	pushSub := s.Conn().Subscribe(ctx, duml.FilterType(duml.MessageTypeKeyValuePush))
+	defer pushSub.Close()
+
+	payload, err := req.MarshalDUML()
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal the request: %w", err)
+	}
+	var opts []duml.RequestOption
+	if req.Op == duml.KeyValueOpGet {
+		opts = append(opts, duml.RequestRetries(duml.DefaultQueryRetries))
+	}
+	msg, err := s.Conn().Request(ctx, &duml.Message{
+		Interface: s.InterfaceID(),
+		AutoID:    true,
+		Type:      duml.MessageTypeStartStopStreaming,
+		Payload:   payload,
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to send the duml.Message: %w", err)
+	}
+	var result duml.Result
+	if err := result.UnmarshalDUML(msg.Payload); err != nil {
+		return nil, fmt.Errorf("unable to parse the result: %w", err)
+	}
+	if !result.IsSuccess() {
+		return nil, duml.NewRejectedError(msg, "unable to %s the keys %v", req.Op, keys)
+	}
+
+	pushCtx, cancel := context.WithTimeout(ctx, duml.DefaultRequestTimeout)
+	defer cancel()
+	values := make(map[duml.KeyValueKey][]byte, len(keys))
+	for len(values) < len(keys) {
+		msg, err := pushSub.Receive(pushCtx)
+		if err != nil && ctx.Err() == nil && req.Op == duml.KeyValueOpSet {
+			logger.Debugf(ctx, "the device has not pushed back all the values set: %v", err)
+			for _, item := range req.Items {
+				if _, ok := values[item.Key]; !ok {
+					values[item.Key] = item.Value
+				}
+			}
+			return values, nil
+		}
+		if err != nil {
+			return values, fmt.Errorf("unable to receive the values of the keys %v: %w", keys, err)
+		}
+		var push duml.KeyValuePush
+		if err := push.UnmarshalDUML(msg.Payload); err != nil {
+			logger.Warnf(ctx, "unable to parse the key-value push: %v", err)
+			continue
+		}
+		for _, key := range keys {
+			if v, ok := push.Get(key); ok {
+				values[key] = v
+			}
+		}
+	}
+	return values, nil
+}
+
+// WatchKeyValues calls the callback on each received key-value push containing
+// any of the given keys (or any push, if no keys are given), until the context is
+// cancelled or the callback returns an error.
+func (s *InterfaceAppToCamera) WatchKeyValues(
+	ctx context.Context,
+	callback func(*duml.KeyValuePush) error,
+	keys ...duml.KeyValueKey,
+) error {
+	pushSub := s.Conn().Subscribe(ctx, duml.FilterType(duml.MessageTypeKeyValuePush), duml.SubscribeOverflowPolicy(duml.OverflowPolicyDropOldest))
+	defer pushSub.Close()
+
+	for {
+		msg, err := pushSub.Receive(ctx)
+		if err != nil {
+			return err
+		}
+		var push duml.KeyValuePush
+		if err := push.UnmarshalDUML(msg.Payload); err != nil {
+			logger.Warnf(ctx, "unable to parse the key-value push: %v", err)
+			continue
+		}
+		matches := len(keys) == 0
+		for _, key := range keys {
+			if _, ok := push.Get(key); ok {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			continue
+		}
+		if err := callback(&push); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package djiapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// SetMode switches the camera mode and returns the mode reported by the device
+// afterwards. An error is returned without sending anything if the mode is not
+// supported by the device type.
+func (s *InterfaceAppToCamera) SetMode(
+	ctx context.Context,
+	mode duml.CameraMode,
+) (duml.CameraMode, error) {
+	b, err := s.Conn().DeviceType().EncodeCameraMode(mode)
+	if err != nil {
+		return duml.CameraModeUndefined, err
+	}
+	values, err := s.SetKeyValues(ctx, duml.KeyValueItem{Key: duml.KeyValueKeyCameraMode, Value: []byte{b}})
+	if err != nil {
+		return duml.CameraModeUndefined, fmt.Errorf("unable to set the camera mode to %s: %w", mode, err)
+	}
+	return s.decodeMode(values[duml.KeyValueKeyCameraMode])
+}
+
+// GetMode returns the current camera mode.
+func (s *InterfaceAppToCamera) GetMode(ctx context.Context) (duml.CameraMode, error) {
+	values, err := s.GetKeyValues(ctx, duml.KeyValueKeyCameraMode)
+	if err != nil {
+		return duml.CameraModeUndefined, fmt.Errorf("unable to get the camera mode: %w", err)
+	}
+	return s.decodeMode(values[duml.KeyValueKeyCameraMode])
+}
+
+func (s *InterfaceAppToCamera) decodeMode(v []byte) (duml.CameraMode, error) {
+	if len(v) != 1 {
+		return duml.CameraModeUndefined, fmt.Errorf("expected a 1-byte camera mode, got %X", v)
+	}
+	mode := s.Conn().DeviceType().DecodeCameraMode(v[0])
+	if mode == duml.CameraModeUndefined {
+		return mode, fmt.Errorf("unknown camera mode 0x%02X of %s", v[0], s.Conn().DeviceType())
+	}
+	return mode, nil
+}
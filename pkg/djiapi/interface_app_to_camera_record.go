@@ -0,0 +1,126 @@
+package djiapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// StartRecording starts recording and waits until the device reports it is recording;
+// it is experimental.
+func (s *InterfaceAppToCamera) StartRecording(ctx context.Context) (*duml.RecordingStatus, error) {
+	return s.takeRecordAndWait(ctx, duml.RecordActionStart, true)
+}
+
+// StopRecording stops recording and waits until the device reports it is not recording;
+// it is experimental.
+func (s *InterfaceAppToCamera) StopRecording(ctx context.Context) (*duml.RecordingStatus, error) {
+	return s.takeRecordAndWait(ctx, duml.RecordActionStop, false)
+}
+
+// TakePhoto takes a photo; only the response of the device is checked. It is experimental.
+func (s *InterfaceAppToCamera) TakePhoto(ctx context.Context) error {
+	return s.TakeRecord(ctx, duml.RecordActionTakePhoto)
+}
+
+func (s *InterfaceAppToCamera) takeRecordAndWait(
+	ctx context.Context,
+	action duml.RecordAction,
+	recording bool,
+) (_ret *duml.RecordingStatus, _err error) {
+	logger.Tracef(ctx, "takeRecordAndWait(ctx, %s)", action)
+	defer func() { logger.Tracef(ctx, "/takeRecordAndWait(ctx, %s): %v %v", action, _ret, _err) }()
+
+	statusSub := s.Conn().Subscribe(ctx, duml.FilterType(duml.MessageTypeRecordingStatus))
+	defer statusSub.Close()
+
+	if err := s.TakeRecord(ctx, action); err != nil {
+		return nil, err
+	}
+
+	for {
+		msg, err := statusSub.Receive(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to receive the recording status: %w", err)
+		}
+		var status duml.RecordingStatus
+		if err := status.UnmarshalDUML(msg.Payload); err != nil {
+			logger.Warnf(ctx, "unable to parse the recording status: %v", err)
+			continue
+		}
+		if status.Recording == recording {
+			return &status, nil
+		}
+		logger.Debugf(ctx, "waiting for 'recording: %t', received: %s", recording, status)
+	}
+}
+
+// TakeRecord sends the action and checks the result code of the response; it is experimental.
+func (s *InterfaceAppToCamera) TakeRecord(
+	ctx context.Context,
+	action duml.RecordAction,
+) error {
+	msg, err := s.RequestTakeRecord(ctx, action)
+	if err != nil {
+		return fmt.Errorf("unable to send the '%s' record action: %w", action, err)
+	}
+	var result duml.Result
+	if err := result.UnmarshalDUML(msg.Payload); err != nil {
+		return fmt.Errorf("unable to parse the result: %w", err)
+	}
+	if !result.IsSuccess() {
+		return duml.NewRejectedError(msg, "unable to %s", action)
+	}
+	return nil
+}
+
+// RequestTakeRecord sends the action; ErrExperimental is returned unless
+// allowed by WithExperimental.
+func (s *InterfaceAppToCamera) RequestTakeRecord(
+	ctx context.Context,
+	action duml.RecordAction,
+) (*duml.Message, error) {
+	msg := s.GetMessageTakeRecord(action)
+	if !s.experimental {
+		return nil, fmt.Errorf("unable to send %s: %w", msg.Type, ErrExperimental)
+	}
+	return s.Conn().Request(ctx, msg)
+}
+
+func (s *InterfaceAppToCamera) GetMessageTakeRecord(
+	action duml.RecordAction,
+) *duml.Message {
+	return &duml.Message{
+		Interface: s.InterfaceID(),
+		AutoID:    true,
+		Type:      duml.MessageTypeTakeRecord,
+		Payload:   must((&duml.TakeRecordRequest{Action: action}).MarshalDUML()),
+	}
+}
+
+// WatchRecordingStatus calls the callback on each received recording status push,
+// until the context is cancelled or the callback returns an error.
+func (s *InterfaceAppToCamera) WatchRecordingStatus(
+	ctx context.Context,
+	callback func(*duml.RecordingStatus) error,
+) error {
+	statusSub := s.Conn().Subscribe(ctx, duml.FilterType(duml.MessageTypeRecordingStatus), duml.SubscribeOverflowPolicy(duml.OverflowPolicyDropOldest))
+	defer statusSub.Close()
+
+	for {
+		msg, err := statusSub.Receive(ctx)
+		if err != nil {
+			return err
+		}
+		var status duml.RecordingStatus
+		if err := status.UnmarshalDUML(msg.Payload); err != nil {
+			logger.Warnf(ctx, "unable to parse the recording status: %v", err)
+			continue
+		}
+		if err := callback(&status); err != nil {
+			return err
+		}
+	}
+}
@@ -1,7 +1,6 @@
-package djible
+package djiapi
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 
@@ -26,7 +25,7 @@ func (s *InterfaceAppToCamera) RequestSetImageStabilization(
 	v duml.ImageStabilization,
 ) (*duml.Message, error) {
 	msg := s.GetMessageSetImageStabilization(v)
-	return s.Device().Request(ctx, msg, true)
+	return s.Conn().Request(ctx, msg)
 }
 
 func (s *InterfaceAppToCamera) GetMessageSetImageStabilization(
@@ -34,7 +33,7 @@ func (s *InterfaceAppToCamera) GetMessageSetImageStabilization(
 ) *duml.Message {
 	return &duml.Message{
 		Interface: s.InterfaceID(),
-		ID:        0, //duml.MessageIDSetImageStabilization,
+		AutoID:    true,
 		Type:      duml.MessageTypeStartStopStreaming,
 		Payload: s.GetMessagePayloadSetImageStabilization(
 			v,
@@ -45,10 +44,8 @@ func (s *InterfaceAppToCamera) GetMessageSetImageStabilization(
 func (s *InterfaceAppToCamera) GetMessagePayloadSetImageStabilization(
 	v duml.ImageStabilization,
 ) []byte {
-	var buf bytes.Buffer
-	must(buf.Write([]byte{0x01, 0x01}))
-	must(buf.Write(array1ToSlice(s.Device().Type.BytesFixedSetImageStabilization())))
-	must(buf.Write([]byte{0x00, 0x01}))
-	must(buf.Write(array1ToSlice(v.BytesFixed())))
-	return buf.Bytes()
+	return must(duml.NewKeyValueSetRequest(
+		duml.KeyValueKey(s.Conn().DeviceType().BytesFixedSetImageStabilization()[0]),
+		v.BytesFixed()[0],
+	).MarshalDUML())
 }
@@ -0,0 +1,44 @@
+package djiapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// SetVideoFormat sets the format of the local recording and returns the format
+// reported by the device afterwards (which is not necessarily the requested one,
+// e.g. if the combination is not supported). An error is returned without sending
+// anything if any of the values is not supported by the device type.
+func (s *InterfaceAppToCamera) SetVideoFormat(
+	ctx context.Context,
+	format duml.VideoFormat,
+) (*duml.VideoFormat, error) {
+	b, err := s.Conn().DeviceType().EncodeVideoFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	values, err := s.SetKeyValues(ctx, duml.KeyValueItem{Key: duml.KeyValueKeyVideoFormat, Value: b})
+	if err != nil {
+		return nil, fmt.Errorf("unable to set the video format to %s: %w", format, err)
+	}
+	return s.decodeVideoFormat(values[duml.KeyValueKeyVideoFormat])
+}
+
+// GetVideoFormat returns the current format of the local recording.
+func (s *InterfaceAppToCamera) GetVideoFormat(ctx context.Context) (*duml.VideoFormat, error) {
+	values, err := s.GetKeyValues(ctx, duml.KeyValueKeyVideoFormat)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the video format: %w", err)
+	}
+	return s.decodeVideoFormat(values[duml.KeyValueKeyVideoFormat])
+}
+
+func (s *InterfaceAppToCamera) decodeVideoFormat(v []byte) (*duml.VideoFormat, error) {
+	format, err := s.Conn().DeviceType().DecodeVideoFormat(v)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the video format: %w", err)
+	}
+	return &format, nil
+}
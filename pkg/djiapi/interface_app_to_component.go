@@ -0,0 +1,95 @@
+package djiapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// InterfaceAppToComponent is the interface to an arbitrary component; it
+// provides the commands supported by (presumably) all the components.
+type InterfaceAppToComponent struct {
+	conn      duml.Conn
+	component duml.ComponentID
+}
+
+func AppToComponent(conn duml.Conn, component duml.ComponentID) *InterfaceAppToComponent {
+	return &InterfaceAppToComponent{conn: conn, component: component}
+}
+
+func (s *InterfaceAppToComponent) InterfaceID() duml.InterfaceID {
+	return duml.InterfaceID{Sender: duml.ComponentIDApp, Receiver: s.component}
+}
+
+func (s *InterfaceAppToComponent) Conn() duml.Conn {
+	return s.conn
+}
+
+// GetVersion returns the hardware, loader and firmware versions of the component.
+func (s *InterfaceAppToComponent) GetVersion(
+	ctx context.Context,
+	opts ...duml.RequestOption,
+) (*duml.VersionInfo, error) {
+	var info duml.VersionInfo
+	resp, err := s.requestResult(ctx, duml.MessageTypeGetVersion, &info, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if info.Result != duml.ResultCodeSuccess {
+		return nil, duml.NewRejectedError(resp, "unable to get the version of %s", s.component)
+	}
+	return &info, nil
+}
+
+// GetSerialNumber returns the serial number of the component.
+func (s *InterfaceAppToComponent) GetSerialNumber(
+	ctx context.Context,
+	opts ...duml.RequestOption,
+) (string, error) {
+	return s.requestString(ctx, duml.MessageTypeGetSerialNum, opts...)
+}
+
+// GetProductID returns the product ID of the component.
+func (s *InterfaceAppToComponent) GetProductID(
+	ctx context.Context,
+	opts ...duml.RequestOption,
+) (string, error) {
+	return s.requestString(ctx, duml.MessageTypeGetProductID, opts...)
+}
+
+func (s *InterfaceAppToComponent) requestString(
+	ctx context.Context,
+	msgType duml.MessageType,
+	opts ...duml.RequestOption,
+) (string, error) {
+	var result duml.StringResult
+	resp, err := s.requestResult(ctx, msgType, &result, opts...)
+	if err != nil {
+		return "", err
+	}
+	if result.Result != duml.ResultCodeSuccess {
+		return "", duml.NewRejectedError(resp, "unable to request %s of %s", msgType, s.component)
+	}
+	return result.Value, nil
+}
+
+func (s *InterfaceAppToComponent) requestResult(
+	ctx context.Context,
+	msgType duml.MessageType,
+	result duml.Payload,
+	opts ...duml.RequestOption,
+) (*duml.Message, error) {
+	resp, err := s.Conn().Request(ctx, &duml.Message{
+		Interface: s.InterfaceID(),
+		Type:      msgType,
+		AutoID:    true,
+	}, append([]duml.RequestOption{duml.RequestRetries(duml.DefaultQueryRetries)}, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to send %s message: %w", msgType, err)
+	}
+	if err := result.UnmarshalDUML(resp.Payload); err != nil {
+		return nil, fmt.Errorf("unable to parse the response to %s: %w", msgType, err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,167 @@
+package djiapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// InterfaceAppToGimbal reads the gimbal status pushed by the device and controls
+// the gimbal; the control commands are experimental (see ErrExperimental).
+type InterfaceAppToGimbal struct {
+	conn         duml.Conn
+	experimental bool
+}
+
+func AppToGimbal(conn duml.Conn) *InterfaceAppToGimbal {
+	return &InterfaceAppToGimbal{conn: conn}
+}
+
+// WithExperimental returns the interface allowing the experimental commands (see ErrExperimental).
+func (s *InterfaceAppToGimbal) WithExperimental() *InterfaceAppToGimbal {
+	return &InterfaceAppToGimbal{conn: s.conn, experimental: true}
+}
+
+func (s *InterfaceAppToGimbal) InterfaceID() duml.InterfaceID {
+	return duml.InterfaceIDAppToGimbal
+}
+
+func (s *InterfaceAppToGimbal) Conn() duml.Conn {
+	return s.conn
+}
+
+// GetStatus waits for the next gimbal status pushed by the device
+// (the device pushes it periodically, there is no known request for it).
+func (s *InterfaceAppToGimbal) GetStatus(ctx context.Context) (*duml.GimbalStatus, error) {
+	statusSub := s.Conn().Subscribe(ctx, duml.FilterType(duml.MessageTypeUnknown0MaybeStatus))
+	defer statusSub.Close()
+
+	for {
+		msg, err := statusSub.Receive(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to receive the gimbal status: %w", err)
+		}
+		var status duml.GimbalStatus
+		if err := status.UnmarshalDUML(msg.Payload); err != nil {
+			logger.Warnf(ctx, "unable to parse the gimbal status: %v", err)
+			continue
+		}
+		return &status, nil
+	}
+}
+
+// WatchStatus calls the callback on each received gimbal status push, until
+// the context is cancelled or the callback returns an error.
+func (s *InterfaceAppToGimbal) WatchStatus(
+	ctx context.Context,
+	callback func(*duml.GimbalStatus) error,
+) error {
+	statusSub := s.Conn().Subscribe(ctx, duml.FilterType(duml.MessageTypeUnknown0MaybeStatus), duml.SubscribeOverflowPolicy(duml.OverflowPolicyDropOldest))
+	defer statusSub.Close()
+
+	for {
+		msg, err := statusSub.Receive(ctx)
+		if err != nil {
+			return err
+		}
+		var status duml.GimbalStatus
+		if err := status.UnmarshalDUML(msg.Payload); err != nil {
+			logger.Warnf(ctx, "unable to parse the gimbal status: %v", err)
+			continue
+		}
+		if err := callback(&status); err != nil {
+			return err
+		}
+	}
+}
+
+// SetMode switches the gimbal mode; it is experimental.
+func (s *InterfaceAppToGimbal) SetMode(ctx context.Context, mode duml.GimbalMode) error {
+	return s.setMode(ctx, &duml.GimbalSetModeRequest{Mode: mode})
+}
+
+// Recenter returns the gimbal to the center, keeping the mode; it is experimental.
+func (s *InterfaceAppToGimbal) Recenter(ctx context.Context) error {
+	return s.setMode(ctx, &duml.GimbalSetModeRequest{Mode: duml.GimbalModeKeep, Recenter: true})
+}
+
+func (s *InterfaceAppToGimbal) setMode(ctx context.Context, req *duml.GimbalSetModeRequest) error {
+	msg, err := s.request(ctx, duml.MessageTypeGimbalSetMode, req)
+	if err != nil {
+		return err
+	}
+	return checkResult(msg, "unable to set the gimbal mode %s (recenter: %t)", req.Mode, req.Recenter)
+}
+
+// MoveTo turns the gimbal to the given attitude within the given duration; it is experimental.
+func (s *InterfaceAppToGimbal) MoveTo(ctx context.Context, attitude duml.GimbalAttitude, duration time.Duration) error {
+	return s.Move(ctx, &duml.GimbalMoveRequest{
+		Yaw:      attitude.Yaw,
+		Roll:     attitude.Roll,
+		Pitch:    attitude.Pitch,
+		Duration: duration,
+	})
+}
+
+// MoveBy turns the gimbal by the given angles within the given duration; it is experimental.
+func (s *InterfaceAppToGimbal) MoveBy(ctx context.Context, delta duml.GimbalAttitude, duration time.Duration) error {
+	return s.Move(ctx, &duml.GimbalMoveRequest{
+		Yaw:      delta.Yaw,
+		Roll:     delta.Roll,
+		Pitch:    delta.Pitch,
+		Flags:    duml.GimbalMoveFlagRelative,
+		Duration: duration,
+	})
+}
+
+// Move sends the movement request; it is experimental. An error is returned
+// without sending anything if the request is invalid.
+func (s *InterfaceAppToGimbal) Move(ctx context.Context, req *duml.GimbalMoveRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	msg, err := s.request(ctx, duml.MessageTypeGimbalMove, req)
+	if err != nil {
+		return err
+	}
+	return checkResult(msg, "unable to move the gimbal")
+}
+
+func (s *InterfaceAppToGimbal) request(
+	ctx context.Context,
+	t duml.MessageType,
+	payload duml.Payload,
+) (*duml.Message, error) {
+	if !s.experimental {
+		return nil, fmt.Errorf("unable to send %s: %w", t, ErrExperimental)
+	}
+	b, err := payload.MarshalDUML()
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal the request: %w", err)
+	}
+	msg, err := s.Conn().Request(ctx, &duml.Message{
+		Interface: s.InterfaceID(),
+		AutoID:    true,
+		Type:      t,
+		Payload:   b,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to send the duml.Message: %w", err)
+	}
+	return msg, nil
+}
+
+// checkResult returns a duml.RejectedError if the response is not a successful duml.Result.
+func checkResult(msg *duml.Message, format string, args ...any) error {
+	var result duml.Result
+	if err := result.UnmarshalDUML(msg.Payload); err != nil {
+		return fmt.Errorf("unable to parse the result: %w", err)
+	}
+	if !result.IsSuccess() {
+		return duml.NewRejectedError(msg, format, args...)
+	}
+	return nil
+}
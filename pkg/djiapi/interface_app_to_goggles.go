@@ -1,4 +1,4 @@
-package djible
+package djiapi
 
 import (
 	"context"
@@ -6,22 +6,24 @@ import (
 	"github.com/xaionaro-go/djictl/pkg/duml"
 )
 
-type InterfaceAppToGoggles Device
+type InterfaceAppToGoggles struct {
+	conn duml.Conn
+}
 
-func (d *Device) AppToGoggles() *InterfaceAppToGoggles {
-	return (*InterfaceAppToGoggles)(d)
+func AppToGoggles(conn duml.Conn) *InterfaceAppToGoggles {
+	return &InterfaceAppToGoggles{conn: conn}
 }
 
 func (s *InterfaceAppToGoggles) InterfaceID() duml.InterfaceID {
 	return duml.InterfaceIDAppToGoggles
 }
 
-func (s *InterfaceAppToGoggles) Device() *Device {
-	return (*Device)(s)
+func (s *InterfaceAppToGoggles) Conn() duml.Conn {
+	return s.conn
 }
 
 func (s *InterfaceAppToGoggles) SetMode(ctx context.Context, mode duml.GogglesMode) (*duml.Message, error) {
 	msg := duml.NewGogglesModeMessage(mode)
 	msg.Interface = s.InterfaceID()
-	return s.Device().Request(ctx, msg, true)
+	return s.Conn().Request(ctx, msg)
 }
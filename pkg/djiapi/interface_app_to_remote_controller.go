@@ -1,4 +1,4 @@
-package djible
+package djiapi
 
 import (
 	"context"
@@ -6,22 +6,24 @@ import (
 	"github.com/xaionaro-go/djictl/pkg/duml"
 )
 
-type InterfaceAppToRemoteController Device
+type InterfaceAppToRemoteController struct {
+	conn duml.Conn
+}
 
-func (d *Device) AppToRemoteController() *InterfaceAppToRemoteController {
-	return (*InterfaceAppToRemoteController)(d)
+func AppToRemoteController(conn duml.Conn) *InterfaceAppToRemoteController {
+	return &InterfaceAppToRemoteController{conn: conn}
 }
 
 func (s *InterfaceAppToRemoteController) InterfaceID() duml.InterfaceID {
 	return duml.InterfaceIDAppToRemoteController
 }
 
-func (s *InterfaceAppToRemoteController) Device() *Device {
-	return (*Device)(s)
+func (s *InterfaceAppToRemoteController) Conn() duml.Conn {
+	return s.conn
 }
 
 func (s *InterfaceAppToRemoteController) SendData(ctx context.Context, data duml.RemoteControllerSimulatorData) error {
 	msg := duml.NewRemoteControllerSimulatorMessage(data)
 	msg.Interface = s.InterfaceID()
-	return s.Device().SendMessage(ctx, msg, true)
+	return s.Conn().SendMessage(ctx, msg)
 }
@@ -0,0 +1,21 @@
+package djiapi
+
+import (
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+type InterfaceAppToVideoTransmission struct {
+	conn duml.Conn
+}
+
+func AppToVideoTransmission(conn duml.Conn) *InterfaceAppToVideoTransmission {
+	return &InterfaceAppToVideoTransmission{conn: conn}
+}
+
+func (s *InterfaceAppToVideoTransmission) Conn() duml.Conn {
+	return s.conn
+}
+
+func (s *InterfaceAppToVideoTransmission) InterfaceID() duml.InterfaceID {
+	return duml.InterfaceIDAppToVideoTransmission
+}
@@ -1,4 +1,4 @@
-package djible
+package djiapi
 
 import (
 	"bytes"
@@ -21,11 +21,12 @@ func (s *InterfaceAppToVideoTransmission) PrepareToLiveStream(
 	}
 
 	logger.Debugf(ctx, "received a duml.MessageTypePrepareToLiveStreamResult: %s", msg)
-	if len(msg.Payload) != 1 {
-		return fmt.Errorf("invalid payload size: %d", len(msg.Payload))
+	var result duml.Result
+	if err := result.UnmarshalDUML(msg.Payload); err != nil {
+		return fmt.Errorf("unable to parse the result: %w", err)
 	}
-	if msg.Payload[0] != 0x00 {
-		return fmt.Errorf("expected the payload to be 0x00, but received 0x%X", msg.Payload)
+	if !result.IsSuccess() || len(result.Data) != 0 {
+		return duml.NewRejectedError(msg, "expected the payload to be 0x00")
 	}
 
 	msg, err = s.RequestPrepareToLiveStreamStage2(ctx)
@@ -42,7 +43,7 @@ func (s *InterfaceAppToVideoTransmission) RequestPrepareToLiveStreamStage1(
 	ctx context.Context,
 ) (*duml.Message, error) {
 	msg := s.GetMessagePrepareToLiveStreamStage1()
-	return s.Device().Request(ctx, msg, true)
+	return s.Conn().Request(ctx, msg)
 }
 
 func (s *InterfaceAppToVideoTransmission) GetMessagePrepareToLiveStreamStage1() *duml.Message {
@@ -64,7 +65,7 @@ func (s *InterfaceAppToVideoTransmission) RequestPrepareToLiveStreamStage2(
 	ctx context.Context,
 ) (*duml.Message, error) {
 	msg := s.GetMessagePrepareToLiveStreamStage2()
-	return s.Device().Request(ctx, msg, true)
+	return s.Conn().Request(ctx, msg)
 }
 
 func (s *InterfaceAppToVideoTransmission) GetMessagePrepareToLiveStreamStage2() *duml.Message {
@@ -77,7 +78,5 @@ func (s *InterfaceAppToVideoTransmission) GetMessagePrepareToLiveStreamStage2()
 }
 
 func (s *InterfaceAppToVideoTransmission) GetMessagePayloadPrepareToLiveStreamStage2() []byte {
-	var buf bytes.Buffer
-	must(buf.Write([]byte{0x00, 0x01, 0x1C, 0x00}))
-	return buf.Bytes()
+	return must(duml.NewKeyValueGetRequest(duml.KeyValueKeyPrepareToLiveStream).MarshalDUML())
 }
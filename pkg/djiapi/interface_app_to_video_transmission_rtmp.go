@@ -1,4 +1,4 @@
-package djible
+package djiapi
 
 import (
 	"context"
@@ -8,5 +8,5 @@ import (
 
 func (s *InterfaceAppToVideoTransmission) ConfigureRTMP(ctx context.Context, url string, enable bool) (*duml.Message, error) {
 	msg := duml.NewBroadcastMessage(enable, url)
-	return s.Device().Request(ctx, msg, true)
+	return s.Conn().Request(ctx, msg)
 }
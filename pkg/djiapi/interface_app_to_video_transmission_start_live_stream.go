@@ -1,9 +1,7 @@
-package djible
+package djiapi
 
 import (
-	"bytes"
 	"context"
-	"encoding/binary"
 	"fmt"
 
 	"github.com/facebookincubator/go-belt/tool/logger"
@@ -22,6 +20,9 @@ func (s *InterfaceAppToVideoTransmission) LiveStream(
 		logger.Tracef(ctx, "/LiveStream(ctx, %v, %v, %v, %v): %v", resolution, bitrateKbps, fps, rtmpURL, _err)
 	}()
 
+	statusSub := s.Conn().Subscribe(ctx, duml.FilterType(duml.MessageTypeBatteryStatus))
+	defer statusSub.Close()
+
 	_, err := s.RequestConfigureLiveStream(ctx, resolution, bitrateKbps, fps, rtmpURL)
 	if err != nil {
 		return fmt.Errorf("unable to send the message to configure the live stream: %w", err)
@@ -33,7 +34,7 @@ func (s *InterfaceAppToVideoTransmission) LiveStream(
 	}
 
 	for {
-		msg, err := s.ReceiveMessageLiveStreamResult(ctx)
+		msg, err := statusSub.Receive(ctx)
 		if err != nil {
 			return fmt.Errorf("unable to receive a response: %w", err)
 		}
@@ -58,7 +59,7 @@ func (s *InterfaceAppToVideoTransmission) RequestConfigureLiveStream(
 	msg := s.GetMessageConfigureLiveStream(
 		resolution, bitrateKbps, fps, rtmpURL,
 	)
-	return s.Device().Request(ctx, msg, true)
+	return s.Conn().Request(ctx, msg)
 }
 
 func (s *InterfaceAppToVideoTransmission) GetMessageConfigureLiveStream(
@@ -89,36 +90,32 @@ func (s *InterfaceAppToVideoTransmission) GetMessagePayloadConfigureLiveStream(
 	// = packet example =
 	// hdr: 55 42 04 b0 0208 b3bb 400878
 	// payload: 00 32 00 0a 7017 0200 03 000000 270072746d703a2f2f3139322e3136382e302e3133313a313934362f746573742f73747265616d302f995c
-	var buf bytes.Buffer
-	must(buf.Write([]byte{0x00}))
-	must(buf.Write(array1ToSlice(s.Type.BytesFixedStartStreaming())))
-	must(buf.Write([]byte{0x00}))
-	must(buf.Write(array1ToSlice(resolution.BytesFixed())))
-	cannotFail(binary.Write(&buf, duml.BinaryOrder(), bitrateKbps))
-	must(buf.Write([]byte{0x02, 0x00}))
-	must(buf.Write(array1ToSlice(fps.BytesFixed())))
-	must(buf.Write([]byte{0x00, 0x00, 0x00}))
-	must(buf.Write(duml.PackURL(rtmpURL)))
-	return buf.Bytes()
+	return must((&duml.LiveStreamConfig{
+		DeviceSpecificByte: s.Conn().DeviceType().BytesFixedStartStreaming()[0],
+		Resolution:         resolution,
+		BitrateKbps:        bitrateKbps,
+		FPS:                fps,
+		URL:                rtmpURL,
+	}).MarshalDUML())
 }
 
 func (s *InterfaceAppToVideoTransmission) ReceiveMessageStartLiveStreamResult(
 	ctx context.Context,
 ) (*duml.Message, error) {
-	return s.Device().ReceiveMessage(ctx, duml.MessageTypeStartStopStreamingResult)
+	return duml.ReceiveMessage(ctx, s.Conn(), duml.MessageTypeStartStopStreamingResult)
 }
 
 func (s *InterfaceAppToVideoTransmission) ReceiveMessageLiveStreamResult(
 	ctx context.Context,
 ) (*duml.Message, error) {
-	return s.Device().ReceiveMessage(ctx, duml.MessageTypeBatteryStatus)
+	return duml.ReceiveMessage(ctx, s.Conn(), duml.MessageTypeBatteryStatus)
 }
 
 func (s *InterfaceAppToVideoTransmission) RequestStartLiveStream(
 	ctx context.Context,
 ) (*duml.Message, error) {
 	msg := s.GetMessageStartLiveStream()
-	return s.Device().Request(ctx, msg, true)
+	return s.Conn().Request(ctx, msg)
 }
 
 func (s *InterfaceAppToVideoTransmission) GetMessageStartLiveStream() *duml.Message {
@@ -131,7 +128,5 @@ func (s *InterfaceAppToVideoTransmission) GetMessageStartLiveStream() *duml.Mess
 }
 
 func (s *InterfaceAppToVideoTransmission) GetMessagePayloadStartLiveStream() []byte {
-	var buf bytes.Buffer
-	must(buf.Write([]byte{0x01, 0x01, 0x1A, 0x00, 0x01, 0x01}))
-	return buf.Bytes()
+	return must(duml.NewKeyValueSetRequest(duml.KeyValueKeyLiveStream, 0x01).MarshalDUML())
 }
@@ -1,7 +1,6 @@
-package djible
+package djiapi
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 
@@ -22,7 +21,7 @@ func (s *InterfaceAppToVideoTransmission) RequestStopLiveStream(
 	ctx context.Context,
 ) (*duml.Message, error) {
 	msg := s.GetMessageStopLiveStream()
-	return s.Device().Request(ctx, msg, true)
+	return s.Conn().Request(ctx, msg)
 }
 
 func (s *InterfaceAppToVideoTransmission) GetMessageStopLiveStream() *duml.Message {
@@ -35,7 +34,5 @@ func (s *InterfaceAppToVideoTransmission) GetMessageStopLiveStream() *duml.Messa
 }
 
 func (s *InterfaceAppToVideoTransmission) GetMessagePayloadStopLiveStream() []byte {
-	var buf bytes.Buffer
-	must(buf.Write([]byte{0x01, 0x01, 0x1A, 0x00, 0x01, 0x02}))
-	return buf.Bytes()
+	return must(duml.NewKeyValueSetRequest(duml.KeyValueKeyLiveStream, 0x02).MarshalDUML())
 }
@@ -0,0 +1,19 @@
+package djiapi
+
+import "github.com/xaionaro-go/djictl/pkg/duml"
+
+type InterfaceAppToWiFiGroundStation struct {
+	conn duml.Conn
+}
+
+func AppToWiFiGroundStation(conn duml.Conn) *InterfaceAppToWiFiGroundStation {
+	return &InterfaceAppToWiFiGroundStation{conn: conn}
+}
+
+func (s *InterfaceAppToWiFiGroundStation) InterfaceID() duml.InterfaceID {
+	return duml.InterfaceIDAppToWiFiGroundStation
+}
+
+func (s *InterfaceAppToWiFiGroundStation) Conn() duml.Conn {
+	return s.conn
+}
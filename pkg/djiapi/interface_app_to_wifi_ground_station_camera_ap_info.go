@@ -1,4 +1,4 @@
-package djible
+package djiapi
 
 import (
 	"context"
@@ -14,30 +14,38 @@ func (s *InterfaceAppToWiFiGroundStation) CameraAPInfo(
 	logger.Tracef(ctx, "CameraAPInfo")
 	defer func() { logger.Tracef(ctx, "/CameraAPInfo") }()
 
-	_, err := s.Device().Request(ctx, s.GetMessageCameraAPInfo(), true)
+	resultSub := s.Conn().Subscribe(ctx, duml.FilterTypes(
+		duml.MessageTypeCameraAPInfoResultSSID,
+		duml.MessageTypeCameraAPInfoResultPSK,
+	))
+	defer resultSub.Close()
+
+	_, err := s.Conn().Request(ctx, s.GetMessageCameraAPInfo())
 	if err != nil {
 		return "", "", fmt.Errorf("unable to send the duml.Message: %w", err)
 	}
 
 	var ssid, psk string
 	for ssid == "" || psk == "" {
-		select {
-		case <-ctx.Done():
-			return "", "", ctx.Err()
-		case msg := <-s.Device().getReceiveMessageChan(ctx, duml.MessageTypeCameraAPInfoResultSSID):
+		msg, err := resultSub.Receive(ctx)
+		if err != nil {
+			return "", "", err
+		}
+		switch msg.Type.CmdID {
+		case duml.MessageTypeCameraAPInfoResultSSID.CmdID:
 			logger.Debugf(ctx, "received SSID: %X", msg.Payload)
-			var err error
-			ssid, err = duml.UnpackStringU16BE(msg.Payload)
-			if err != nil {
+			var result duml.CameraAPInfoResult
+			if err := result.UnmarshalDUML(msg.Payload); err != nil {
 				return "", "", fmt.Errorf("unable to unpack SSID: %w", err)
 			}
-		case msg := <-s.Device().getReceiveMessageChan(ctx, duml.MessageTypeCameraAPInfoResultPSK):
+			ssid = result.Value
+		case duml.MessageTypeCameraAPInfoResultPSK.CmdID:
 			logger.Debugf(ctx, "received PSK: %X", msg.Payload)
-			var err error
-			psk, err = duml.UnpackStringU16BE(msg.Payload)
-			if err != nil {
+			var result duml.CameraAPInfoResult
+			if err := result.UnmarshalDUML(msg.Payload); err != nil {
 				return "", "", fmt.Errorf("unable to unpack PSK: %w", err)
 			}
+			psk = result.Value
 		}
 	}
 
@@ -1,9 +1,10 @@
-package djible
+package djiapi
 
 import (
 	"bytes"
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/facebookincubator/go-belt/tool/logger"
 	"github.com/xaionaro-go/djictl/pkg/duml"
@@ -11,6 +12,10 @@ import (
 
 const (
 	wifiWaitForScanReport = false
+
+	// connecting to a WiFi network takes a while, and re-sending
+	// the request would restart the connection
+	wifiConnectTimeout = 30 * time.Second
 )
 
 func (s *InterfaceAppToWiFiGroundStation) ConnectToWiFi(
@@ -22,18 +27,20 @@ func (s *InterfaceAppToWiFiGroundStation) ConnectToWiFi(
 	defer func() { logger.Tracef(ctx, "/ConnectToWiFi: %v", _err) }()
 
 	if wifiWaitForScanReport {
+		scanSub := s.Conn().Subscribe(ctx, duml.FilterType(duml.MessageTypeWiFiScanReport))
+		defer scanSub.Close()
+
 		_, err := s.RequestStartScanningWiFi(ctx)
 		if err != nil {
 			return fmt.Errorf("unable to send the duml.Message: %w", err)
 		}
 
 		logger.Debugf(ctx, "waiting WiFi scan results")
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case msg := <-s.Device().getReceiveMessageChan(ctx, duml.MessageTypeWiFiScanReport):
-			logger.Debugf(ctx, "received a wifi scan result: %#+v", msg)
+		msg, err := scanSub.Receive(ctx)
+		if err != nil {
+			return err
 		}
+		logger.Debugf(ctx, "received a wifi scan result: %#+v", msg)
 	}
 
 	msg, err := s.RequestConnectToWiFi(ctx, ssid, psk)
@@ -42,8 +49,12 @@ func (s *InterfaceAppToWiFiGroundStation) ConnectToWiFi(
 	}
 
 	logger.Debugf(ctx, "received a report about connecting to WiFi: %#+v", msg)
-	if !bytes.Equal(msg.Payload, []byte{0, 0}) {
-		return fmt.Errorf("unable to connect to WiFi, payload should be 0000, but received %X", msg.Payload)
+	var result duml.Result
+	if err := result.UnmarshalDUML(msg.Payload); err != nil {
+		return fmt.Errorf("unable to parse the result: %w", err)
+	}
+	if !result.IsSuccess() || !bytes.Equal(result.Data, []byte{0}) {
+		return duml.NewRejectedError(msg, "unable to connect to WiFi, payload should be 0000")
 	}
 
 	return nil
@@ -57,7 +68,7 @@ func (s *InterfaceAppToWiFiGroundStation) RequestConnectToWiFi(
 	logger.Tracef(ctx, "RequestConnectToWiFi")
 	defer func() { logger.Tracef(ctx, "/RequestConnectToWiFi: %v", _err) }()
 	msg := s.GetMessageConnectToWiFi(ssid, psk)
-	return s.Device().Request(ctx, msg, true)
+	return s.Conn().Request(ctx, msg, duml.RequestTimeout(wifiConnectTimeout), duml.RequestRetries(0))
 }
 
 func (s *InterfaceAppToWiFiGroundStation) GetMessageConnectToWiFi(
@@ -79,10 +90,10 @@ func (s *InterfaceAppToWiFiGroundStation) GetMessagePayloadConnectToWiFi(
 	ssid string,
 	psk string,
 ) []byte {
-	var buf bytes.Buffer
-	must(buf.Write(duml.PackString(ssid)))
-	must(buf.Write(duml.PackString(psk)))
-	return buf.Bytes()
+	return must((&duml.ConnectToWiFiRequest{
+		SSID: ssid,
+		PSK:  psk,
+	}).MarshalDUML())
 }
 
 func (s *InterfaceAppToWiFiGroundStation) RequestStartScanningWiFi(
@@ -91,7 +102,7 @@ func (s *InterfaceAppToWiFiGroundStation) RequestStartScanningWiFi(
 	logger.Tracef(ctx, "RequestStartScanningWiFi")
 	defer func() { logger.Tracef(ctx, "/RequestStartScanningWiFi: %v", _err) }()
 	msg := s.GetMessageStartScanningWiFi()
-	return s.Device().Request(ctx, msg, true)
+	return s.Conn().Request(ctx, msg)
 }
 
 func (s *InterfaceAppToWiFiGroundStation) GetMessageStartScanningWiFi() *duml.Message {
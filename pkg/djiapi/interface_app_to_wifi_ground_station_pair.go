@@ -1,7 +1,6 @@
-package djible
+package djiapi
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 
@@ -11,13 +10,23 @@ import (
 
 const (
 	defaultPINCode = "5160"
+	defaultAppID   = "001749319286102"
 )
 
+// PairingRequester is implemented by the transports that require
+// an out-of-band request to start pairing (e.g. BLE).
+type PairingRequester interface {
+	SendPairingRequest(ctx context.Context) error
+}
+
 func (s *InterfaceAppToWiFiGroundStation) Pair(
 	ctx context.Context,
 ) (_err error) {
 	logger.Tracef(ctx, "Pair")
 	defer func() { logger.Tracef(ctx, "/Pair: %v", _err) }()
+	approveSub := s.Conn().Subscribe(ctx, duml.FilterType(duml.MessageTypePairingPINApproved))
+	defer approveSub.Close()
+
 	err := s.SendRequestStartPairing(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to send the request to start pairing: %w", err)
@@ -28,22 +37,22 @@ func (s *InterfaceAppToWiFiGroundStation) Pair(
 	}
 
 	logger.Debugf(ctx, "received the pairing info: %#+v", msg)
-	if len(msg.Payload) < 2 {
-		logger.Errorf(ctx, "the payload size of the pairing status is too small: %d", len(msg.Payload))
+	var status duml.PairingStatus
+	if err := status.UnmarshalDUML(msg.Payload); err != nil {
+		logger.Errorf(ctx, "unable to parse the pairing status: %v", err)
 	} else {
-		if msg.Payload[1] == 0x01 {
+		if status.State == duml.PairingStateAlreadyPaired {
 			logger.Debugf(ctx, "is already paired")
 			return nil
 		}
 	}
 
 	logger.Debugf(ctx, "waiting for PIN approve")
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case msg := <-s.Device().getReceiveMessageChan(ctx, duml.MessageTypePairingPINApproved):
-		logger.Debugf(ctx, "PIN was approved: %#+v", msg)
+	msg, err = approveSub.Receive(ctx)
+	if err != nil {
+		return err
 	}
+	logger.Debugf(ctx, "PIN was approved: %#+v", msg)
 
 	_, err = s.RequestPairingStage1(ctx)
 	if err != nil {
@@ -61,7 +70,12 @@ func (s *InterfaceAppToWiFiGroundStation) SendRequestStartPairing(
 ) (_err error) {
 	logger.Tracef(ctx, "SendRequestStartPairing")
 	defer func() { logger.Tracef(ctx, "/SendRequestStartPairing: %v", _err) }()
-	return s.Device().SendPairingRequest(ctx)
+	requester, ok := s.Conn().(PairingRequester)
+	if !ok {
+		logger.Debugf(ctx, "the transport %T does not need a pairing request", s.Conn())
+		return nil
+	}
+	return requester.SendPairingRequest(ctx)
 }
 
 func (s *InterfaceAppToWiFiGroundStation) RequestSetPairingPIN(
@@ -71,7 +85,7 @@ func (s *InterfaceAppToWiFiGroundStation) RequestSetPairingPIN(
 	logger.Tracef(ctx, "RequestSetPairingPIN")
 	defer func() { logger.Tracef(ctx, "/RequestSetPairingPIN: %v", _err) }()
 	msg := s.GetMessageSetPairingPIN(pinCode)
-	return s.Device().Request(ctx, msg, true)
+	return s.Conn().Request(ctx, msg)
 }
 
 func (s *InterfaceAppToWiFiGroundStation) GetMessageSetPairingPIN(
@@ -90,16 +104,16 @@ func (s *InterfaceAppToWiFiGroundStation) GetMessageSetPairingPIN(
 func (s *InterfaceAppToWiFiGroundStation) GetMessagePayloadSetPairingPIN(
 	pinCode string,
 ) []byte {
-	var buf bytes.Buffer
-	must(buf.Write(duml.PackString("001749319286102")))
-	must(buf.Write(duml.PackString(pinCode)))
-	return buf.Bytes()
+	return must((&duml.SetPairingPINRequest{
+		AppID: defaultAppID,
+		PIN:   pinCode,
+	}).MarshalDUML())
 }
 
 func (s *InterfaceAppToWiFiGroundStation) ReceiveMessageSetPairingPINResult(
 	ctx context.Context,
 ) (*duml.Message, error) {
-	return s.Device().ReceiveMessage(ctx, duml.MessageTypeSetPairingPIN)
+	return duml.ReceiveMessage(ctx, s.Conn(), duml.MessageTypeSetPairingPIN)
 }
 
 func (s *InterfaceAppToWiFiGroundStation) RequestPairingStage1(
@@ -108,7 +122,7 @@ func (s *InterfaceAppToWiFiGroundStation) RequestPairingStage1(
 	logger.Tracef(ctx, "RequestPairingStage1")
 	defer func() { logger.Tracef(ctx, "/RequestPairingStage1: %v", _err) }()
 	msg := s.GetMessagePairingStage1()
-	return s.Device().Request(ctx, msg, true)
+	return s.Conn().Request(ctx, msg)
 }
 
 func (s *InterfaceAppToWiFiGroundStation) GetMessagePairingStage1() *duml.Message {
@@ -126,7 +140,7 @@ func (s *InterfaceAppToWiFiGroundStation) RequestPairingStage2(
 	logger.Tracef(ctx, "RequestPairingStage2")
 	defer func() { logger.Tracef(ctx, "/RequestPairingStage2: %v", _err) }()
 	msg := s.GetMessagePairingStage2()
-	return s.Device().Request(ctx, msg, true)
+	return s.Conn().Request(ctx, msg)
 }
 
 func (s *InterfaceAppToWiFiGroundStation) GetMessagePairingStage2() *duml.Message {
@@ -0,0 +1,44 @@
+package djible
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xaionaro-go/djictl/pkg/duml"
+	"github.com/xaionaro-go/gatt"
+)
+
+// Advertisement is the last BLE advertisement received from a Device.
+type Advertisement struct {
+	duml.AdvertisementData
+
+	LocalName   string
+	RSSI        int
+	Connectable bool
+}
+
+// ParseAdvertisement parses the advertisement of a discovered peripheral.
+func ParseAdvertisement(adv *gatt.Advertisement, rssi int) Advertisement {
+	return Advertisement{
+		AdvertisementData: duml.ParseAdvertisementData(adv.ManufacturerData),
+		LocalName:         adv.LocalName,
+		RSSI:              rssi,
+		Connectable:       adv.Connectable,
+	}
+}
+
+func (a Advertisement) String() string {
+	parts := []string{
+		fmt.Sprintf("model: %s", a.DeviceType),
+	}
+	if a.HasVariant {
+		parts = append(parts, fmt.Sprintf("variant: %d", a.Variant))
+	}
+	parts = append(parts, fmt.Sprintf("RSSI: %ddBm", a.RSSI))
+	if a.HasStatus {
+		parts = append(parts, fmt.Sprintf("status: %s", a.Status))
+	} else {
+		parts = append(parts, "status: unknown")
+	}
+	return strings.Join(parts, "; ")
+}
@@ -2,9 +2,8 @@ package djible
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"io"
+	"math/rand/v2"
 	"net"
 	"sort"
 
@@ -14,48 +13,68 @@ import (
 	"github.com/xaionaro-go/xsync"
 )
 
+// The value handles of the characteristics used to talk DUML over BLE.
 const (
-	characteristicIDReceiver         = uint16(0x002D)
-	characteristicIDPairingRequestor = uint16(0x002E)
-	characteristicIDSender           = uint16(0x0030)
+	CharacteristicIDReceiver         = uint16(0x002D)
+	CharacteristicIDPairingRequestor = uint16(0x002E)
+	CharacteristicIDSender           = uint16(0x0030)
 )
 
+// FrameHook observes the raw bytes written to (DirectionSent) and notified by
+// (DirectionReceived) the characteristic with the given value handle.
+type FrameHook func(ctx context.Context, direction duml.Direction, handle uint16, b []byte)
+
 type Device struct {
 	Periph gatt.Peripheral
 	ID     DeviceID
 	Type   duml.DeviceType
 	Name   string
 
+	// Advertisement is the advertisement the device was discovered by.
+	Advertisement Advertisement
+
 	ConnectedChan                  chan struct{}
 	CharacteristicSender           *gatt.Characteristic
 	CharacteristicPairingRequestor *gatt.Characteristic
 	CharacteristicReceiver         *gatt.Characteristic
 
-	ReceiveBuffer xsync.Map[*gatt.Characteristic, []byte]
+	*duml.Endpoint
+
+	ReceiveDecoderLocker xsync.Mutex
+	ReceiveDecoder       map[*gatt.Characteristic]*duml.Decoder
 
-	ReceiveLocker                          xsync.Mutex
 	ReceivedPairingRequestConfirmationChan chan struct{}
-	ReceivedMessageChan                    map[duml.MessageType]chan *duml.Message
-	ReceivedResponseChan                   map[duml.MessageID]chan *duml.Message
+
+	// OnFrame, if set, is called for each frame sent or received (e.g. to record the session).
+	// It should be set before Init.
+	OnFrame FrameHook
 }
 
+var _ duml.Conn = (*Device)(nil)
+
 func NewDevice(
 	periph gatt.Peripheral,
 	id net.HardwareAddr,
 	typ duml.DeviceType,
 	name string,
 ) *Device {
-	return &Device{
+	d := &Device{
 		Periph: periph,
 		ID:     id,
 		Type:   typ,
 		Name:   name,
 
 		ConnectedChan:                          make(chan struct{}),
+		ReceiveDecoder:                         make(map[*gatt.Characteristic]*duml.Decoder),
 		ReceivedPairingRequestConfirmationChan: make(chan struct{}),
-		ReceivedMessageChan:                    make(map[duml.MessageType]chan *duml.Message),
-		ReceivedResponseChan:                   make(map[duml.MessageID]chan *duml.Message),
 	}
+	d.Endpoint = duml.NewEndpoint(duml.MessageID(rand.Uint32()), d.writeMessage)
+	return d
+}
+
+// DeviceType implements duml.Conn.
+func (d *Device) DeviceType() duml.DeviceType {
+	return d.Type
 }
 
 func (d *Device) String() string {
@@ -91,11 +110,11 @@ func (d *Device) getCharacteristics(
 		for _, characteristic := range characteristics {
 			logger.Tracef(ctx, "found characteristic %04X:%04X", characteristic.Handle(), characteristic.VHandle())
 			switch characteristic.VHandle() {
-			case characteristicIDReceiver:
+			case CharacteristicIDReceiver:
 				receiver = characteristic
-			case characteristicIDSender:
+			case CharacteristicIDSender:
 				sender = characteristic
-			case characteristicIDPairingRequestor:
+			case CharacteristicIDPairingRequestor:
 				pairingRequestor = characteristic
 			default:
 				continue
@@ -105,11 +124,11 @@ func (d *Device) getCharacteristics(
 
 	switch {
 	case receiver == nil:
-		return nil, nil, nil, fmt.Errorf("unable to find characteristic %04X", characteristicIDReceiver)
+		return nil, nil, nil, fmt.Errorf("unable to find characteristic %04X", CharacteristicIDReceiver)
 	case sender == nil:
-		return nil, nil, nil, fmt.Errorf("unable to find characteristic %04X", characteristicIDSender)
+		return nil, nil, nil, fmt.Errorf("unable to find characteristic %04X", CharacteristicIDSender)
 	case pairingRequestor == nil:
-		pairingRequestor = gatt.NewCharacteristic(gatt.UUID{}, nil, 0, 0, characteristicIDPairingRequestor)
+		pairingRequestor = gatt.NewCharacteristic(gatt.UUID{}, nil, 0, 0, CharacteristicIDPairingRequestor)
 	}
 	return
 }
@@ -121,8 +140,9 @@ func (d *Device) Init(ctx context.Context) (_err error) {
 	logger.Debugf(ctx, "connecting to %s:%s", d.Periph.ID(), d.Periph.Name())
 	d.Periph.Device().Connect(ctx, d.Periph)
 	<-d.ConnectedChan
-	statusChan := d.getReceiveMessageChan(ctx, duml.MessageTypeBatteryStatus)
-	d.Periph.Subscribe(0x2D, func(c *gatt.Characteristic, b []byte, err error) {
+	statusSub := d.Subscribe(ctx, duml.FilterType(duml.MessageTypeBatteryStatus))
+	defer statusSub.Close()
+	d.Periph.Subscribe(CharacteristicIDReceiver, func(c *gatt.Characteristic, b []byte, err error) {
 		d.receiveNotification(ctx, c, b, err)
 	})
 	logger.Debugf(ctx, "connected")
@@ -149,7 +169,7 @@ func (d *Device) Init(ctx context.Context) (_err error) {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
-	case msg := <-statusChan:
+	case msg := <-statusSub.C():
 		logger.Debugf(ctx, "received a status: %#+v", msg)
 	}
 	return nil
@@ -167,69 +187,31 @@ func (d *Device) receiveNotification(
 	}()
 
 	if err != nil {
-		logger.Errorf(ctx, "received a notification about an error: %v")
+		logger.Errorf(ctx, "received a notification about an error: %v", err)
 		return
 	}
+	d.onFrame(ctx, duml.DirectionReceived, c, b)
 
-	prevBuf, _ := d.ReceiveBuffer.LoadAndDelete(c)
-	combined := append(prevBuf, b...)
-	msg, err := duml.ParseMessage(combined)
-	if err != nil {
-		if errors.Is(err, io.ErrUnexpectedEOF) {
-			logger.Debugf(ctx, "message is incomplete, waiting for more data...")
-			d.ReceiveBuffer.Store(c, combined)
-			return
+	msgs := xsync.DoR1(ctx, &d.ReceiveDecoderLocker, func() []*duml.Message {
+		decoder := d.ReceiveDecoder[c]
+		if decoder == nil {
+			decoder = duml.NewDecoder()
+			d.ReceiveDecoder[c] = decoder
 		}
-		logger.Errorf(ctx, "unable to parse the duml.Message (%X): %v", b, err)
-		return
-	}
-	logger.Debugf(ctx, "received duml.Message: %#+v", msg)
-	logger.Tracef(ctx, "payload: %X", msg.Payload)
-
-	if msg.Type.Flags&duml.MessageTypeFlagAckRequired != 0 {
-		logger.Debugf(ctx, "sending ACK for message %v", msg.Type)
-		if err := d.SendACK(ctx, msg); err != nil {
-			logger.Errorf(ctx, "unable to send ACK for message %v: %v", msg.Type, err)
-		}
-	}
-
-	if msg.Type.Flags&duml.MessageTypeFlagResponse != 0 {
-		select {
-		case d.getReceiveResponseChan(ctx, msg.ID) <- msg:
-		default:
-			logger.Tracef(ctx, "nobody waits for response to message ID %v, skipping", msg.ID)
+		droppedBefore := decoder.DroppedBytes()
+		msgs := decoder.Decode(b)
+		if dropped := decoder.DroppedBytes() - droppedBefore; dropped > 0 {
+			logger.Errorf(ctx, "dropped %d bytes of garbage while looking for a duml.Message (%X)", dropped, b)
 		}
-	}
-
-	select {
-	case d.getReceiveMessageChan(ctx, msg.Type) <- msg:
-	default:
-		logger.Debugf(ctx, "nobody waits for this message (%v), skipping", msg.Type)
-	}
-}
-
-func (d *Device) getReceiveMessageChan(
-	ctx context.Context,
-	msgType duml.MessageType,
-) chan *duml.Message {
-	return xsync.DoR1(ctx, &d.ReceiveLocker, func() chan *duml.Message {
-		if d.ReceivedMessageChan[msgType] == nil {
-			d.ReceivedMessageChan[msgType] = make(chan *duml.Message, 1)
+		if decoder.Buffered() > 0 {
+			logger.Debugf(ctx, "message is incomplete, waiting for more data...")
 		}
-		return d.ReceivedMessageChan[msgType]
+		return msgs
 	})
-}
 
-func (d *Device) getReceiveResponseChan(
-	ctx context.Context,
-	msgID duml.MessageID,
-) chan *duml.Message {
-	return xsync.DoR1(ctx, &d.ReceiveLocker, func() chan *duml.Message {
-		if d.ReceivedResponseChan[msgID] == nil {
-			d.ReceivedResponseChan[msgID] = make(chan *duml.Message, 1)
-		}
-		return d.ReceivedResponseChan[msgID]
-	})
+	for _, msg := range msgs {
+		d.HandleMessage(ctx, msg)
+	}
 }
 
 func (d *Device) IsInitialized() bool {
@@ -244,81 +226,48 @@ func (d *Device) SendPairingRequest(
 	if !d.IsInitialized() {
 		return fmt.Errorf("call Init first")
 	}
-	return d.Periph.WriteCharacteristic(ctx, d.CharacteristicPairingRequestor, []byte{0x01, 0x00}, false)
+	return d.writeCharacteristic(ctx, d.CharacteristicPairingRequestor, []byte{0x01, 0x00}, false)
 }
 
-func (d *Device) SendMessage(
+// writeMessage writes the message to the sender characteristic (without waiting for a write response).
+func (d *Device) writeMessage(
 	ctx context.Context,
 	msg *duml.Message,
-	noResponse bool,
 ) error {
-	logger.Tracef(ctx, "SendMessage")
-	defer func() { logger.Tracef(ctx, "/SendMessage") }()
 	if !d.IsInitialized() {
 		return fmt.Errorf("call Init first")
 	}
 
-	return d.Periph.WriteCharacteristic(ctx, d.CharacteristicSender, msg.Bytes(), noResponse)
+	return d.writeCharacteristic(ctx, d.CharacteristicSender, msg.Bytes(), true)
 }
 
-func (d *Device) Request(
+func (d *Device) writeCharacteristic(
 	ctx context.Context,
-	msg *duml.Message,
+	c *gatt.Characteristic,
+	b []byte,
 	noResponse bool,
-) (*duml.Message, error) {
-	logger.Tracef(ctx, "Request")
-	defer func() { logger.Tracef(ctx, "/Request") }()
-
-	if msg.Type.Flags&duml.MessageTypeFlagAckRequired == 0 {
-		return nil, fmt.Errorf("Request() called for a message that does not require a response; use SendMessage() instead")
-	}
-
-	respChan := d.getReceiveResponseChan(ctx, msg.ID)
-	// Clear previous stale responses if any
-	select {
-	case <-respChan:
-	default:
-	}
-
-	err := d.SendMessage(ctx, msg, noResponse)
-	if err != nil {
-		return nil, err
-	}
-
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case resp := <-respChan:
-		return resp, nil
-	}
+) error {
+	d.onFrame(ctx, duml.DirectionSent, c, b)
+	return d.Periph.WriteCharacteristic(ctx, c, b, noResponse)
 }
 
-func (d *Device) SendACK(
+func (d *Device) onFrame(
 	ctx context.Context,
-	msg *duml.Message,
-) error {
-	ack := &duml.Message{
-		Interface: duml.InterfaceID{
-			Sender:   msg.Interface.Receiver,
-			Receiver: msg.Interface.Sender,
-		},
-		ID:      msg.ID,
-		Type:    duml.MessageTypeResponse(msg.Type.CmdSet, msg.Type.CmdID),
-		Payload: []byte{0x00},
+	direction duml.Direction,
+	c *gatt.Characteristic,
+	b []byte,
+) {
+	if d.OnFrame == nil {
+		return
 	}
-	return d.SendMessage(ctx, ack, true)
+	d.OnFrame(ctx, direction, c.VHandle(), b)
 }
 
-func (d *Device) ReceiveMessage(
+// onDisconnect fails all the pending requests and closes all the subscriptions.
+func (d *Device) onDisconnect(
 	ctx context.Context,
-	msgType duml.MessageType,
-) (_ret *duml.Message, _err error) {
-	logger.Tracef(ctx, "ReceiveMessage")
-	defer func() { logger.Tracef(ctx, "/ReceiveMessage: %v", _err) }()
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case v := <-d.getReceiveMessageChan(ctx, msgType):
-		return v, nil
-	}
+	err error,
+) {
+	logger.Debugf(ctx, "device %s disconnected: %v", d, err)
+	d.Endpoint.Close(duml.ErrDisconnected)
 }
@@ -47,7 +47,7 @@ func TestDevice_SendMessage(t *testing.T) {
 		return nil
 	}
 
-	err := dev.SendMessage(ctx, msg, true)
+	err := dev.SendMessage(ctx, msg)
 	if err != nil {
 		t.Fatalf("SendMessage failed: %v", err)
 	}
@@ -68,7 +68,7 @@ func TestDevice_Request_NoAckRequired(t *testing.T) {
 		},
 	}
 
-	_, err := dev.Request(ctx, msg, true)
+	_, err := dev.Request(ctx, msg)
 	if err == nil {
 		t.Fatal("Request should have failed for message without AckRequired flag")
 	}
@@ -116,7 +116,7 @@ func TestDevice_Request_Success(t *testing.T) {
 		dev.receiveNotification(context.Background(), senderChar, resp.Bytes(), nil)
 	}()
 
-	resp, err := dev.Request(ctx, msg, true)
+	resp, err := dev.Request(ctx, msg)
 	if err != nil {
 		t.Fatalf("Request failed: %v", err)
 	}
@@ -148,7 +148,7 @@ func TestDevice_Request_Timeout(t *testing.T) {
 		},
 	}
 
-	_, err := dev.Request(ctx, msg, true)
+	_, err := dev.Request(ctx, msg)
 	if err == nil {
 		t.Fatal("Request should have timed out")
 	}
@@ -156,3 +156,216 @@ func TestDevice_Request_Timeout(t *testing.T) {
 		t.Errorf("Expected DeadlineExceeded error, got %v", err)
 	}
 }
+
+func TestDevice_Request_SameIDDifferentCommands(t *testing.T) {
+	mock := &mockPeripheral{}
+	dev := NewDevice(mock, nil, duml.DeviceTypeOsmoAction4, "test-device")
+	senderChar := &gatt.Characteristic{}
+	dev.CharacteristicSender = senderChar
+	dev.CharacteristicReceiver = &gatt.Characteristic{}
+	dev.CharacteristicPairingRequestor = &gatt.Characteristic{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var requests []*duml.Message
+	for _, cmdID := range []duml.CommandID{0x01, 0x02} {
+		requests = append(requests, &duml.Message{
+			Interface: duml.InterfaceIDAppToCamera,
+			ID:        duml.MessageIDStartStreaming,
+			Type:      duml.MessageTypeRequest(duml.CommandSetCamera, cmdID),
+		})
+	}
+
+	// respond in the reverse order, without swapping the interface
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		for i := len(requests) - 1; i >= 0; i-- {
+			resp := &duml.Message{
+				Interface: requests[i].Interface,
+				ID:        requests[i].ID,
+				Type:      duml.MessageTypeResponse(requests[i].Type.CmdSet, requests[i].Type.CmdID),
+				Payload:   []byte{byte(i)},
+			}
+			dev.receiveNotification(context.Background(), senderChar, resp.Bytes(), nil)
+		}
+	}()
+
+	type result struct {
+		resp *duml.Message
+		err  error
+	}
+	results := make([]chan result, len(requests))
+	for i, req := range requests {
+		results[i] = make(chan result, 1)
+		go func() {
+			resp, err := dev.Request(ctx, req)
+			results[i] <- result{resp, err}
+		}()
+	}
+	for i, ch := range results {
+		r := <-ch
+		if r.err != nil {
+			t.Fatalf("Request %d failed: %v", i, r.err)
+		}
+		if r.resp.Type.CmdID != requests[i].Type.CmdID || r.resp.Payload[0] != byte(i) {
+			t.Errorf("request %d received a wrong response: %v", i, r.resp)
+		}
+	}
+}
+
+func TestDevice_SendMessage_AutoID(t *testing.T) {
+	mock := &mockPeripheral{}
+	dev := NewDevice(mock, nil, duml.DeviceTypeOsmoAction4, "test-device")
+	dev.CharacteristicSender = &gatt.Characteristic{}
+	dev.CharacteristicReceiver = &gatt.Characteristic{}
+	dev.CharacteristicPairingRequestor = &gatt.Characteristic{}
+	dev.Sequencer = duml.NewSequencer(100)
+
+	ctx := context.Background()
+	var sentIDs []duml.MessageID
+	mock.writeFunc = func(c *gatt.Characteristic, b []byte, noResp bool) error {
+		msg, err := duml.ParseMessage(b)
+		if err != nil {
+			return err
+		}
+		sentIDs = append(sentIDs, msg.ID)
+		return nil
+	}
+
+	for _, msg := range []*duml.Message{
+		{Interface: duml.InterfaceIDAppToCamera, AutoID: true, Type: duml.MessageTypeGetVersion},
+		{Interface: duml.InterfaceIDAppToCamera, ID: duml.MessageIDConnectToWifi, Type: duml.MessageTypeGetVersion},
+		{Interface: duml.InterfaceIDAppToCamera, ID: 0, Type: duml.MessageTypeGetVersion},
+		{Interface: duml.InterfaceIDAppToCamera, AutoID: true, Type: duml.MessageTypeGetVersion},
+	} {
+		if err := dev.SendMessage(ctx, msg); err != nil {
+			t.Fatalf("SendMessage failed: %v", err)
+		}
+	}
+	if err := dev.SendACK(ctx, &duml.Message{ID: 0, Type: duml.MessageTypeGetVersion}); err != nil {
+		t.Fatalf("SendACK failed: %v", err)
+	}
+
+	expected := []duml.MessageID{100, duml.MessageIDConnectToWifi, 0, 101, 0}
+	if len(sentIDs) != len(expected) {
+		t.Fatalf("expected %d messages, got %d", len(expected), len(sentIDs))
+	}
+	for i := range expected {
+		if sentIDs[i] != expected[i] {
+			t.Errorf("message %d: expected ID %v, got %v", i, expected[i], sentIDs[i])
+		}
+	}
+}
+
+func TestDevice_Request_Retransmit(t *testing.T) {
+	mock := &mockPeripheral{}
+	dev := NewDevice(mock, nil, duml.DeviceTypeOsmoAction4, "test-device")
+	senderChar := &gatt.Characteristic{}
+	dev.CharacteristicSender = senderChar
+	dev.CharacteristicReceiver = &gatt.Characteristic{}
+	dev.CharacteristicPairingRequestor = &gatt.Characteristic{}
+
+	var sentIDs []duml.MessageID
+	mock.writeFunc = func(c *gatt.Characteristic, b []byte, noResp bool) error {
+		msg, err := duml.ParseMessage(b)
+		if err != nil {
+			return err
+		}
+		sentIDs = append(sentIDs, msg.ID)
+		if len(sentIDs) < 3 {
+			return nil // the device "lost" the request
+		}
+		resp := &duml.Message{
+			Interface: duml.InterfaceID{Sender: msg.Interface.Receiver, Receiver: msg.Interface.Sender},
+			ID:        msg.ID,
+			Type:      duml.MessageTypeResponse(msg.Type.CmdSet, msg.Type.CmdID),
+		}
+		go dev.receiveNotification(context.Background(), senderChar, resp.Bytes(), nil)
+		return nil
+	}
+
+	msg := &duml.Message{
+		Interface: duml.InterfaceIDAppToBattery,
+		Type:      duml.MessageTypeGetBatteryInfo,
+	}
+	_, err := dev.Request(context.Background(), msg, duml.RequestTimeout(20*time.Millisecond), duml.RequestRetries(2))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if len(sentIDs) != 3 || sentIDs[0] != sentIDs[1] || sentIDs[1] != sentIDs[2] {
+		t.Errorf("expected 3 transmissions with the same ID, got %v", sentIDs)
+	}
+
+	mock.writeFunc = nil
+	_, err = dev.Request(context.Background(), msg, duml.RequestTimeout(10*time.Millisecond), duml.RequestRetries(1))
+	if !errors.Is(err, duml.ErrTimeout) {
+		t.Errorf("expected ErrTimeout, got %v", err)
+	}
+}
+
+func TestDevice_Request_Disconnect(t *testing.T) {
+	mock := &mockPeripheral{}
+	dev := NewDevice(mock, nil, duml.DeviceTypeOsmoAction4, "test-device")
+	dev.CharacteristicSender = &gatt.Characteristic{}
+	dev.CharacteristicReceiver = &gatt.Characteristic{}
+	dev.CharacteristicPairingRequestor = &gatt.Characteristic{}
+
+	ctx := context.Background()
+	sent := make(chan struct{}, 1)
+	mock.writeFunc = func(c *gatt.Characteristic, b []byte, noResp bool) error {
+		sent <- struct{}{}
+		return nil
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := dev.Request(ctx, &duml.Message{
+			Interface: duml.InterfaceIDAppToBattery,
+			Type:      duml.MessageTypeGetBatteryInfo,
+		})
+		errCh <- err
+	}()
+	<-sent
+	dev.onDisconnect(ctx, nil)
+
+	if err := <-errCh; !errors.Is(err, duml.ErrDisconnected) {
+		t.Errorf("expected ErrDisconnected, got %v", err)
+	}
+}
+
+func TestDevice_Subscribe(t *testing.T) {
+	mock := &mockPeripheral{}
+	dev := NewDevice(mock, nil, duml.DeviceTypeOsmoAction4, "test-device")
+	receiverChar := &gatt.Characteristic{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	subCtx, subCancel := context.WithCancel(ctx)
+	monitor := dev.Subscribe(subCtx, duml.FilterType(duml.MessageTypeBatteryStatus))
+	flow := dev.Subscribe(ctx, duml.FilterType(duml.MessageTypeBatteryStatus))
+
+	status := &duml.Message{Type: duml.MessageTypeBatteryStatus, Payload: make([]byte, 21)}
+	dev.receiveNotification(ctx, receiverChar, status.Bytes(), nil)
+
+	for name, sub := range map[string]*duml.Subscription{"monitor": monitor, "flow": flow} {
+		msg, err := sub.Receive(ctx)
+		if err != nil {
+			t.Fatalf("%s: unable to receive: %v", name, err)
+		}
+		if msg.Type != duml.MessageTypeBatteryStatus {
+			t.Errorf("%s: unexpected message %v", name, msg)
+		}
+	}
+
+	subCancel()
+	if _, err := monitor.Receive(ctx); !errors.Is(err, duml.ErrSubscriptionClosed) {
+		t.Errorf("expected the subscription to be closed with the context, got %v", err)
+	}
+
+	dev.onDisconnect(ctx, nil)
+	if _, err := flow.Receive(ctx); !errors.Is(err, duml.ErrDisconnected) {
+		t.Errorf("expected ErrDisconnected, got %v", err)
+	}
+}
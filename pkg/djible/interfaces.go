@@ -0,0 +1,42 @@
+package djible
+
+import (
+	"github.com/xaionaro-go/djictl/pkg/djiapi"
+)
+
+// The typed commands are implemented in package djiapi on top of duml.Conn;
+// these aliases are kept for compatibility.
+type (
+	InterfaceAppToBattery           = djiapi.InterfaceAppToBattery
+	InterfaceAppToCamera            = djiapi.InterfaceAppToCamera
+	InterfaceAppToGoggles           = djiapi.InterfaceAppToGoggles
+	InterfaceAppToRemoteController  = djiapi.InterfaceAppToRemoteController
+	InterfaceAppToVideoTransmission = djiapi.InterfaceAppToVideoTransmission
+	InterfaceAppToWiFiGroundStation = djiapi.InterfaceAppToWiFiGroundStation
+)
+
+var _ djiapi.PairingRequester = (*Device)(nil)
+
+func (d *Device) AppToBattery() *InterfaceAppToBattery {
+	return djiapi.AppToBattery(d)
+}
+
+func (d *Device) AppToCamera() *InterfaceAppToCamera {
+	return djiapi.AppToCamera(d)
+}
+
+func (d *Device) AppToGoggles() *InterfaceAppToGoggles {
+	return djiapi.AppToGoggles(d)
+}
+
+func (d *Device) AppToRemoteController() *InterfaceAppToRemoteController {
+	return djiapi.AppToRemoteController(d)
+}
+
+func (d *Device) AppToVideoTransmission() *InterfaceAppToVideoTransmission {
+	return djiapi.AppToVideoTransmission(d)
+}
+
+func (d *Device) AppToWiFiGroundStation() *InterfaceAppToWiFiGroundStation {
+	return djiapi.AppToWiFiGroundStation(d)
+}
@@ -54,8 +54,8 @@ func ScanWithDevice(
 					logger.Tracef(ctx, "/gatt.PeripheralDiscovered(ctx, %s:%s)", periph.ID(), periph.Name())
 				}()
 			}
-			deviceType := duml.IdentifyDeviceType(adv.ManufacturerData)
-			if deviceType == duml.DeviceTypeUndefined {
+			advertisement := ParseAdvertisement(adv, rssi)
+			if advertisement.DeviceType == duml.DeviceTypeUndefined {
 				if logUnknownDevices {
 					logger.Debugf(ctx, "ignoring device %s: considered a non DJI Osmo device (%X)", periph.ID(), adv.ManufacturerData)
 				}
@@ -67,7 +67,8 @@ func ScanWithDevice(
 				errCh <- fmt.Errorf("unable to parse device ID '%s': %w", periph.ID(), err)
 				return
 			}
-			dev := NewDevice(periph, deviceID, deviceType, adv.LocalName)
+			dev := NewDevice(periph, deviceID, advertisement.DeviceType, adv.LocalName)
+			dev.Advertisement = advertisement
 			devicesLocker.Do(ctx, func() {
 				devices[periph.ID()] = dev
 			})
@@ -88,6 +89,19 @@ func ScanWithDevice(
 			dev.Periph = periph
 			close(dev.ConnectedChan)
 		}),
+		gatt.PeripheralDisconnected(func(ctx context.Context, periph gatt.Peripheral, err error) {
+			logger.Tracef(ctx, "gatt.PeripheralDisconnected(ctx, %s:%s, %v)", periph.ID(), periph.Name(), err)
+			defer func() {
+				logger.Tracef(ctx, "/gatt.PeripheralDisconnected(ctx, %s:%s, %v)", periph.ID(), periph.Name(), err)
+			}()
+			dev := xsync.DoR1(ctx, &devicesLocker, func() *Device {
+				return devices[periph.ID()]
+			})
+			if dev == nil {
+				return
+			}
+			dev.onDisconnect(ctx, err)
+		}),
 	)
 
 	err := d.Start(ctx, func(ctx context.Context, d gatt.Device, s gatt.State) {
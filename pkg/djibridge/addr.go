@@ -0,0 +1,43 @@
+package djibridge
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// DefaultAddr is the default address of the bridge.
+const DefaultAddr = "tcp:127.0.0.1:9005"
+
+// ParseAddr splits an address in form "tcp:host:port" or "unix:/path/to/socket"
+// into the network and the address for net.Listen/net.Dial. An address
+// without a known prefix is considered a TCP address.
+func ParseAddr(s string) (network string, address string, _err error) {
+	network, address, ok := strings.Cut(s, ":")
+	if ok {
+		switch network {
+		case "tcp", "tcp4", "tcp6", "unix":
+			if address == "" {
+				return "", "", fmt.Errorf("empty address in '%s'", s)
+			}
+			return network, address, nil
+		}
+	}
+	if _, _, err := net.SplitHostPort(s); err != nil {
+		return "", "", fmt.Errorf("unable to parse address '%s' (expected 'tcp:host:port' or 'unix:/path'): %w", s, err)
+	}
+	return "tcp", s, nil
+}
+
+// Listen listens on an address in form accepted by ParseAddr.
+func Listen(addr string) (net.Listener, error) {
+	network, address, err := ParseAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	l, err := net.Listen(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen on %s:%s: %w", network, address, err)
+	}
+	return l, nil
+}
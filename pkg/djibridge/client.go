@@ -0,0 +1,42 @@
+package djibridge
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// Client is a connection to a device through a bridge Server.
+type Client struct {
+	Addr string
+
+	*duml.StreamConn
+}
+
+var _ duml.Conn = (*Client)(nil)
+
+// Dial connects to a bridge at an address in form accepted by ParseAddr.
+func Dial(
+	ctx context.Context,
+	addr string,
+) (*Client, error) {
+	network, address, err := ParseAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the bridge at %s:%s: %w", network, address, err)
+	}
+	return &Client{
+		Addr:       addr,
+		StreamConn: duml.NewStreamConn(ctx, conn),
+	}, nil
+}
+
+func (c *Client) String() string {
+	return "bridge:" + c.Addr
+}
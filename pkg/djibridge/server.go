@@ -0,0 +1,191 @@
+// Package djibridge shares one connection to a DJI device (e.g. over BLE,
+// where only one process could hold the HCI adapter) with many local
+// clients through a TCP or Unix socket carrying raw DUML frames.
+//
+// The requests of the clients are forwarded with message IDs allocated by the
+// shared connection, and the responses are returned to the requesting client
+// with the original IDs. The messages pushed by the device are broadcast to
+// all the clients.
+package djibridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+	"github.com/xaionaro-go/xsync"
+)
+
+// Server serves a duml.Conn to many clients.
+type Server struct {
+	Conn duml.Conn
+
+	// RequestOptions are used to forward the requests of the clients.
+	RequestOptions []duml.RequestOption
+
+	numClients atomic.Int64
+}
+
+// NewServer returns a Server sharing the given connection.
+func NewServer(conn duml.Conn) *Server {
+	return &Server{
+		Conn: conn,
+	}
+}
+
+// NumClients returns the amount of currently connected clients.
+func (s *Server) NumClients() int {
+	return int(s.numClients.Load())
+}
+
+// Serve accepts clients until the context is cancelled or the listener fails.
+// The listener is closed on return.
+func (s *Server) Serve(
+	ctx context.Context,
+	l net.Listener,
+) (_err error) {
+	logger.Tracef(ctx, "Serve")
+	defer func() { logger.Tracef(ctx, "/Serve: %v", _err) }()
+
+	ctx, cancel := context.WithCancel(ctx)
+	context.AfterFunc(ctx, func() { l.Close() })
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("unable to accept a client: %w", err)
+		}
+		logger.Infof(ctx, "client %s connected", conn.RemoteAddr())
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.ServeConn(ctx, conn)
+			logger.Infof(ctx, "client %s disconnected: %v", conn.RemoteAddr(), err)
+		}()
+	}
+}
+
+type client struct {
+	conn        net.Conn
+	writeLocker xsync.Mutex
+}
+
+func (c *client) writeMessage(ctx context.Context, msg *duml.Message) error {
+	b := msg.Bytes()
+	return xsync.DoR1(ctx, &c.writeLocker, func() error {
+		_, err := c.conn.Write(b)
+		return err
+	})
+}
+
+// ServeConn serves one client until it disconnects or the context is cancelled.
+// The connection is closed on return.
+func (s *Server) ServeConn(
+	ctx context.Context,
+	conn net.Conn,
+) (_err error) {
+	logger.Tracef(ctx, "ServeConn")
+	defer func() { logger.Tracef(ctx, "/ServeConn: %v", _err) }()
+
+	s.numClients.Add(1)
+	defer s.numClients.Add(-1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	context.AfterFunc(ctx, func() { conn.Close() })
+
+	c := &client{conn: conn}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	pushes := s.Conn.Subscribe(ctx, duml.FilterResponses(false))
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer cancel()
+		for {
+			msg, err := pushes.Receive(ctx)
+			if err != nil {
+				logger.Debugf(ctx, "stopped forwarding the pushes to %s: %v", conn.RemoteAddr(), err)
+				return
+			}
+			if err := c.writeMessage(ctx, msg); err != nil {
+				logger.Debugf(ctx, "unable to forward a push to %s: %v", conn.RemoteAddr(), err)
+				return
+			}
+		}
+	}()
+
+	err := duml.NewDecoder().ReadMessages(conn, func(msg *duml.Message) error {
+		s.handleClientMessage(ctx, c, msg, &wg)
+		return nil
+	})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, os.ErrClosed) || errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) handleClientMessage(
+	ctx context.Context,
+	c *client,
+	msg *duml.Message,
+	wg *sync.WaitGroup,
+) {
+	logger.Debugf(ctx, "received from client %s: %s", c.conn.RemoteAddr(), msg)
+
+	switch {
+	case msg.Type.Flags&duml.MessageTypeFlagResponse != 0:
+		// the pushes are already acknowledged by the shared connection
+		logger.Debugf(ctx, "dropping the response %s (ID %v) from client %s", msg.Type, msg.ID, c.conn.RemoteAddr())
+	case msg.Type.Flags&duml.MessageTypeFlagAckRequired != 0:
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.forwardRequest(ctx, c, msg)
+		}()
+	default:
+		fwd := *msg
+		fwd.AutoID = true
+		if err := s.Conn.SendMessage(ctx, &fwd); err != nil {
+			logger.Errorf(ctx, "unable to forward %s from client %s: %v", msg.Type, c.conn.RemoteAddr(), err)
+		}
+	}
+}
+
+func (s *Server) forwardRequest(
+	ctx context.Context,
+	c *client,
+	msg *duml.Message,
+) {
+	fwd := *msg
+	fwd.AutoID = true
+	resp, err := s.Conn.Request(ctx, &fwd, s.RequestOptions...)
+	if err != nil {
+		logger.Errorf(ctx, "unable to forward request %s (ID %v) from client %s: %v", msg.Type, msg.ID, c.conn.RemoteAddr(), err)
+		return
+	}
+	logger.Debugf(ctx, "forwarding the response %s (ID %v -> %v) to client %s", resp.Type, resp.ID, msg.ID, c.conn.RemoteAddr())
+	out := *resp
+	out.ID = msg.ID
+	if err := c.writeMessage(ctx, &out); err != nil {
+		logger.Debugf(ctx, "unable to forward the response to %s: %v", c.conn.RemoteAddr(), err)
+	}
+}
@@ -0,0 +1,143 @@
+package djibridge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/djictl/pkg/djible"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// newEmulatedDevice returns an initialized BLE connection to an emulated camera
+// (which is stopped at the end of the test).
+func newEmulatedDevice(ctx context.Context, t *testing.T, camera *djiemu.Camera) *djible.Device {
+	bleDev := camera.NewBLEDevice(ctx)
+	t.Cleanup(func() { bleDev.Close() })
+	devCh, errCh, err := djible.ScanWithDevice(ctx, bleDev)
+	require.NoError(t, err)
+	var dev *djible.Device
+	select {
+	case dev = <-devCh:
+	case err := <-errCh:
+		t.Fatal(err)
+	case <-ctx.Done():
+		t.Fatal("the emulated device was not found")
+	}
+	require.NoError(t, dev.Init(ctx))
+	return dev
+}
+
+func TestServer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoPocket3)
+	camera.BatteryPushInterval = 10 * time.Millisecond
+	device := newEmulatedDevice(ctx, t, camera)
+	numSubs := device.Subscriptions.Len()
+
+	srv := NewServer(device)
+	l, err := Listen("tcp:127.0.0.1:0")
+	require.NoError(t, err)
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.Serve(ctx, l) }()
+
+	var clients []*Client
+	for range 2 {
+		c, err := Dial(ctx, "tcp:"+l.Addr().String())
+		require.NoError(t, err)
+		defer c.Close()
+		clients = append(clients, c)
+	}
+	require.Eventually(t, func() bool {
+		return device.Subscriptions.Len() == numSubs+len(clients)
+	}, time.Second, time.Millisecond)
+
+	serialNumber, err := (&duml.StringResult{Value: camera.SerialNumber}).MarshalDUML()
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(clients)*5)
+	for _, c := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 5 {
+				msg := &duml.Message{
+					Interface: duml.InterfaceIDAppToCamera,
+					Type:      duml.MessageTypeGetSerialNum,
+				}
+				resp, err := c.Request(ctx, msg, duml.RequestTimeout(time.Second))
+				switch {
+				case err != nil:
+					errs <- err
+				case resp.ID != msg.ID:
+					errs <- fmt.Errorf("the response ID %d does not match the request ID %d", resp.ID, msg.ID)
+				case !bytes.Equal(resp.Payload, serialNumber):
+					errs <- fmt.Errorf("unexpected payload %X", resp.Payload)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	seen := map[duml.MessageID]struct{}{}
+	for _, msg := range camera.State().Received {
+		if msg.Type == duml.MessageTypeGetSerialNum {
+			seen[msg.ID] = struct{}{}
+		}
+	}
+	require.Len(t, seen, 10, "the IDs of the forwarded requests must be unique")
+
+	// the pushes of the device are delivered to every client
+	for _, c := range clients {
+		sub := c.Subscribe(ctx, duml.FilterType(duml.MessageTypeBatteryStatus))
+		_, err := sub.Receive(ctx)
+		require.NoError(t, err)
+		sub.Close()
+	}
+
+	require.NoError(t, clients[0].Close())
+	require.Eventually(t, func() bool {
+		return srv.NumClients() == 1
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	require.ErrorIs(t, <-serveErr, context.Canceled)
+}
+
+func TestParseAddr(t *testing.T) {
+	for _, tc := range []struct {
+		Addr    string
+		Network string
+		Address string
+		IsError bool
+	}{
+		{Addr: "tcp:127.0.0.1:9005", Network: "tcp", Address: "127.0.0.1:9005"},
+		{Addr: "unix:/tmp/djictl.sock", Network: "unix", Address: "/tmp/djictl.sock"},
+		{Addr: "localhost:9005", Network: "tcp", Address: "localhost:9005"},
+		{Addr: "unix:", IsError: true},
+		{Addr: "/tmp/djictl.sock", IsError: true},
+	} {
+		t.Run(tc.Addr, func(t *testing.T) {
+			network, address, err := ParseAddr(tc.Addr)
+			if tc.IsError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Network, network)
+			require.Equal(t, tc.Address, address)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package djiemu
+
+func must[T any](in T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return in
+}
@@ -0,0 +1,120 @@
+package djiemu
+
+import (
+	"context"
+	"sync"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/djictl/pkg/djible"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+	"github.com/xaionaro-go/gatt"
+	"github.com/xaionaro-go/xsync"
+)
+
+// ServiceUUID is the UUID of the GATT service of the emulated device.
+var ServiceUUID = gatt.MustParseUUID("0000fff0-0000-1000-8000-00805f9b34fb")
+
+// NewBLEDevice returns an in-memory gatt.Device (to be used with djible.ScanWithDevice)
+// advertising the Camera and serving the DUML characteristics.
+//
+// The battery pushes start when the app subscribes to the notifications and
+// stop when the context is cancelled or the BLEDevice is closed.
+func (c *Camera) NewBLEDevice(ctx context.Context) *BLEDevice {
+	return NewBLEDevice(ctx, c)
+}
+
+// BLEDevice is an in-memory gatt.Device serving a Device, see NewBLEDevice.
+type BLEDevice struct {
+	gatt.Device
+
+	cancel        context.CancelFunc
+	pushesLocker  sync.Mutex
+	pushesStarted bool
+	pushes        sync.WaitGroup
+}
+
+// Close stops Device.RunPushes and waits for it to return.
+func (d *BLEDevice) Close() error {
+	d.cancel()
+	d.pushesLocker.Lock()
+	defer d.pushesLocker.Unlock()
+	d.pushes.Wait()
+	return nil
+}
+
+// startPushes starts Device.RunPushes once, unless the BLEDevice is closed.
+func (d *BLEDevice) startPushes(ctx context.Context, run func(ctx context.Context)) {
+	d.pushesLocker.Lock()
+	defer d.pushesLocker.Unlock()
+	if d.pushesStarted || ctx.Err() != nil {
+		return
+	}
+	d.pushesStarted = true
+	d.pushes.Add(1)
+	go func() {
+		defer d.pushes.Done()
+		run(ctx)
+	}()
+}
+
+// advertiser is implemented by the devices advertising more than the device type.
+type advertiser interface {
+	AdvertisementData() duml.AdvertisementData
+}
+
+// NewBLEDevice returns an in-memory gatt.Device (to be used with djible.ScanWithDevice)
+// advertising the Device and serving the DUML characteristics.
+//
+// If the Device has the AdvertisementData method (as Camera), it is advertised
+// instead of the bare device type.
+//
+// Device.RunPushes is started when the app subscribes to the notifications and
+// stops when the context is cancelled or the BLEDevice is closed.
+func NewBLEDevice(ctx context.Context, d Device) *BLEDevice {
+	ctx, cancel := context.WithCancel(ctx)
+	svc := gatt.NewService(ServiceUUID)
+	dev := gatt.NewSimDeviceClient(svc, d.DeviceName())
+	result := &BLEDevice{Device: dev, cancel: cancel}
+	if a, ok := d.(advertiser); ok {
+		dev.SetManufacturerData(a.AdvertisementData().Bytes())
+	} else {
+		dev.SetManufacturerData(d.DeviceType().ManufacturerData())
+	}
+
+	send := func(ctx context.Context, msg *duml.Message) error {
+		dev.SendNotification(djible.CharacteristicIDReceiver, msg.Bytes())
+		return nil
+	}
+
+	receiver := svc.AddCharacteristic(gatt.UUID16(djible.CharacteristicIDReceiver))
+	receiver.SetVHandle(djible.CharacteristicIDReceiver)
+	receiver.HandleNotifyFunc(func(_ context.Context, _ gatt.Request, _ gatt.Notifier) {
+		logger.Debugf(ctx, "the app subscribed to the notifications")
+		result.startPushes(ctx, func(ctx context.Context) {
+			d.RunPushes(ctx, send)
+		})
+	})
+
+	var decoderLocker xsync.Mutex
+	decoder := duml.NewDecoder()
+	sender := svc.AddCharacteristic(gatt.UUID16(djible.CharacteristicIDSender))
+	sender.SetVHandle(djible.CharacteristicIDSender)
+	sender.HandleWriteFunc(func(reqCtx context.Context, _ gatt.Request, b []byte) byte {
+		msgs := xsync.DoR1(ctx, &decoderLocker, func() []*duml.Message {
+			return decoder.Decode(b)
+		})
+		for _, msg := range msgs {
+			d.Handle(ctx, msg, send)
+		}
+		return gatt.StatusSuccess
+	})
+
+	pairingRequestor := svc.AddCharacteristic(gatt.UUID16(djible.CharacteristicIDPairingRequestor))
+	pairingRequestor.SetVHandle(djible.CharacteristicIDPairingRequestor)
+	pairingRequestor.HandleWriteFunc(func(_ context.Context, _ gatt.Request, b []byte) byte {
+		d.HandlePairingRequest(ctx, b, send)
+		return gatt.StatusSuccess
+	})
+
+	return result
+}
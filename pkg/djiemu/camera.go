@@ -0,0 +1,639 @@
+// Package djiemu implements a virtual DJI camera: the device side of the DUML
+// protocol, so that the flows of djictl could be tested without hardware.
+//
+// The emulator (or any other Device) is attachable to an in-memory
+// gatt.Device (see NewBLEDevice) and to a UDP socket (see ServeUDP).
+package djiemu
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+	"github.com/xaionaro-go/xsync"
+)
+
+const (
+	// DefaultBatteryPushInterval is the default period of MessageTypeBatteryStatus pushes.
+	DefaultBatteryPushInterval = time.Second
+
+	// DefaultBatteryCapacity is the initial battery capacity of a Camera.
+	DefaultBatteryCapacity = duml.BatteryCapacity(87)
+)
+
+// SendFunc sends a message from the emulated device to the app.
+type SendFunc func(ctx context.Context, msg *duml.Message) error
+
+// Camera is a virtual DJI camera.
+//
+// The configuration fields are expected to be set before attaching the Camera
+// to a transport; the dynamic state is available through State.
+type Camera struct {
+	Type duml.DeviceType
+	Name string
+
+	// AlreadyPaired makes the camera report it is already paired on MessageTypeSetPairingPIN.
+	AlreadyPaired bool
+
+	// AdvertisementStatus is advertised as is (see AdvertisementData).
+	AdvertisementStatus duml.AdvertisementStatus
+
+	// PINApprovalDelay is the delay between MessageTypeSetPairingPIN and the
+	// MessageTypePairingPINApproved push (emulating the user approving the PIN on the device).
+	PINApprovalDelay time.Duration
+
+	// BatteryPushInterval is the period of MessageTypeBatteryStatus pushes (and of
+	// MessageTypeUnknown0MaybeStatus pushes, if the model has a gimbal).
+	BatteryPushInterval time.Duration
+
+	// CameraAPSSID and CameraAPPSK are returned on MessageTypeCameraAPInfo.
+	CameraAPSSID string
+	CameraAPPSK  string
+
+	// AcceptWiFi decides if connecting to the WiFi network succeeds; nil accepts any network.
+	AcceptWiFi func(ssid, psk string) bool
+
+	// FirmwareVersion, SerialNumber and ProductID are returned on
+	// MessageTypeGetVersion, MessageTypeGetSerialNum and MessageTypeGetProductID.
+	FirmwareVersion duml.FirmwareVersion
+	SerialNumber    string
+	ProductID       string
+
+	// Parameters are pushed (MessageTypeParameterPush) once to a newly connected app.
+	Parameters []duml.ParameterEntry
+
+	sequencer   *duml.Sequencer
+	stateLocker xsync.Mutex
+	state       State
+}
+
+// State is the dynamic state of a Camera.
+type State struct {
+	BatteryCapacity duml.BatteryCapacity
+	BatteryCharging bool
+	PairingRequests int
+	PairingPIN      string
+	Paired          bool
+	WiFiSSID        string
+	WiFiPSK         string
+	WiFiConnected   bool
+	LiveStream      *duml.LiveStreamConfig
+	Streaming       bool
+	Recording       bool
+	PhotosTaken     int
+	Settings        map[duml.KeyValueKey][]byte
+	Gimbal          duml.GimbalStatus
+
+	// RecordingStartedAt is the time the current recording started (if Recording).
+	RecordingStartedAt time.Time
+
+	// Received are all the DUML messages received from the app.
+	Received []*duml.Message
+}
+
+// NewCamera returns a Camera emulating the given device type.
+func NewCamera(typ duml.DeviceType) *Camera {
+	return &Camera{
+		Type:                typ,
+		Name:                fmt.Sprintf("DJI %s (emulated)", typ),
+		BatteryPushInterval: DefaultBatteryPushInterval,
+		CameraAPSSID:        "OsmoEmulator-0000",
+		CameraAPPSK:         "12345678",
+		FirmwareVersion:     duml.NewFirmwareVersion(1, 0, 0, 0),
+		SerialNumber:        "EMU0000000000",
+		ProductID:           typ.String(),
+		Parameters: []duml.ParameterEntry{
+			{Name: "product_shielded_config", Value: duml.ParameterValue{0x00, 0x00, 0x00, 0x00}},
+		},
+		sequencer: duml.NewSequencer(1),
+		state: State{
+			BatteryCapacity: DefaultBatteryCapacity,
+			Settings:        defaultSettings(typ),
+		},
+	}
+}
+
+var _ Device = (*Camera)(nil)
+
+// defaultSettings returns the initial values of the settings of the camera.
+func defaultSettings(typ duml.DeviceType) map[duml.KeyValueKey][]byte {
+	settings := map[duml.KeyValueKey][]byte{}
+	if b, err := typ.EncodeCameraMode(duml.CameraModeVideo); err == nil {
+		settings[duml.KeyValueKeyCameraMode] = []byte{b}
+	}
+	if b, err := typ.EncodeVideoFormat(duml.VideoFormat{
+		Resolution:  duml.VideoResolution1080p,
+		FPS:         30,
+		AspectRatio: duml.AspectRatio16x9,
+		Orientation: duml.OrientationLandscape,
+	}); err == nil {
+		settings[duml.KeyValueKeyVideoFormat] = b
+	}
+	ev := duml.EVCompensation(0)
+	colorProfile := duml.ColorProfileNormal
+	exposure := duml.ExposureSettings{
+		EV:           &ev,
+		ISO:          &duml.ISOSetting{Mode: duml.ISOModeAuto},
+		Shutter:      &duml.ShutterSetting{Mode: duml.ShutterModeAuto},
+		WhiteBalance: &duml.WhiteBalance{Mode: duml.WhiteBalanceModeAuto},
+		ColorProfile: &colorProfile,
+	}
+	for _, item := range must(exposure.Items()) {
+		settings[item.Key] = item.Value
+	}
+	return settings
+}
+
+// DeviceType implements Device.
+func (c *Camera) DeviceType() duml.DeviceType {
+	return c.Type
+}
+
+// DeviceName implements Device.
+func (c *Camera) DeviceName() string {
+	return c.Name
+}
+
+// State returns a copy of the current state.
+func (c *Camera) State() State {
+	return xsync.DoR1(context.Background(), &c.stateLocker, func() State {
+		s := c.state
+		s.Settings = make(map[duml.KeyValueKey][]byte, len(c.state.Settings))
+		for k, v := range c.state.Settings {
+			s.Settings[k] = slices.Clone(v)
+		}
+		s.Received = slices.Clone(c.state.Received)
+		if c.state.LiveStream != nil {
+			cfg := *c.state.LiveStream
+			s.LiveStream = &cfg
+		}
+		return s
+	})
+}
+
+// SetBatteryCapacity sets the capacity reported in the next battery pushes.
+func (c *Camera) SetBatteryCapacity(capacity duml.BatteryCapacity) {
+	c.stateLocker.Do(context.Background(), func() {
+		c.state.BatteryCapacity = capacity
+	})
+}
+
+// SetBatteryCharging sets whether the next battery pushes report the battery as charging.
+func (c *Camera) SetBatteryCharging(charging bool) {
+	c.stateLocker.Do(context.Background(), func() {
+		c.state.BatteryCharging = charging
+	})
+}
+
+// AdvertisementData returns the BLE advertisement manufacturer data (see NewBLEDevice).
+//
+// The status byte is the fixed AdvertisementStatus: it does not follow the
+// state, since the meaning of its bits is not known.
+func (c *Camera) AdvertisementData() duml.AdvertisementData {
+	return duml.AdvertisementData{
+		DeviceType: c.Type,
+		Status:     c.AdvertisementStatus,
+		HasVariant: true,
+		HasStatus:  true,
+	}
+}
+
+func (c *Camera) updateState(ctx context.Context, fn func(s *State)) {
+	c.stateLocker.Do(ctx, func() {
+		fn(&c.state)
+	})
+}
+
+// HandlePairingRequest handles a write to the BLE pairing requestor characteristic.
+func (c *Camera) HandlePairingRequest(ctx context.Context, b []byte, _ SendFunc) {
+	logger.Debugf(ctx, "received a pairing request: %X", b)
+	c.updateState(ctx, func(s *State) {
+		s.PairingRequests++
+	})
+}
+
+// BatteryStatusMessage returns the battery push with the current capacity and charging state.
+func (c *Camera) BatteryStatusMessage() *duml.Message {
+	status := xsync.DoR1(context.Background(), &c.stateLocker, func() *duml.BatteryStatus {
+		status := &duml.BatteryStatus{
+			DeviceType:             c.Type,
+			Capacity:               c.state.BatteryCapacity,
+			Voltage:                3850,
+			Current:                -600,
+			Temperature:            315,
+			ChargingState:          duml.BatteryChargingStateDischarging,
+			RemainingRecordingTime: 3600,
+			CellCount:              1,
+			Health:                 100,
+		}
+		if c.state.BatteryCharging {
+			status.Current = 1200
+			status.ChargingState = duml.BatteryChargingStateCharging
+		}
+		return status
+	})
+	msg := must(duml.NewMessage(
+		duml.InterfaceID{Sender: duml.ComponentIDBattery, Receiver: duml.ComponentIDApp},
+		c.sequencer.Next(),
+		duml.MessageTypeBatteryStatus,
+		status,
+	))
+	return msg
+}
+
+// RunPushes implements Device: it pushes the parameters and runs the status pushes.
+func (c *Camera) RunPushes(ctx context.Context, send SendFunc) {
+	if len(c.Parameters) > 0 {
+		msg := must(duml.NewMessage(
+			duml.InterfaceID{Sender: duml.ComponentIDCamera, Receiver: duml.ComponentIDApp},
+			c.sequencer.Next(),
+			duml.MessageTypeParameterPush,
+			&duml.ParameterPush{Entries: c.Parameters},
+		))
+		if err := send(ctx, msg); err != nil {
+			logger.Debugf(ctx, "unable to send the parameters: %v", err)
+			return
+		}
+	}
+	c.RunStatusPushes(ctx, send)
+}
+
+// RunStatusPushes sends the battery status (and the gimbal status, if the model
+// has a gimbal) immediately and then every BatteryPushInterval until the context
+// is cancelled or sending fails.
+func (c *Camera) RunStatusPushes(ctx context.Context, send SendFunc) {
+	interval := c.BatteryPushInterval
+	if interval <= 0 {
+		interval = DefaultBatteryPushInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := send(ctx, c.BatteryStatusMessage()); err != nil {
+			logger.Debugf(ctx, "unable to send the battery status: %v", err)
+			return
+		}
+		if c.hasGimbal() {
+			if err := send(ctx, c.GimbalStatusMessage()); err != nil {
+				logger.Debugf(ctx, "unable to send the gimbal status: %v", err)
+				return
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func isCommand(msg *duml.Message, t duml.MessageType) bool {
+	return msg.Type.CmdSet == t.CmdSet && msg.Type.CmdID == t.CmdID
+}
+
+// reply returns a message sent back to the sender of req with the same ID.
+func reply(req *duml.Message, t duml.MessageType, payload []byte) *duml.Message {
+	return &duml.Message{
+		Interface: duml.InterfaceID{Sender: req.Interface.Receiver, Receiver: req.Interface.Sender},
+		ID:        req.ID,
+		Type:      t,
+		Payload:   payload,
+	}
+}
+
+// push returns a new message sent back to the sender of req with a new ID.
+func (c *Camera) push(req *duml.Message, t duml.MessageType, payload []byte) *duml.Message {
+	msg := reply(req, t, payload)
+	msg.ID = c.sequencer.Next()
+	return msg
+}
+
+// Handle processes a message received from the app and sends the replies
+// (and the pushes caused by the message) using the given function.
+func (c *Camera) Handle(
+	ctx context.Context,
+	msg *duml.Message,
+	send SendFunc,
+) {
+	logger.Debugf(ctx, "emulator received: %s", msg)
+	c.updateState(ctx, func(s *State) {
+		s.Received = append(s.Received, msg)
+	})
+
+	if msg.Type.Flags&duml.MessageTypeFlagAckRequired == 0 {
+		// an ACK or a notification, nothing to reply
+		return
+	}
+
+	for _, out := range c.replies(ctx, msg, send) {
+		if err := send(ctx, out); err != nil {
+			logger.Errorf(ctx, "unable to send %s: %v", out.Type, err)
+			return
+		}
+	}
+}
+
+func (c *Camera) replies(
+	ctx context.Context,
+	msg *duml.Message,
+	send SendFunc,
+) []*duml.Message {
+	switch {
+	case isCommand(msg, duml.MessageTypeSetPairingPIN):
+		return c.handleSetPairingPIN(ctx, msg, send)
+
+	case isCommand(msg, duml.MessageTypePairingPINApproved):
+		// the app confirms the approval ("pairing stage1")
+		return []*duml.Message{reply(msg, duml.MessageTypeResponse(msg.Type.CmdSet, msg.Type.CmdID), []byte{0x00})}
+
+	case isCommand(msg, duml.MessageTypePairingStage2):
+		c.updateState(ctx, func(s *State) {
+			s.Paired = true
+		})
+		return []*duml.Message{reply(msg, duml.MessageTypeResponse(msg.Type.CmdSet, msg.Type.CmdID), []byte{0x00})}
+
+	case isCommand(msg, duml.MessageTypePrepareToLiveStream):
+		return []*duml.Message{reply(msg, duml.MessageTypePrepareToLiveStreamResult, []byte{0x00})}
+
+	case isCommand(msg, duml.MessageTypeStartStopStreaming):
+		return c.handleKeyValue(ctx, msg)
+
+	case isCommand(msg, duml.MessageTypeConfigureStreaming):
+		var cfg duml.LiveStreamConfig
+		if err := cfg.UnmarshalDUML(msg.Payload); err != nil {
+			logger.Warnf(ctx, "unable to parse the live stream config: %v", err)
+			return []*duml.Message{reply(msg, duml.MessageTypeConfigureStreamingResult, []byte{0x01})}
+		}
+		c.updateState(ctx, func(s *State) {
+			s.LiveStream = &cfg
+		})
+		return []*duml.Message{reply(msg, duml.MessageTypeConfigureStreamingResult, []byte{0x00})}
+
+	case isCommand(msg, duml.MessageTypeConnectToWiFi):
+		return c.handleConnectToWiFi(ctx, msg)
+
+	case isCommand(msg, duml.MessageTypeTakeRecord):
+		return c.handleTakeRecord(ctx, msg)
+
+	case isCommand(msg, duml.MessageTypeGimbalSetMode):
+		return c.handleGimbalSetMode(ctx, msg)
+
+	case isCommand(msg, duml.MessageTypeGimbalMove):
+		return c.handleGimbalMove(ctx, msg)
+
+	case isCommand(msg, duml.MessageTypeStartScanningWiFi):
+		return []*duml.Message{reply(msg, duml.MessageTypeStartScanningWiFiResult, []byte{0x00})}
+
+	case isCommand(msg, duml.MessageTypeCameraAPInfo):
+		return []*duml.Message{
+			reply(msg, duml.MessageTypeCameraAPInfoResultSSID, must((&duml.CameraAPInfoResult{Value: c.CameraAPSSID}).MarshalDUML())),
+			c.push(msg, duml.MessageTypeCameraAPInfoResultPSK, must((&duml.CameraAPInfoResult{Value: c.CameraAPPSK}).MarshalDUML())),
+		}
+
+	case isCommand(msg, duml.MessageTypeGetBatteryInfo):
+		return []*duml.Message{
+			reply(msg, duml.MessageTypeResponse(msg.Type.CmdSet, msg.Type.CmdID), []byte{0x00}),
+			c.BatteryStatusMessage(),
+		}
+
+	case isCommand(msg, duml.MessageTypeGetVersion):
+		return []*duml.Message{reply(msg, duml.MessageTypeGetVersionResult, must((&duml.VersionInfo{
+			HardwareVersion: "emulator",
+			LoaderVersion:   c.FirmwareVersion,
+			FirmwareVersion: c.FirmwareVersion,
+		}).MarshalDUML()))}
+
+	case isCommand(msg, duml.MessageTypeGetSerialNum):
+		return []*duml.Message{reply(msg, duml.MessageTypeGetSerialNumResult, must((&duml.StringResult{Value: c.SerialNumber}).MarshalDUML()))}
+
+	case isCommand(msg, duml.MessageTypeGetProductID):
+		return []*duml.Message{reply(msg, duml.MessageTypeGetProductIDResult, must((&duml.StringResult{Value: c.ProductID}).MarshalDUML()))}
+
+	default:
+		return []*duml.Message{reply(msg, duml.MessageTypeResponse(msg.Type.CmdSet, msg.Type.CmdID), []byte{0x00})}
+	}
+}
+
+func (c *Camera) handleSetPairingPIN(
+	ctx context.Context,
+	msg *duml.Message,
+	send SendFunc,
+) []*duml.Message {
+	var req duml.SetPairingPINRequest
+	if err := req.UnmarshalDUML(msg.Payload); err != nil {
+		logger.Warnf(ctx, "unable to parse the pairing request: %v", err)
+		return []*duml.Message{reply(msg, duml.MessageTypePairingStatus, []byte{0x01, 0x00})}
+	}
+
+	paired := c.AlreadyPaired || xsync.DoR1(ctx, &c.stateLocker, func() bool {
+		c.state.PairingPIN = req.PIN
+		return c.state.Paired
+	})
+	state := duml.PairingStateNotPaired
+	if paired {
+		state = duml.PairingStateAlreadyPaired
+	}
+	result := []*duml.Message{
+		reply(msg, duml.MessageTypePairingStatus, must((&duml.PairingStatus{State: state}).MarshalDUML())),
+	}
+	if paired {
+		return result
+	}
+
+	approved := c.push(msg, duml.MessageTypePairingPINApproved, []byte{0x00})
+	if c.PINApprovalDelay > 0 {
+		go func() {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.PINApprovalDelay):
+			}
+			if err := send(ctx, approved); err != nil {
+				logger.Errorf(ctx, "unable to send %s: %v", approved.Type, err)
+			}
+		}()
+		return result
+	}
+	return append(result, approved)
+}
+
+func (c *Camera) handleKeyValue(
+	ctx context.Context,
+	msg *duml.Message,
+) []*duml.Message {
+	var req duml.KeyValueRequest
+	if err := req.UnmarshalDUML(msg.Payload); err != nil {
+		logger.Warnf(ctx, "unable to parse the key-value request: %v", err)
+		return []*duml.Message{reply(msg, duml.MessageTypeStartStopStreamingResult, []byte{0x01})}
+	}
+	if req.Op == duml.KeyValueOpSet {
+		c.updateState(ctx, func(s *State) {
+			for _, item := range req.Items {
+				s.Settings[item.Key] = slices.Clone(item.Value)
+				if item.Key == duml.KeyValueKeyLiveStream && len(item.Value) == 1 {
+					switch item.Value[0] {
+					case 0x01:
+						s.Streaming = true
+					case 0x02:
+						s.Streaming = false
+					}
+				}
+			}
+		})
+	}
+
+	// reporting the current values of the keys back
+	var push duml.KeyValuePush
+	c.stateLocker.Do(ctx, func() {
+		for _, item := range req.Items {
+			if v, ok := c.state.Settings[item.Key]; ok {
+				push.Items = append(push.Items, duml.KeyValueItem{Key: item.Key, Value: slices.Clone(v)})
+			}
+		}
+	})
+	result := []*duml.Message{reply(msg, duml.MessageTypeStartStopStreamingResult, []byte{0x00})}
+	if len(push.Items) > 0 {
+		result = append(result, c.push(msg, duml.MessageTypeKeyValuePush, must(push.MarshalDUML())))
+	}
+	return result
+}
+
+// RecordingStatusMessage returns the recording status push with the current state.
+func (c *Camera) RecordingStatusMessage() *duml.Message {
+	status := xsync.DoR1(context.Background(), &c.stateLocker, func() *duml.RecordingStatus {
+		status := &duml.RecordingStatus{Recording: c.state.Recording}
+		if c.state.Recording {
+			status.Duration = duml.Seconds(time.Since(c.state.RecordingStartedAt) / time.Second)
+		}
+		return status
+	})
+	return must(duml.NewMessage(
+		duml.InterfaceID{Sender: duml.ComponentIDCamera, Receiver: duml.ComponentIDApp},
+		c.sequencer.Next(),
+		duml.MessageTypeRecordingStatus,
+		status,
+	))
+}
+
+func (c *Camera) handleTakeRecord(
+	ctx context.Context,
+	msg *duml.Message,
+) []*duml.Message {
+	var req duml.TakeRecordRequest
+	if err := req.UnmarshalDUML(msg.Payload); err != nil {
+		logger.Warnf(ctx, "unable to parse the record request: %v", err)
+		return []*duml.Message{reply(msg, duml.MessageTypeTakeRecordResult, []byte{0x01})}
+	}
+	switch req.Action {
+	case duml.RecordActionStart, duml.RecordActionStop:
+		recording := req.Action == duml.RecordActionStart
+		c.updateState(ctx, func(s *State) {
+			if recording && !s.Recording {
+				s.RecordingStartedAt = time.Now()
+			}
+			s.Recording = recording
+		})
+		return []*duml.Message{
+			reply(msg, duml.MessageTypeTakeRecordResult, []byte{0x00}),
+			c.RecordingStatusMessage(),
+		}
+	case duml.RecordActionTakePhoto:
+		c.updateState(ctx, func(s *State) {
+			s.PhotosTaken++
+		})
+		return []*duml.Message{reply(msg, duml.MessageTypeTakeRecordResult, []byte{0x00})}
+	default:
+		// the failure code is experimental (see duml.KeyValueKey.IsExperimental)
+		return []*duml.Message{reply(msg, duml.MessageTypeTakeRecordResult, []byte{0x01})}
+	}
+}
+
+func (c *Camera) hasGimbal() bool {
+	p, _ := c.Type.Profile()
+	return p.Gimbal
+}
+
+// GimbalStatusMessage returns the gimbal status push with the current attitude and mode.
+func (c *Camera) GimbalStatusMessage() *duml.Message {
+	status := xsync.DoR1(context.Background(), &c.stateLocker, func() duml.GimbalStatus {
+		return c.state.Gimbal
+	})
+	return must(duml.NewMessage(
+		duml.InterfaceID{Sender: duml.ComponentIDGimbal, Receiver: duml.ComponentIDApp},
+		c.sequencer.Next(),
+		duml.MessageTypeUnknown0MaybeStatus,
+		&status,
+	))
+}
+
+func (c *Camera) handleGimbalSetMode(
+	ctx context.Context,
+	msg *duml.Message,
+) []*duml.Message {
+	// the failure code is experimental
+	var req duml.GimbalSetModeRequest
+	if err := req.UnmarshalDUML(msg.Payload); err != nil || !c.hasGimbal() {
+		logger.Warnf(ctx, "unable to handle the gimbal mode request %X (has gimbal: %t): %v", msg.Payload, c.hasGimbal(), err)
+		return []*duml.Message{reply(msg, duml.MessageTypeGimbalSetModeResult, []byte{0x01})}
+	}
+	switch req.Mode {
+	case duml.GimbalModeFollow, duml.GimbalModeTiltLocked, duml.GimbalModeFPV, duml.GimbalModeKeep:
+	default:
+		return []*duml.Message{reply(msg, duml.MessageTypeGimbalSetModeResult, []byte{0x01})}
+	}
+	c.updateState(ctx, func(s *State) {
+		if req.Mode != duml.GimbalModeKeep {
+			s.Gimbal.Mode = req.Mode
+		}
+		if req.Recenter {
+			s.Gimbal.Attitude = duml.GimbalAttitude{}
+		}
+	})
+	return []*duml.Message{
+		reply(msg, duml.MessageTypeGimbalSetModeResult, []byte{0x00}),
+		c.GimbalStatusMessage(),
+	}
+}
+
+func (c *Camera) handleGimbalMove(
+	ctx context.Context,
+	msg *duml.Message,
+) []*duml.Message {
+	var req duml.GimbalMoveRequest
+	if err := req.UnmarshalDUML(msg.Payload); err != nil || !c.hasGimbal() {
+		logger.Warnf(ctx, "unable to handle the gimbal move request %X (has gimbal: %t): %v", msg.Payload, c.hasGimbal(), err)
+		return []*duml.Message{reply(msg, duml.MessageTypeGimbalMoveResult, []byte{0x01})}
+	}
+	// the movement is instant, req.Duration is ignored
+	c.updateState(ctx, func(s *State) {
+		s.Gimbal.Attitude = req.Apply(s.Gimbal.Attitude)
+	})
+	return []*duml.Message{
+		reply(msg, duml.MessageTypeGimbalMoveResult, []byte{0x00}),
+		c.GimbalStatusMessage(),
+	}
+}
+
+func (c *Camera) handleConnectToWiFi(
+	ctx context.Context,
+	msg *duml.Message,
+) []*duml.Message {
+	var req duml.ConnectToWiFiRequest
+	if err := req.UnmarshalDUML(msg.Payload); err != nil {
+		logger.Warnf(ctx, "unable to parse the WiFi credentials: %v", err)
+		return []*duml.Message{reply(msg, duml.MessageTypeConnectToWiFiResult, []byte{0x01})}
+	}
+	accepted := c.AcceptWiFi == nil || c.AcceptWiFi(req.SSID, req.PSK)
+	c.updateState(ctx, func(s *State) {
+		s.WiFiSSID, s.WiFiPSK = req.SSID, req.PSK
+		s.WiFiConnected = accepted
+	})
+	if !accepted {
+		// the failure code is experimental
+		return []*duml.Message{reply(msg, duml.MessageTypeConnectToWiFiResult, []byte{0x00, 0x01})}
+	}
+	return []*duml.Message{reply(msg, duml.MessageTypeConnectToWiFiResult, []byte{0x00, 0x00})}
+}
@@ -0,0 +1,130 @@
+package djiemu
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/djictl/pkg/djiapi"
+	"github.com/xaionaro-go/djictl/pkg/djible"
+	"github.com/xaionaro-go/djictl/pkg/djiwifi"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+func TestCameraBLE(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	camera := NewCamera(duml.DeviceTypeOsmoAction4)
+	camera.PINApprovalDelay = 10 * time.Millisecond
+	camera.BatteryPushInterval = 10 * time.Millisecond
+
+	bleDev := camera.NewBLEDevice(ctx)
+	defer bleDev.Close()
+	devCh, errCh, err := djible.ScanWithDevice(ctx, bleDev)
+	require.NoError(t, err)
+	var dev *djible.Device
+	select {
+	case dev = <-devCh:
+	case err := <-errCh:
+		t.Fatal(err)
+	case <-ctx.Done():
+		t.Fatal("the emulated device was not found")
+	}
+	require.Equal(t, duml.DeviceTypeOsmoAction4, dev.Type)
+
+	require.NoError(t, dev.Init(ctx))
+	require.NoError(t, dev.AppToWiFiGroundStation().Pair(ctx))
+	state := camera.State()
+	require.True(t, state.Paired)
+	require.Equal(t, 1, state.PairingRequests)
+	require.NotEmpty(t, state.PairingPIN)
+
+	// pairing again is a no-op
+	require.NoError(t, dev.AppToWiFiGroundStation().Pair(ctx))
+
+	ssid, psk, err := dev.AppToWiFiGroundStation().CameraAPInfo(ctx)
+	require.NoError(t, err)
+	require.Equal(t, camera.CameraAPSSID, ssid)
+	require.Equal(t, camera.CameraAPPSK, psk)
+
+	require.NoError(t, dev.AppToVideoTransmission().PrepareToLiveStream(ctx))
+	require.NoError(t, dev.AppToWiFiGroundStation().ConnectToWiFi(ctx, "test-ssid", "test-psk"))
+	state = camera.State()
+	require.True(t, state.WiFiConnected)
+	require.Equal(t, "test-ssid", state.WiFiSSID)
+	require.Equal(t, "test-psk", state.WiFiPSK)
+
+	streamCtx, streamCancel := context.WithCancel(ctx)
+	streamErr := make(chan error, 1)
+	go func() {
+		streamErr <- dev.AppToVideoTransmission().LiveStream(streamCtx, duml.Resolution720p, 4000, duml.FPS30, "rtmp://test/live")
+	}()
+	require.Eventually(t, func() bool { return camera.State().Streaming }, 5*time.Second, time.Millisecond)
+	streamCancel()
+	require.ErrorIs(t, <-streamErr, context.Canceled)
+
+	state = camera.State()
+	require.NotNil(t, state.LiveStream)
+	require.Equal(t, "rtmp://test/live", state.LiveStream.URL)
+	require.Equal(t, duml.Resolution720p, state.LiveStream.Resolution)
+	require.Equal(t, uint16(4000), state.LiveStream.BitrateKbps)
+
+	require.NoError(t, dev.AppToVideoTransmission().StopLiveStream(ctx))
+	require.False(t, camera.State().Streaming)
+
+	camera.SetBatteryCapacity(42)
+	status, err := dev.AppToBattery().GetInfo(ctx)
+	require.NoError(t, err)
+	require.Equal(t, duml.BatteryCapacity(42), status.Capacity)
+}
+
+func TestCameraUDP(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	camera := NewCamera(duml.DeviceTypeOsmoPocket3)
+	camera.AcceptWiFi = func(ssid, psk string) bool {
+		return psk == "correct"
+	}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- camera.ServeUDP(ctx, conn) }()
+
+	ctrl, err := djiwifi.NewController(ctx, conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer ctrl.Close()
+	require.NoError(t, ctrl.SendHandshake(ctx))
+
+	status, err := djiapi.AppToBattery(ctrl).GetInfo(ctx)
+	require.NoError(t, err)
+	require.Equal(t, DefaultBatteryCapacity, status.Capacity)
+
+	err = djiapi.AppToWiFiGroundStation(ctrl).ConnectToWiFi(ctx, "test-ssid", "wrong")
+	var rejected *duml.RejectedError
+	require.ErrorAs(t, err, &rejected)
+	require.False(t, camera.State().WiFiConnected)
+	require.NoError(t, djiapi.AppToWiFiGroundStation(ctrl).ConnectToWiFi(ctx, "test-ssid", "correct"))
+	require.True(t, camera.State().WiFiConnected)
+
+	_, err = djiapi.AppToCamera(ctrl).StartRecording(ctx)
+	require.ErrorIs(t, err, djiapi.ErrExperimental)
+	require.False(t, camera.State().Recording)
+	recordingStatus, err := djiapi.AppToCamera(ctrl).WithExperimental().StartRecording(ctx)
+	require.NoError(t, err)
+	require.True(t, recordingStatus.Recording)
+	require.True(t, camera.State().Recording)
+	require.NoError(t, djiapi.AppToCamera(ctrl).WithExperimental().TakePhoto(ctx))
+	require.Equal(t, 1, camera.State().PhotosTaken)
+	recordingStatus, err = djiapi.AppToCamera(ctrl).WithExperimental().StopRecording(ctx)
+	require.NoError(t, err)
+	require.False(t, recordingStatus.Recording)
+	require.False(t, camera.State().Recording)
+
+	cancel()
+	require.ErrorIs(t, <-serveErr, context.Canceled)
+}
@@ -0,0 +1,26 @@
+package djiemu
+
+import (
+	"context"
+
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// Device is the device side of the protocol, as served by NewBLEDevice and ServeUDP.
+//
+// Camera is the stateful emulator; other implementations may, for example,
+// replay a recorded session.
+type Device interface {
+	DeviceType() duml.DeviceType
+	DeviceName() string
+
+	// RunPushes sends the unsolicited messages to a connected app
+	// until the context is cancelled.
+	RunPushes(ctx context.Context, send SendFunc)
+
+	// Handle processes a message received from the app and sends the replies.
+	Handle(ctx context.Context, msg *duml.Message, send SendFunc)
+
+	// HandlePairingRequest handles a write to the BLE pairing requestor characteristic.
+	HandlePairingRequest(ctx context.Context, b []byte, send SendFunc)
+}
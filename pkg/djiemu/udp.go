@@ -0,0 +1,87 @@
+package djiemu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/djictl/pkg/djiwifi"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// ServeUDP serves the Camera on the UDP socket (the WiFi transport, see package djiwifi)
+// until the context is cancelled. The socket is closed on return.
+//
+// The battery pushes are sent to each peer since its first packet.
+func (c *Camera) ServeUDP(
+	ctx context.Context,
+	conn net.PacketConn,
+) error {
+	return ServeUDP(ctx, conn, c)
+}
+
+// ServeUDP serves the Device on the UDP socket (the WiFi transport, see package djiwifi)
+// until the context is cancelled. The socket is closed on return.
+//
+// Device.RunPushes is started for each peer since its first packet.
+func ServeUDP(
+	ctx context.Context,
+	conn net.PacketConn,
+	d Device,
+) (_err error) {
+	logger.Tracef(ctx, "ServeUDP")
+	defer func() { logger.Tracef(ctx, "/ServeUDP: %v", _err) }()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	context.AfterFunc(ctx, func() { conn.Close() })
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	decoders := map[string]*duml.Decoder{}
+	buf := make([]byte, djiwifi.ReadBufferSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return ctx.Err()
+			}
+			return fmt.Errorf("unable to read: %w", err)
+		}
+
+		p, err := djiwifi.ParsePacket(append([]byte{}, buf[:n]...))
+		if err != nil {
+			logger.Debugf(ctx, "unable to parse a packet from %s: %v", addr, err)
+			continue
+		}
+
+		send := func(ctx context.Context, msg *duml.Message) error {
+			_, err := conn.WriteTo(djiwifi.NewDUMLPacket(msg, djiwifi.MetadataApp).Bytes(), addr)
+			return err
+		}
+
+		decoder := decoders[addr.String()]
+		if decoder == nil {
+			logger.Debugf(ctx, "new peer %s", addr)
+			decoder = duml.NewDecoder()
+			decoders[addr.String()] = decoder
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.RunPushes(ctx, send)
+			}()
+		}
+
+		if len(p.Payload) == 0 || p.Payload[0] != djiwifi.DUMLMagic {
+			logger.Debugf(ctx, "ignoring a non-DUML packet of type %s from %s", p.Type, addr)
+			continue
+		}
+		for _, msg := range decoder.Decode(p.Payload) {
+			d.Handle(ctx, msg, send)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package djirecord records the DUML sessions of djible.Device and
+// djiwifi.Controller as JSON-lines transcripts and replays them as a mocked
+// device (see Replayer), so that a session captured in the field could be
+// turned into a deterministic test.
+package djirecord
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/xaionaro-go/djictl/pkg/djiwifi"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// Transport is the transport a frame was sent or received over.
+type Transport string
+
+const (
+	TransportBLE  = Transport("ble")
+	TransportWiFi = Transport("wifi")
+)
+
+// HexBytes is a byte slice represented as a hex string in JSON.
+type HexBytes []byte
+
+// MarshalText implements encoding.TextMarshaler.
+func (b HexBytes) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(b)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *HexBytes) UnmarshalText(text []byte) error {
+	v, err := hex.DecodeString(string(text))
+	if err != nil {
+		return fmt.Errorf("unable to decode hex '%s': %w", text, err)
+	}
+	*b = v
+	return nil
+}
+
+// Entry is a single line of a transcript: one frame sent or received by the app.
+type Entry struct {
+	Time      time.Time      `json:"time"`
+	Direction duml.Direction `json:"direction"`
+	Transport Transport      `json:"transport"`
+
+	// Handle is the value handle of the BLE characteristic (TransportBLE only).
+	Handle uint16 `json:"handle,omitempty"`
+
+	// Metadata is the metadata of the djiwifi.Packet (TransportWiFi only).
+	Metadata HexBytes `json:"metadata,omitempty"`
+
+	// Frame is the characteristic value for TransportBLE and the whole
+	// djiwifi.Packet for TransportWiFi.
+	Frame HexBytes `json:"frame"`
+
+	// Messages are the human-readable DUML messages completed by this frame;
+	// informational only, it is ignored on replay.
+	Messages []string `json:"messages,omitempty"`
+}
+
+// dumlPayload returns the bytes of the frame that carry DUML messages (nil if none).
+func (e *Entry) dumlPayload() []byte {
+	if e.Transport != TransportWiFi {
+		return e.Frame
+	}
+	p, err := djiwifi.ParsePacket(e.Frame)
+	if err != nil || len(p.Payload) == 0 || p.Payload[0] != djiwifi.DUMLMagic {
+		return nil
+	}
+	return p.Payload
+}
+
+type streamKey struct {
+	Direction duml.Direction
+	Transport Transport
+	Handle    uint16
+}
+
+// entryDecoder reassembles the DUML messages of a transcript, independently per stream
+// (a BLE notification may carry a fragment of a message).
+type entryDecoder map[streamKey]*duml.Decoder
+
+// Decode returns the messages completed by the entry.
+func (d entryDecoder) Decode(e *Entry) []*duml.Message {
+	b := e.dumlPayload()
+	if len(b) == 0 {
+		return nil
+	}
+	key := streamKey{Direction: e.Direction, Transport: e.Transport, Handle: e.Handle}
+	decoder := d[key]
+	if decoder == nil {
+		decoder = duml.NewDecoder()
+		d[key] = decoder
+	}
+	return decoder.Decode(b)
+}
+
+// ReadEntries reads a transcript written by a Recorder.
+func ReadEntries(r io.Reader) ([]*Entry, error) {
+	var entries []*Entry
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var e Entry
+		err := decoder.Decode(&e)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return entries, nil
+			}
+			return nil, fmt.Errorf("unable to decode entry #%d: %w", len(entries), err)
+		}
+		entries = append(entries, &e)
+	}
+}
+
+// ReadFile reads a transcript file written by a Recorder.
+func ReadFile(path string) ([]*Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open '%s': %w", path, err)
+	}
+	defer f.Close()
+	return ReadEntries(f)
+}
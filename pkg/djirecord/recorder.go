@@ -0,0 +1,78 @@
+package djirecord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/djictl/pkg/djible"
+	"github.com/xaionaro-go/djictl/pkg/djiwifi"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+	"github.com/xaionaro-go/xsync"
+)
+
+// Recorder writes a transcript of a session as JSON lines (one Entry per line).
+//
+// It is attached to a transport through its hooks: see BLEFrameHook and WiFiPacketHook.
+type Recorder struct {
+	locker   xsync.Mutex
+	encoder  *json.Encoder
+	decoders entryDecoder
+}
+
+// NewRecorder returns a Recorder writing the transcript to the given writer.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{
+		encoder:  json.NewEncoder(w),
+		decoders: entryDecoder{},
+	}
+}
+
+// Record writes the entry; if the time of the entry is not set, then the current time is used.
+func (r *Recorder) Record(ctx context.Context, e *Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	return xsync.DoR1(ctx, &r.locker, func() error {
+		for _, msg := range r.decoders.Decode(e) {
+			e.Messages = append(e.Messages, msg.String())
+		}
+		if err := r.encoder.Encode(e); err != nil {
+			return fmt.Errorf("unable to write the entry: %w", err)
+		}
+		return nil
+	})
+}
+
+// BLEFrameHook returns the hook to be set as djible.Device.OnFrame.
+func (r *Recorder) BLEFrameHook() djible.FrameHook {
+	return func(ctx context.Context, direction duml.Direction, handle uint16, b []byte) {
+		err := r.Record(ctx, &Entry{
+			Direction: direction,
+			Transport: TransportBLE,
+			Handle:    handle,
+			Frame:     append(HexBytes{}, b...),
+		})
+		if err != nil {
+			logger.Errorf(ctx, "unable to record a frame: %v", err)
+		}
+	}
+}
+
+// WiFiPacketHook returns the hook to be set as djiwifi.Controller.OnPacket.
+func (r *Recorder) WiFiPacketHook() djiwifi.PacketHook {
+	return func(ctx context.Context, direction duml.Direction, p *djiwifi.Packet) {
+		err := r.Record(ctx, &Entry{
+			Direction: direction,
+			Transport: TransportWiFi,
+			Metadata:  append(HexBytes{}, p.Metadata[:]...),
+			Frame:     p.Bytes(),
+		})
+		if err != nil {
+			logger.Errorf(ctx, "unable to record a packet: %v", err)
+		}
+	}
+}
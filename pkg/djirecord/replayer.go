@@ -0,0 +1,182 @@
+package djirecord
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/djictl/pkg/djible"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+	"github.com/xaionaro-go/xsync"
+)
+
+// step is a frame sent by the app in the transcript, together with the
+// messages received after it (until the next sent frame).
+type step struct {
+	// Request is the sent DUML message (nil for the non-DUML frames).
+	Request *duml.Message
+
+	// PairingRequest is true for a write to the BLE pairing requestor characteristic.
+	PairingRequest bool
+
+	Replies  []*duml.Message
+	Consumed bool
+}
+
+// Replayer is a mocked device (see djiemu.Device) serving a transcript back to the app.
+//
+// Each message from the app is matched (by MessageType) to the first not yet
+// matched sent message of the transcript, and the messages received after it
+// in the transcript are sent back; the ID of the response is rewritten to the
+// ID of the app's message. The messages received before the first sent frame
+// are sent on connect (see RunPushes). The timing of the transcript is not
+// reproduced.
+type Replayer struct {
+	Type duml.DeviceType
+	Name string
+
+	locker   xsync.Mutex
+	prologue []*duml.Message
+	steps    []*step
+}
+
+var _ djiemu.Device = (*Replayer)(nil)
+
+// NewReplayer returns a Replayer of the transcript.
+func NewReplayer(typ duml.DeviceType, entries []*Entry) *Replayer {
+	r := &Replayer{
+		Type: typ,
+		Name: fmt.Sprintf("DJI %s (replay)", typ),
+	}
+
+	decoders := entryDecoder{}
+	var last *step
+	for _, e := range entries {
+		msgs := decoders.Decode(e)
+		switch e.Direction {
+		case duml.DirectionSent:
+			if e.Transport == TransportBLE && e.Handle == djible.CharacteristicIDPairingRequestor {
+				last = &step{PairingRequest: true}
+				r.steps = append(r.steps, last)
+				continue
+			}
+			for _, msg := range msgs {
+				last = &step{Request: msg}
+				r.steps = append(r.steps, last)
+			}
+		case duml.DirectionReceived:
+			if last == nil {
+				r.prologue = append(r.prologue, msgs...)
+				continue
+			}
+			last.Replies = append(last.Replies, msgs...)
+		}
+	}
+	return r
+}
+
+// DeviceType implements djiemu.Device.
+func (r *Replayer) DeviceType() duml.DeviceType {
+	return r.Type
+}
+
+// DeviceName implements djiemu.Device.
+func (r *Replayer) DeviceName() string {
+	return r.Name
+}
+
+// Pending returns the amount of the requests (the messages with
+// MessageTypeFlagAckRequired and the pairing requests) of the transcript
+// that were not matched yet.
+func (r *Replayer) Pending() int {
+	return xsync.DoR1(context.Background(), &r.locker, func() int {
+		count := 0
+		for _, s := range r.steps {
+			if s.Consumed {
+				continue
+			}
+			if s.PairingRequest || s.Request.Type.Flags&duml.MessageTypeFlagAckRequired != 0 {
+				count++
+			}
+		}
+		return count
+	})
+}
+
+// RunPushes implements djiemu.Device: it sends the messages received before the first sent frame.
+func (r *Replayer) RunPushes(ctx context.Context, send djiemu.SendFunc) {
+	r.sendAll(ctx, nil, nil, r.prologue, send)
+}
+
+// HandlePairingRequest implements djiemu.Device.
+func (r *Replayer) HandlePairingRequest(ctx context.Context, b []byte, send djiemu.SendFunc) {
+	s := r.consume(ctx, func(s *step) bool {
+		return s.PairingRequest
+	})
+	if s == nil {
+		logger.Warnf(ctx, "the transcript has no more pairing requests")
+		return
+	}
+	r.sendAll(ctx, nil, nil, s.Replies, send)
+}
+
+// Handle implements djiemu.Device.
+func (r *Replayer) Handle(
+	ctx context.Context,
+	msg *duml.Message,
+	send djiemu.SendFunc,
+) {
+	logger.Debugf(ctx, "replayer received: %s", msg)
+	s := r.consume(ctx, func(s *step) bool {
+		return s.Request != nil && sameType(s.Request.Type, msg.Type)
+	})
+	if s == nil {
+		logger.Warnf(ctx, "the transcript has no more messages of type %s", msg.Type)
+		return
+	}
+	r.sendAll(ctx, s.Request, msg, s.Replies, send)
+}
+
+func (r *Replayer) consume(ctx context.Context, match func(*step) bool) *step {
+	return xsync.DoR1(ctx, &r.locker, func() *step {
+		for _, s := range r.steps {
+			if s.Consumed || !match(s) {
+				continue
+			}
+			s.Consumed = true
+			return s
+		}
+		return nil
+	})
+}
+
+// sendAll sends copies of the messages; the ID of the response to the
+// recorded request is replaced with the ID of the actual request.
+func (r *Replayer) sendAll(
+	ctx context.Context,
+	recordedReq *duml.Message,
+	req *duml.Message,
+	msgs []*duml.Message,
+	send djiemu.SendFunc,
+) {
+	for _, msg := range msgs {
+		out := *msg
+		if recordedReq != nil && out.Type.Flags&duml.MessageTypeFlagResponse != 0 &&
+			out.ID == recordedReq.ID &&
+			out.Type.CmdSet == recordedReq.Type.CmdSet && out.Type.CmdID == recordedReq.Type.CmdID {
+			out.ID = req.ID
+		}
+		if err := send(ctx, &out); err != nil {
+			logger.Errorf(ctx, "unable to send %s: %v", out.Type, err)
+			return
+		}
+	}
+}
+
+// sameType returns true if the messages have the same command and are both
+// requests or both responses.
+func sameType(a, b duml.MessageType) bool {
+	return a.CmdSet == b.CmdSet && a.CmdID == b.CmdID &&
+		a.Flags&duml.MessageTypeFlagResponse == b.Flags&duml.MessageTypeFlagResponse
+}
@@ -0,0 +1,111 @@
+package djirecord
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/djictl/pkg/djiapi"
+	"github.com/xaionaro-go/djictl/pkg/djible"
+	"github.com/xaionaro-go/djictl/pkg/djiemu"
+	"github.com/xaionaro-go/djictl/pkg/djiwifi"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+	"github.com/xaionaro-go/gatt"
+)
+
+func scanOne(ctx context.Context, t *testing.T, d gatt.Device) *djible.Device {
+	devCh, errCh, err := djible.ScanWithDevice(ctx, d)
+	require.NoError(t, err)
+	select {
+	case dev := <-devCh:
+		return dev
+	case err := <-errCh:
+		t.Fatal(err)
+	case <-ctx.Done():
+		t.Fatal("the device was not found")
+	}
+	return nil
+}
+
+func TestRecordAndReplayBLE(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	session := func(dev *djible.Device) (string, string, duml.BatteryCapacity) {
+		require.NoError(t, dev.Init(ctx))
+		require.NoError(t, dev.AppToWiFiGroundStation().Pair(ctx))
+		ssid, psk, err := dev.AppToWiFiGroundStation().CameraAPInfo(ctx)
+		require.NoError(t, err)
+		status, err := dev.AppToBattery().GetInfo(ctx)
+		require.NoError(t, err)
+		return ssid, psk, status.Capacity
+	}
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoAction4)
+	camera.SetBatteryCapacity(42)
+	var transcript bytes.Buffer
+	bleDev := camera.NewBLEDevice(ctx)
+	defer bleDev.Close()
+	dev := scanOne(ctx, t, bleDev)
+	dev.OnFrame = NewRecorder(&transcript).BLEFrameHook()
+	ssid, psk, capacity := session(dev)
+	require.Equal(t, camera.CameraAPSSID, ssid)
+
+	entries, err := ReadEntries(bytes.NewReader(transcript.Bytes()))
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	require.Equal(t, duml.DirectionReceived, entries[0].Direction)
+	require.Equal(t, djible.CharacteristicIDReceiver, entries[0].Handle)
+	require.NotEmpty(t, entries[0].Messages)
+
+	replayer := NewReplayer(duml.DeviceTypeOsmoAction4, entries)
+	require.NotZero(t, replayer.Pending())
+	replayDev := djiemu.NewBLEDevice(ctx, replayer)
+	defer replayDev.Close()
+	replayedSSID, replayedPSK, replayedCapacity := session(scanOne(ctx, t, replayDev))
+	require.Equal(t, ssid, replayedSSID)
+	require.Equal(t, psk, replayedPSK)
+	require.Equal(t, capacity, replayedCapacity)
+	require.Zero(t, replayer.Pending())
+}
+
+func TestRecordAndReplayWiFi(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	session := func(d djiemu.Device, record *bytes.Buffer) duml.BatteryCapacity {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		go djiemu.ServeUDP(ctx, conn, d)
+
+		ctrl, err := djiwifi.NewController(ctx, conn.LocalAddr().String())
+		require.NoError(t, err)
+		defer ctrl.Close()
+		if record != nil {
+			ctrl.OnPacket = NewRecorder(record).WiFiPacketHook()
+		}
+		require.NoError(t, ctrl.SendHandshake(ctx))
+		status, err := djiapi.AppToBattery(ctrl).GetInfo(ctx)
+		require.NoError(t, err)
+		return status.Capacity
+	}
+
+	camera := djiemu.NewCamera(duml.DeviceTypeOsmoPocket3)
+	camera.SetBatteryCapacity(13)
+	var transcript bytes.Buffer
+	require.Equal(t, duml.BatteryCapacity(13), session(camera, &transcript))
+
+	entries, err := ReadEntries(&transcript)
+	require.NoError(t, err)
+	require.Equal(t, duml.DirectionSent, entries[0].Direction)
+	require.Equal(t, TransportWiFi, entries[0].Transport)
+	require.Equal(t, HexBytes(djiwifi.MetadataInitial[:]), entries[0].Metadata)
+
+	camera.SetBatteryCapacity(99)
+	replayer := NewReplayer(duml.DeviceTypeOsmoPocket3, entries)
+	require.Equal(t, duml.BatteryCapacity(13), session(replayer, nil))
+	require.Zero(t, replayer.Pending())
+}
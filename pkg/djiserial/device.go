@@ -0,0 +1,60 @@
+// Package djiserial implements the DUML transport over a serial port,
+// e.g. the USB CDC-ACM port exposed by DJI Goggles in USB mode.
+package djiserial
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/xaionaro-go/djictl/pkg/duml"
+)
+
+// DefaultBaudRate is the baud rate used by DJI devices on their serial ports.
+const DefaultBaudRate = 115200
+
+// Device is a connection to a DJI device over a serial port.
+//
+// It implements duml.Conn: the byte stream is split into DUML frames, which
+// are handled by the embedded duml.StreamConn.
+type Device struct {
+	// Path is the path to the tty device (empty if the Device was created by NewDevice).
+	Path string
+
+	*duml.StreamConn
+}
+
+var _ duml.Conn = (*Device)(nil)
+
+// Open opens the tty device in raw mode with the given baud rate.
+func Open(
+	ctx context.Context,
+	path string,
+	baudRate uint,
+) (*Device, error) {
+	port, err := openPort(path, baudRate)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open serial port '%s': %w", path, err)
+	}
+	d := NewDevice(ctx, port)
+	d.Path = path
+	return d, nil
+}
+
+// NewDevice returns a Device talking DUML over the given byte stream.
+// The stream is closed on Close.
+func NewDevice(
+	ctx context.Context,
+	port io.ReadWriteCloser,
+) *Device {
+	return &Device{
+		StreamConn: duml.NewStreamConn(ctx, port),
+	}
+}
+
+func (d *Device) String() string {
+	if d.Path == "" {
+		return "serial"
+	}
+	return "serial:" + d.Path
+}
@@ -0,0 +1,107 @@
+//go:build linux
+
+package djiserial
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/djictl/pkg/duml"
+	"golang.org/x/sys/unix"
+)
+
+// openPTY returns the master side of a new pseudo-terminal and the path to its slave side.
+func openPTY(t *testing.T) (*os.File, string) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK, 0)
+	if err != nil {
+		t.Skipf("unable to open /dev/ptmx: %v", err)
+	}
+	rawConn, err := master.SyscallConn()
+	require.NoError(t, err)
+	var (
+		ptyNum int
+		ptyErr error
+	)
+	require.NoError(t, rawConn.Control(func(fd uintptr) {
+		if ptyErr = unix.IoctlSetPointerInt(int(fd), unix.TIOCSPTLCK, 0); ptyErr != nil {
+			return
+		}
+		ptyNum, ptyErr = unix.IoctlGetInt(int(fd), unix.TIOCGPTN)
+	}))
+	if ptyErr != nil {
+		master.Close()
+		t.Skipf("unable to initialize the pty: %v", ptyErr)
+	}
+	return master, fmt.Sprintf("/dev/pts/%d", ptyNum)
+}
+
+func TestDeviceOverPTY(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	master, slavePath := openPTY(t)
+	defer master.Close()
+
+	dev, err := Open(ctx, slavePath, DefaultBaudRate)
+	require.NoError(t, err)
+	defer dev.Close()
+
+	// the scripted fake device: it responds to requests (prefixing the
+	// responses with garbage) and reports the received responses (ACKs)
+	acks := make(chan *duml.Message, 1)
+	go func() {
+		_ = duml.NewDecoder().ReadMessages(master, func(msg *duml.Message) error {
+			if msg.Type.Flags&duml.MessageTypeFlagResponse != 0 {
+				acks <- msg
+				return nil
+			}
+			resp := &duml.Message{
+				Interface: duml.InterfaceID{Sender: msg.Interface.Receiver, Receiver: msg.Interface.Sender},
+				ID:        msg.ID,
+				Type:      duml.MessageTypeResponse(msg.Type.CmdSet, msg.Type.CmdID),
+				Payload:   []byte{0x00, 0x01},
+			}
+			_, err := master.Write(append([]byte{0x00, 0x55, 0xFF}, resp.Bytes()...))
+			return err
+		})
+	}()
+
+	msg := duml.NewFCCEnableMessage(true)
+	msg.Type.Flags |= duml.MessageTypeFlagAckRequired
+	resp, err := dev.Request(ctx, msg, duml.RequestTimeout(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, msg.ID, resp.ID)
+	require.Equal(t, []byte{0x00, 0x01}, resp.Payload)
+
+	push := &duml.Message{
+		Interface: duml.InterfaceID{Sender: duml.ComponentIDCamera, Receiver: duml.ComponentIDApp},
+		ID:        0x1234,
+		Type:      duml.MessageTypeRequest(msg.Type.CmdSet, msg.Type.CmdID),
+	}
+	_, err = master.Write(push.Bytes())
+	require.NoError(t, err)
+	select {
+	case ack := <-acks:
+		require.Equal(t, push.ID, ack.ID)
+		require.Equal(t, duml.InterfaceID{Sender: duml.ComponentIDApp, Receiver: duml.ComponentIDCamera}, ack.Interface)
+	case <-ctx.Done():
+		t.Fatal("no ACK received")
+	}
+
+	// closing the master side hangs up the slave side
+	sub := dev.Subscribe(ctx, nil)
+	require.NoError(t, master.Close())
+	_, err = sub.Receive(ctx)
+	require.ErrorIs(t, err, duml.ErrDisconnected)
+	_, err = dev.Request(ctx, msg, duml.RequestTimeout(time.Second))
+	require.ErrorIs(t, err, duml.ErrDisconnected)
+}
+
+func TestOpenUnsupportedBaudRate(t *testing.T) {
+	_, err := Open(context.Background(), "/dev/null", 12345)
+	require.Error(t, err)
+}
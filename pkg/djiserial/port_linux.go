@@ -0,0 +1,83 @@
+//go:build linux
+
+package djiserial
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+var baudRates = map[uint]uint32{
+	9600:    unix.B9600,
+	19200:   unix.B19200,
+	38400:   unix.B38400,
+	57600:   unix.B57600,
+	115200:  unix.B115200,
+	230400:  unix.B230400,
+	460800:  unix.B460800,
+	921600:  unix.B921600,
+	1000000: unix.B1000000,
+	1500000: unix.B1500000,
+	2000000: unix.B2000000,
+	3000000: unix.B3000000,
+	4000000: unix.B4000000,
+}
+
+// openPort opens the tty in raw mode (8N1, no flow control).
+//
+// The file is opened in the non-blocking mode, so that it is handled
+// by the Go runtime poller and Close interrupts a pending Read.
+func openPort(path string, baudRate uint) (*os.File, error) {
+	speed, ok := baudRates[baudRate]
+	if !ok {
+		return nil, fmt.Errorf("unsupported baud rate: %d", baudRate)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// f.Fd() would switch the file back to the blocking mode, so using SyscallConn
+	rawConn, err := f.SyscallConn()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("unable to get the raw connection: %w", err)
+	}
+	var setErr error
+	if err := rawConn.Control(func(fd uintptr) {
+		setErr = setRaw(int(fd), speed)
+	}); err != nil {
+		setErr = err
+	}
+	if setErr != nil {
+		f.Close()
+		return nil, setErr
+	}
+	return f, nil
+}
+
+func setRaw(fd int, speed uint32) error {
+	t, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return fmt.Errorf("unable to get the terminal attributes: %w", err)
+	}
+
+	// the same as cfmakeraw(3)
+	t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON | unix.IXOFF
+	t.Oflag &^= unix.OPOST
+	t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	t.Cflag &^= unix.CSIZE | unix.PARENB | unix.CSTOPB | unix.CRTSCTS | unix.CBAUD
+	t.Cflag |= unix.CS8 | unix.CREAD | unix.CLOCAL | speed
+	t.Ispeed = speed
+	t.Ospeed = speed
+	t.Cc[unix.VMIN] = 1
+	t.Cc[unix.VTIME] = 0
+
+	if err := unix.IoctlSetTermios(fd, unix.TCSETS, t); err != nil {
+		return fmt.Errorf("unable to set the terminal attributes: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package djiserial
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+func openPort(path string, baudRate uint) (*os.File, error) {
+	return nil, fmt.Errorf("serial ports are not supported on %s, yet", runtime.GOOS)
+}
@@ -3,24 +3,46 @@ package djiwifi
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
 	"net"
-	"sync/atomic"
-	"time"
 
 	"github.com/facebookincubator/go-belt/tool/logger"
 	"github.com/xaionaro-go/djictl/pkg/duml"
 )
 
+// packetQueueSize is the amount of received non-DUML packets buffered for ReceivePacket.
+const packetQueueSize = 256
+
+// PacketHook observes the packets sent (DirectionSent) to and received
+// (DirectionReceived) from the device.
+type PacketHook func(ctx context.Context, direction duml.Direction, p *Packet)
+
+// Controller is a connection to a DJI device over WiFi (UDP).
+//
+// It implements duml.Conn: the DUML messages received from the device are
+// handled by the embedded duml.Endpoint, and the other packets (e.g. video)
+// are available through ReceivePacket.
 type Controller struct {
 	conn *net.UDPConn
 	addr *net.UDPAddr
 
-	nextSeq atomic.Uint32
+	// Type is the model of the device, if known.
+	Type duml.DeviceType
+
+	*duml.Endpoint
+
+	packets chan *Packet
+
+	// OnPacket, if set, is called for each packet sent or received (e.g. to record the session).
+	// It should be set before sending anything.
+	OnPacket PacketHook
 
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
+var _ duml.Conn = (*Controller)(nil)
+
 func NewController(ctx context.Context, deviceAddr string) (*Controller, error) {
 	addr, err := net.ResolveUDPAddr(ProtocolUDP, deviceAddr)
 	if err != nil {
@@ -34,27 +56,83 @@ func NewController(ctx context.Context, deviceAddr string) (*Controller, error)
 
 	ctx, cancel := context.WithCancel(ctx)
 	c := &Controller{
-		conn:   conn,
-		addr:   addr,
-		ctx:    ctx,
-		cancel: cancel,
+		conn:    conn,
+		addr:    addr,
+		packets: make(chan *Packet, packetQueueSize),
+		ctx:     ctx,
+		cancel:  cancel,
 	}
+	c.Endpoint = duml.NewEndpoint(duml.MessageID(rand.Uint32()), func(ctx context.Context, msg *duml.Message) error {
+		return c.SendPacket(ctx, NewDUMLPacket(msg, MetadataApp))
+	})
+	go c.receiveLoop(ctx)
 
 	return c, nil
 }
 
+// DeviceType implements duml.Conn.
+func (c *Controller) DeviceType() duml.DeviceType {
+	return c.Type
+}
+
 func (c *Controller) Close() error {
 	c.cancel()
-	return c.conn.Close()
+	err := c.conn.Close()
+	c.Endpoint.Close(duml.ErrDisconnected)
+	return err
+}
+
+func (c *Controller) receiveLoop(ctx context.Context) {
+	logger.Tracef(ctx, "receiveLoop")
+	defer func() { logger.Tracef(ctx, "/receiveLoop") }()
+
+	decoder := duml.NewDecoder()
+	buf := make([]byte, ReadBufferSize)
+	for {
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Errorf(ctx, "unable to read from %s: %v", c.addr, err)
+			}
+			c.Endpoint.Close(duml.ErrDisconnected)
+			return
+		}
+
+		p, err := ParsePacket(append([]byte{}, buf[:n]...))
+		if err != nil {
+			logger.Debugf(ctx, "unable to parse a packet from %s: %v", c.addr, err)
+			continue
+		}
+		if c.OnPacket != nil {
+			c.OnPacket(ctx, duml.DirectionReceived, p)
+		}
+		if len(p.Payload) == 0 || p.Payload[0] != DUMLMagic {
+			select {
+			case c.packets <- p:
+			default:
+				logger.Debugf(ctx, "the packet queue is full, dropping a packet of type %s", p.Type)
+			}
+			continue
+		}
+		for _, msg := range decoder.Decode(p.Payload) {
+			c.HandleMessage(ctx, msg)
+		}
+	}
 }
 
 func (c *Controller) SendPacket(ctx context.Context, p *Packet) error {
 	logger.Tracef(ctx, "SendPacket: Type=%s Len=%d", p.Type, len(p.Payload))
+	if c.OnPacket != nil {
+		c.OnPacket(ctx, duml.DirectionSent, p)
+	}
 	_, err := c.conn.Write(p.Bytes())
 	return err
 }
 
+// SendDUML sends the message; if duml.Message.AutoID is set, then the ID
+// is allocated by the Sequencer (and set in the message).
 func (c *Controller) SendDUML(ctx context.Context, msg *duml.Message, metadata Metadata) error {
+	c.Sequencer.Assign(msg)
 	p := NewDUMLPacket(msg, metadata)
 	return c.SendPacket(ctx, p)
 }
@@ -68,17 +146,17 @@ func (c *Controller) SendAppStatus(ctx context.Context, metadata Metadata, paylo
 	return c.SendPacket(ctx, p)
 }
 
+// ReceivePacket returns the next received packet that is not a DUML message
+// (the DUML messages are available through Subscribe).
 func (c *Controller) ReceivePacket(ctx context.Context) (*Packet, error) {
-	buf := make([]byte, ReadBufferSize)
-	if deadline, ok := ctx.Deadline(); ok {
-		c.conn.SetReadDeadline(deadline)
-		defer c.conn.SetReadDeadline(time.Time{})
-	}
-	n, err := c.conn.Read(buf)
-	if err != nil {
-		return nil, err
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.ctx.Done():
+		return nil, duml.ErrDisconnected
+	case p := <-c.packets:
+		return p, nil
 	}
-	return ParsePacket(buf[:n])
 }
 
 func (c *Controller) SendHandshake(ctx context.Context) error {
@@ -106,31 +184,3 @@ func (c *Controller) SendVideoHandshake(ctx context.Context) error {
 	}
 	return c.SendPacket(ctx, p)
 }
-
-// SendSimulatorData sends Remote Controller stick and button data in simulator mode.
-func (c *Controller) SendSimulatorData(ctx context.Context, data duml.RemoteControllerSimulatorData) error {
-	msg := duml.NewRemoteControllerSimulatorMessage(data)
-	return c.SendDUML(ctx, msg, MetadataApp)
-}
-
-func (c *Controller) SendFCCEnable(ctx context.Context, enable bool) error {
-	msg := duml.NewFCCEnableMessage(enable)
-	msg.Interface = duml.InterfaceIDAppToCamera
-	return c.SendDUML(ctx, msg, MetadataApp)
-}
-
-func (c *Controller) SendConfigureBroadcast(ctx context.Context, url string, enable bool) error {
-	msg := duml.NewBroadcastMessage(enable, url)
-	return c.SendDUML(ctx, msg, MetadataApp)
-}
-
-// SendStopStreaming sends the command to stop video streaming.
-func (c *Controller) SendStopStreaming(ctx context.Context) error {
-	msg := &duml.Message{
-		Interface: duml.InterfaceIDAppToVideoTransmission,
-		ID:        duml.MessageIDStopStreaming,
-		Type:      duml.MessageTypeStartStopStreaming,
-		Payload:   []byte{0x00}, // 0 = Stop
-	}
-	return c.SendDUML(ctx, msg, MetadataApp)
-}
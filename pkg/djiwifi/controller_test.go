@@ -1,10 +1,14 @@
 package djiwifi
 
 import (
+	"context"
 	"encoding/hex"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/xaionaro-go/djictl/pkg/duml"
 )
 
@@ -56,3 +60,56 @@ func TestPacket_Video(t *testing.T) {
 	// Verify NAL start code in payload
 	assert.Contains(t, hex.EncodeToString(p.Payload), "0000000165")
 }
+
+func TestController_Request(t *testing.T) {
+	ctx := context.Background()
+
+	device, err := net.ListenUDP(ProtocolUDP, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer device.Close()
+
+	go func() {
+		buf := make([]byte, ReadBufferSize)
+		for {
+			n, addr, err := device.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			p, err := ParsePacket(buf[:n])
+			if err != nil {
+				continue
+			}
+			msg, err := p.DUMLMessage()
+			if err != nil || msg.Type.Flags&duml.MessageTypeFlagResponse != 0 {
+				continue
+			}
+			resp := &duml.Message{
+				Interface: duml.InterfaceID{Sender: msg.Interface.Receiver, Receiver: msg.Interface.Sender},
+				ID:        msg.ID,
+				Type:      duml.MessageTypeResponse(msg.Type.CmdSet, msg.Type.CmdID),
+				Payload:   []byte{0x00},
+			}
+			_, _ = device.WriteToUDP(NewDUMLPacket(resp, MetadataApp).Bytes(), addr)
+			_, _ = device.WriteToUDP((&Packet{Type: MessageTypeControl, Payload: []byte{0x01}}).Bytes(), addr)
+		}
+	}()
+
+	ctrl, err := NewController(ctx, device.LocalAddr().String())
+	require.NoError(t, err)
+	defer ctrl.Close()
+
+	msg := duml.NewFCCEnableMessage(true)
+	msg.Type.Flags |= duml.MessageTypeFlagAckRequired
+	resp, err := ctrl.Request(ctx, msg, duml.RequestTimeout(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, msg.ID, resp.ID)
+	require.Equal(t, []byte{0x00}, resp.Payload)
+
+	p, err := ctrl.ReceivePacket(ctx)
+	require.NoError(t, err)
+	require.Equal(t, MessageTypeControl, p.Type)
+
+	require.NoError(t, ctrl.Close())
+	_, err = ctrl.Request(ctx, msg, duml.RequestTimeout(time.Second))
+	require.ErrorIs(t, err, duml.ErrDisconnected)
+}
@@ -0,0 +1,79 @@
+package duml
+
+import (
+	"fmt"
+)
+
+// AdvertisementStatus is the raw status byte of the BLE advertisement manufacturer data.
+//
+// The meaning of its bits is not known yet, so it is not decoded.
+type AdvertisementStatus uint8
+
+// String returns the raw byte, e.g. "0x05 (undecoded)".
+func (s AdvertisementStatus) String() string {
+	return fmt.Sprintf("0x%02X (undecoded)", uint8(s))
+}
+
+const (
+	advertisementVariantOffset = 4
+	advertisementStatusOffset  = 5
+)
+
+// AdvertisementData is the parsed BLE advertisement manufacturer data of a DJI device.
+//
+// The layout beyond the model magic is experimental (see KeyValueKey.IsExperimental):
+//
+//	[0:2]  - the DJI prefix (0xAA 0x08)
+//	[2:4]  - the model magic (see DeviceProfile.Magic)
+//	[4]    - the model variant
+//	[5]    - the AdvertisementStatus
+//	[6:]   - not yet understood
+type AdvertisementData struct {
+	DeviceType DeviceType
+	Variant    uint8
+	Status     AdvertisementStatus
+
+	// HasVariant and HasStatus are false if the manufacturer data is too short to carry them.
+	HasVariant bool
+	HasStatus  bool
+
+	Raw []byte
+}
+
+// ParseAdvertisementData parses the BLE advertisement manufacturer data;
+// DeviceType is DeviceTypeUndefined if it is not a DJI device.
+func ParseAdvertisementData(manufacturerData []byte) AdvertisementData {
+	data := AdvertisementData{
+		DeviceType: IdentifyDeviceType(manufacturerData),
+		Raw:        append([]byte{}, manufacturerData...),
+	}
+	if data.DeviceType == DeviceTypeUndefined {
+		return data
+	}
+	if len(manufacturerData) > advertisementVariantOffset {
+		data.Variant = manufacturerData[advertisementVariantOffset]
+		data.HasVariant = true
+	}
+	if len(manufacturerData) > advertisementStatusOffset {
+		data.Status = AdvertisementStatus(manufacturerData[advertisementStatusOffset])
+		data.HasStatus = true
+	}
+	return data
+}
+
+// Bytes is the reverse of ParseAdvertisementData; the bytes of Raw beyond
+// the known fields are preserved.
+func (a AdvertisementData) Bytes() []byte {
+	b := a.DeviceType.ManufacturerData()
+	if !a.HasVariant && !a.HasStatus {
+		return b
+	}
+	b = append(b, a.Variant)
+	if a.HasStatus {
+		b = append(b, uint8(a.Status))
+		if len(a.Raw) > len(b) {
+			b = append(b, a.Raw[len(b):]...)
+		}
+	}
+	return b
+}
@@ -0,0 +1,40 @@
+package duml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAdvertisementData(t *testing.T) {
+	t.Run("model-only", func(t *testing.T) {
+		data := ParseAdvertisementData(DeviceTypeOsmoAction4.ManufacturerData())
+		require.Equal(t, DeviceTypeOsmoAction4, data.DeviceType)
+		require.False(t, data.HasVariant)
+		require.False(t, data.HasStatus)
+		require.Equal(t, DeviceTypeOsmoAction4.ManufacturerData(), data.Bytes())
+	})
+
+	t.Run("with-status", func(t *testing.T) {
+		b := append(DeviceTypeOsmoPocket3.ManufacturerData(), 0x02, 0x0A, 0xDE, 0xAD)
+		data := ParseAdvertisementData(b)
+		require.Equal(t, DeviceTypeOsmoPocket3, data.DeviceType)
+		require.True(t, data.HasVariant)
+		require.Equal(t, uint8(2), data.Variant)
+		require.True(t, data.HasStatus)
+		require.Equal(t, AdvertisementStatus(0x0A), data.Status)
+		require.Equal(t, "0x0A (undecoded)", data.Status.String())
+		require.Equal(t, b, data.Bytes())
+	})
+
+	t.Run("not-dji", func(t *testing.T) {
+		data := ParseAdvertisementData([]byte{0x4C, 0x00, 0x02, 0x15, 0x01, 0x01})
+		require.Equal(t, DeviceTypeUndefined, data.DeviceType)
+		require.False(t, data.HasStatus)
+	})
+}
+
+func TestAdvertisementStatusString(t *testing.T) {
+	require.Equal(t, "0x00 (undecoded)", AdvertisementStatus(0).String())
+	require.Equal(t, "0x05 (undecoded)", AdvertisementStatus(0x05).String())
+}
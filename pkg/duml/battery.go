@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"strings"
+	"time"
 )
 
 type BatteryCapacity int8
@@ -19,29 +21,367 @@ func (b BatteryCapacity) String() string {
 	return fmt.Sprintf("%d%%", int8(b))
 }
 
+// Millivolts is a voltage.
+type Millivolts uint16
+
+const (
+	UndefinedMillivolts = Millivolts(math.MaxUint16)
+)
+
+func (v Millivolts) String() string {
+	if v == UndefinedMillivolts {
+		return "<undefined>"
+	}
+	return fmt.Sprintf("%d.%03dV", v/1000, v%1000)
+}
+
+// Milliamperes is an electric current; positive is into the battery (charging).
+type Milliamperes int16
+
+const (
+	UndefinedMilliamperes = Milliamperes(math.MinInt16)
+)
+
+func (a Milliamperes) String() string {
+	if a == UndefinedMilliamperes {
+		return "<undefined>"
+	}
+	return fmt.Sprintf("%dmA", int16(a))
+}
+
+// Decicelsius is a temperature in tenths of a degree Celsius.
+type Decicelsius int16
+
+const (
+	UndefinedDecicelsius = Decicelsius(math.MinInt16)
+)
+
+func (t Decicelsius) Celsius() float64 {
+	return float64(t) / 10
+}
+
+func (t Decicelsius) String() string {
+	if t == UndefinedDecicelsius {
+		return "<undefined>"
+	}
+	return fmt.Sprintf("%.1f°C", t.Celsius())
+}
+
+// Seconds is a duration with a precision of one second.
+type Seconds uint16
+
+const (
+	UndefinedSeconds = Seconds(math.MaxUint16)
+)
+
+func (s Seconds) Duration() time.Duration {
+	return time.Duration(s) * time.Second
+}
+
+func (s Seconds) String() string {
+	if s == UndefinedSeconds {
+		return "<undefined>"
+	}
+	return s.Duration().String()
+}
+
+// BatteryChargingState is the charging state of the battery.
+//
+// The values are experimental (see KeyValueKey.IsExperimental).
+type BatteryChargingState uint8
+
+const (
+	BatteryChargingStateDischarging = BatteryChargingState(0x00)
+	BatteryChargingStateCharging    = BatteryChargingState(0x01)
+	BatteryChargingStateFull        = BatteryChargingState(0x02)
+	UndefinedBatteryChargingState   = BatteryChargingState(0xFF)
+)
+
+func (s BatteryChargingState) String() string {
+	switch s {
+	case BatteryChargingStateDischarging:
+		return "discharging"
+	case BatteryChargingStateCharging:
+		return "charging"
+	case BatteryChargingStateFull:
+		return "full"
+	case UndefinedBatteryChargingState:
+		return "<undefined>"
+	}
+	return fmt.Sprintf("<unexpected:0x%02X>", uint8(s))
+}
+
+// BatteryStatus is the payload of MessageTypeBatteryStatus.
+//
+// Only Capacity is confirmed (byte 20 of a payload of 21+ bytes, otherwise
+// byte 12), the rest of the fields are experimental (see KeyValueKey.IsExperimental
+// and batteryStatusLayout). The fields not present in the payload
+// of the device are set to their Undefined* values.
 type BatteryStatus struct {
-	Capacity BatteryCapacity
+	// DeviceType selects the layout of the payload; if undefined, then
+	// the layout is guessed by the length of the payload.
+	DeviceType DeviceType
+
+	Capacity               BatteryCapacity
+	Voltage                Millivolts
+	Current                Milliamperes
+	Temperature            Decicelsius
+	ChargingState          BatteryChargingState
+	RemainingRecordingTime Seconds
+	CellCount              uint8
+	Health                 BatteryCapacity
+
+	// Raw is the original payload; it is used as the base when marshaling.
+	Raw []byte
+}
+
+var _ Payload = (*BatteryStatus)(nil)
+
+// batteryStatusLayout is the offsets of the fields of BatteryStatus in the payload
+// (-1 if the field is not present); only the offsets of Capacity are confirmed,
+// the rest are experimental.
+//
+// The 16-bit fields are little-endian (see BinaryOrder).
+type batteryStatusLayout struct {
+	Length                 int
+	Capacity               int
+	Voltage                int
+	Current                int
+	Temperature            int
+	ChargingState          int
+	RemainingRecordingTime int
+	CellCount              int
+	Health                 int
+}
+
+const (
+	batteryStatusMinLength = 13
+	batteryStatusLength    = 21
+)
+
+var (
+	// batteryStatusLayouts are the layouts by their names in DeviceProfile.BatteryStatusLayout.
+	batteryStatusLayouts = map[string]batteryStatusLayout{
+		"short": batteryStatusLayoutShort,
+		"long":  batteryStatusLayoutLong,
+	}
+
+	// batteryStatusLayoutShort is the layout of the pushes of the drones
+	// and of the short pushes of the cameras.
+	batteryStatusLayoutShort = batteryStatusLayout{
+		Length:                 batteryStatusMinLength,
+		Capacity:               12,
+		Voltage:                0,
+		Current:                2,
+		Temperature:            4,
+		ChargingState:          6,
+		RemainingRecordingTime: -1,
+		CellCount:              -1,
+		Health:                 -1,
+	}
+
+	// batteryStatusLayoutLong is the layout of the pushes of the Osmo cameras.
+	batteryStatusLayoutLong = batteryStatusLayout{
+		Length:                 batteryStatusLength,
+		Capacity:               20,
+		Voltage:                0,
+		Current:                2,
+		Temperature:            4,
+		ChargingState:          6,
+		RemainingRecordingTime: 7,
+		CellCount:              9,
+		Health:                 10,
+	}
+)
+
+// BatteryStatusLength returns the length of the payload of
+// MessageTypeBatteryStatus expected from the device type
+// (0 if the device type is unknown).
+func (t DeviceType) BatteryStatusLength() int {
+	layout, ok := t.batteryStatusLayout()
+	if !ok {
+		return 0
+	}
+	return layout.Length
+}
+
+// batteryStatusLayout returns the layout named by the profile of the device type.
+func (t DeviceType) batteryStatusLayout() (batteryStatusLayout, bool) {
+	p, ok := t.Profile()
+	if !ok {
+		return batteryStatusLayout{}, false
+	}
+	layout, ok := batteryStatusLayouts[p.BatteryStatusLayout]
+	return layout, ok
+}
+
+// getBatteryStatusLayout returns the layout guessed by the payload length,
+// with the experimental fields of the device type if it is known and
+// the payload is long enough for it.
+//
+// The offset of the capacity depends on the length only, for any device.
+func getBatteryStatusLayout(t DeviceType, payloadLength int) batteryStatusLayout {
+	layout := batteryStatusLayoutShort
+	if payloadLength >= batteryStatusLength {
+		layout = batteryStatusLayoutLong
+	}
+	if deviceLayout, ok := t.batteryStatusLayout(); ok && payloadLength >= deviceLayout.Length {
+		deviceLayout.Capacity = layout.Capacity
+		layout = deviceLayout
+	}
+	return layout
 }
 
 func ParseBatteryStatus(
 	ctx context.Context,
 	payload []byte,
 ) (*BatteryStatus, error) {
-	if len(payload) < 13 {
-		return nil, fmt.Errorf("payload is too short: %d < 13", len(payload))
+	return ParseBatteryStatusForDevice(ctx, DeviceTypeUndefined, payload)
+}
+
+// ParseBatteryStatusForDevice is the same as ParseBatteryStatus,
+// but uses the payload layout of the given device type.
+func ParseBatteryStatusForDevice(
+	ctx context.Context,
+	deviceType DeviceType,
+	payload []byte,
+) (*BatteryStatus, error) {
+	status := BatteryStatus{DeviceType: deviceType}
+	if err := status.UnmarshalDUML(payload); err != nil {
+		return nil, err
 	}
+	return &status, nil
+}
 
-	var capacity BatteryCapacity
-	switch {
-	case len(payload) >= 21:
-		capacity = BatteryCapacity(payload[20])
-	case len(payload) >= 13:
-		capacity = BatteryCapacity(payload[12])
-	default:
-		return nil, fmt.Errorf("unable to determine battery status; the payload is too short: %d", len(payload))
+func (s *BatteryStatus) MarshalDUML() ([]byte, error) {
+	b := append([]byte{}, s.Raw...)
+	layout := getBatteryStatusLayout(s.DeviceType, len(b))
+	if len(b) < batteryStatusMinLength {
+		var ok bool
+		if layout, ok = s.DeviceType.batteryStatusLayout(); !ok {
+			layout = batteryStatusLayoutLong
+		}
+	}
+	if len(b) < layout.Length {
+		b = append(b, make([]byte, layout.Length-len(b))...)
+	}
+
+	b[layout.Capacity] = uint8(s.Capacity)
+	if layout.Voltage >= 0 && s.Voltage != UndefinedMillivolts {
+		binaryOrder.PutUint16(b[layout.Voltage:], uint16(s.Voltage))
+	}
+	if layout.Current >= 0 && s.Current != UndefinedMilliamperes {
+		binaryOrder.PutUint16(b[layout.Current:], uint16(s.Current))
+	}
+	if layout.Temperature >= 0 && s.Temperature != UndefinedDecicelsius {
+		binaryOrder.PutUint16(b[layout.Temperature:], uint16(s.Temperature))
+	}
+	if layout.ChargingState >= 0 && s.ChargingState != UndefinedBatteryChargingState {
+		b[layout.ChargingState] = uint8(s.ChargingState)
 	}
+	if layout.RemainingRecordingTime >= 0 && s.RemainingRecordingTime != UndefinedSeconds {
+		binaryOrder.PutUint16(b[layout.RemainingRecordingTime:], uint16(s.RemainingRecordingTime))
+	}
+	if layout.CellCount >= 0 {
+		b[layout.CellCount] = s.CellCount
+	}
+	if layout.Health >= 0 && s.Health != UndefinedBatteryCapacity {
+		b[layout.Health] = uint8(s.Health)
+	}
+	return b, nil
+}
+
+func (s *BatteryStatus) UnmarshalDUML(payload []byte) error {
+	if len(payload) < batteryStatusMinLength {
+		return fmt.Errorf("payload is too short: %d < %d", len(payload), batteryStatusMinLength)
+	}
+	layout := getBatteryStatusLayout(s.DeviceType, len(payload))
+
+	s.Capacity = BatteryCapacity(payload[layout.Capacity])
+	s.Voltage = UndefinedMillivolts
+	if layout.Voltage >= 0 {
+		s.Voltage = Millivolts(binaryOrder.Uint16(payload[layout.Voltage:]))
+	}
+	s.Current = UndefinedMilliamperes
+	if layout.Current >= 0 {
+		s.Current = Milliamperes(binaryOrder.Uint16(payload[layout.Current:]))
+	}
+	s.Temperature = UndefinedDecicelsius
+	if layout.Temperature >= 0 {
+		s.Temperature = Decicelsius(binaryOrder.Uint16(payload[layout.Temperature:]))
+	}
+	s.ChargingState = UndefinedBatteryChargingState
+	if layout.ChargingState >= 0 {
+		s.ChargingState = BatteryChargingState(payload[layout.ChargingState])
+	}
+	s.RemainingRecordingTime = UndefinedSeconds
+	if layout.RemainingRecordingTime >= 0 {
+		s.RemainingRecordingTime = Seconds(binaryOrder.Uint16(payload[layout.RemainingRecordingTime:]))
+	}
+	s.CellCount = 0
+	if layout.CellCount >= 0 {
+		s.CellCount = payload[layout.CellCount]
+	}
+	s.Health = UndefinedBatteryCapacity
+	if layout.Health >= 0 {
+		s.Health = BatteryCapacity(payload[layout.Health])
+	}
+	s.Raw = append([]byte{}, payload...)
+	return nil
+}
+
+// IsCharging returns true if the battery is being charged (according
+// to ChargingState, or to Current if the state is undefined).
+//
+// It is experimental, as both of the fields are.
+func (s *BatteryStatus) IsCharging() bool {
+	switch s.ChargingState {
+	case BatteryChargingStateCharging:
+		return true
+	case UndefinedBatteryChargingState:
+		return s.Current != UndefinedMilliamperes && s.Current > 0
+	}
+	return false
+}
+
+// Equal returns true if the decoded fields are equal (Raw and DeviceType are ignored).
+func (s *BatteryStatus) Equal(other *BatteryStatus) bool {
+	return s.Capacity == other.Capacity &&
+		s.Voltage == other.Voltage &&
+		s.Current == other.Current &&
+		s.Temperature == other.Temperature &&
+		s.ChargingState == other.ChargingState &&
+		s.RemainingRecordingTime == other.RemainingRecordingTime &&
+		s.CellCount == other.CellCount &&
+		s.Health == other.Health
+}
+
+// String returns the confirmed field only, e.g. "capacity: 87%"; see ExperimentalString.
+func (s *BatteryStatus) String() string {
+	return "capacity: " + s.Capacity.String()
+}
+
+// ExperimentalString returns the defined experimental fields,
+// e.g. "voltage: 3.850V, charging state: charging" (empty if none).
+func (s *BatteryStatus) ExperimentalString() string {
+	var fields []string
+	add := func(name string, value fmt.Stringer, defined bool) {
+		if defined {
+			fields = append(fields, fmt.Sprintf("%s: %s", name, value))
+		}
+	}
+	add("voltage", s.Voltage, s.Voltage != UndefinedMillivolts)
+	add("current", s.Current, s.Current != UndefinedMilliamperes)
+	add("temperature", s.Temperature, s.Temperature != UndefinedDecicelsius)
+	add("charging state", s.ChargingState, s.ChargingState != UndefinedBatteryChargingState)
+	add("remaining recording time", s.RemainingRecordingTime, s.RemainingRecordingTime != UndefinedSeconds)
+	if s.CellCount != 0 {
+		fields = append(fields, fmt.Sprintf("cells: %d", s.CellCount))
+	}
+	add("health", s.Health, s.Health != UndefinedBatteryCapacity)
+	return strings.Join(fields, ", ")
+}
 
-	return &BatteryStatus{
-		Capacity: capacity,
-	}, nil
+func init() {
+	RegisterPayload(MessageTypeBatteryStatus, func() Payload { return &BatteryStatus{} })
 }
@@ -0,0 +1,83 @@
+package duml
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatteryStatus(t *testing.T) {
+	ctx := context.Background()
+
+	long := []byte{
+		0x0A, 0x0F, // 3850mV
+		0x2C, 0x01, // 300mA
+		0x3B, 0x01, // 31.5°C
+		0x01,       // charging
+		0x10, 0x0E, // 3600s
+		0x01, // cells
+		0x5F, // health 95%
+		0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0x57, // capacity 87%
+	}
+	status, err := ParseBatteryStatusForDevice(ctx, DeviceTypeOsmoPocket3, long)
+	require.NoError(t, err)
+	require.Equal(t, BatteryCapacity(87), status.Capacity)
+	require.Equal(t, Millivolts(3850), status.Voltage)
+	require.Equal(t, Milliamperes(300), status.Current)
+	require.Equal(t, Decicelsius(315), status.Temperature)
+	require.Equal(t, BatteryChargingStateCharging, status.ChargingState)
+	require.Equal(t, time.Hour, status.RemainingRecordingTime.Duration())
+	require.Equal(t, uint8(1), status.CellCount)
+	require.Equal(t, BatteryCapacity(95), status.Health)
+	require.Equal(t, long, status.Raw)
+	require.True(t, status.IsCharging())
+	require.Equal(t, "capacity: 87%", status.String())
+	require.Equal(t, "voltage: 3.850V, current: 300mA, temperature: 31.5°C, charging state: charging, remaining recording time: 1h0m0s, cells: 1, health: 95%", status.ExperimentalString())
+
+	b, err := status.MarshalDUML()
+	require.NoError(t, err)
+	require.Equal(t, long, b)
+
+	// the capacity is at byte 20 of a long payload for any device
+	status, err = ParseBatteryStatusForDevice(ctx, DeviceTypeMavic3, long)
+	require.NoError(t, err)
+	require.Equal(t, BatteryCapacity(87), status.Capacity)
+	b, err = status.MarshalDUML()
+	require.NoError(t, err)
+	require.Equal(t, long, b)
+
+	// the layout of an unknown device is guessed by the length
+	status, err = ParseBatteryStatus(ctx, long[:13])
+	require.NoError(t, err)
+	require.Equal(t, BatteryCapacity(0), status.Capacity)
+	require.Equal(t, Millivolts(3850), status.Voltage)
+	require.Equal(t, UndefinedSeconds, status.RemainingRecordingTime)
+
+	status.ChargingState = UndefinedBatteryChargingState
+	require.True(t, status.IsCharging())
+	status.Current = -300
+	require.False(t, status.IsCharging())
+	require.Equal(t, "capacity: 0%", status.String())
+	require.Equal(t, "voltage: 3.850V, current: -300mA, temperature: 31.5°C", status.ExperimentalString())
+
+	require.Empty(t, (&BatteryStatus{
+		Capacity:               50,
+		Voltage:                UndefinedMillivolts,
+		Current:                UndefinedMilliamperes,
+		Temperature:            UndefinedDecicelsius,
+		ChargingState:          UndefinedBatteryChargingState,
+		RemainingRecordingTime: UndefinedSeconds,
+		Health:                 UndefinedBatteryCapacity,
+	}).ExperimentalString())
+
+	// the undefined fields are not overwritten
+	b, err = status.MarshalDUML()
+	require.NoError(t, err)
+	require.Equal(t, append([]byte{0x0A, 0x0F, 0xD4, 0xFE}, long[4:13]...), b)
+
+	_, err = ParseBatteryStatus(ctx, long[:12])
+	require.Error(t, err)
+}
@@ -1,6 +1,10 @@
 package duml
 
-import "bytes"
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
 
 type BroadcastPlatform uint8
 
@@ -8,13 +12,23 @@ const (
 	BroadcastPlatformRTMP = BroadcastPlatform(2)
 )
 
+// BroadcastConfig is the payload of MessageTypeOsmoBroadcastConfig.
 type BroadcastConfig struct {
 	Enabled  bool
 	Platform BroadcastPlatform
 	URL      string
 }
 
+var _ Payload = (*BroadcastConfig)(nil)
+
 func (c *BroadcastConfig) Payload() []byte {
+	return must(c.MarshalDUML())
+}
+
+func (c *BroadcastConfig) MarshalDUML() ([]byte, error) {
+	if err := checkStringLength(c.URL, math.MaxUint16); err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
 	var buf bytes.Buffer
 	if c.Enabled {
 		buf.WriteByte(1)
@@ -24,7 +38,24 @@ func (c *BroadcastConfig) Payload() []byte {
 	buf.WriteByte(uint8(c.Platform))
 
 	buf.Write(PackURL(c.URL))
-	return buf.Bytes()
+	return buf.Bytes(), nil
+}
+
+func (c *BroadcastConfig) UnmarshalDUML(b []byte) error {
+	if len(b) < 2 {
+		return fmt.Errorf("the payload is too short: %d < 2", len(b))
+	}
+	url, rest, err := UnpackURL(b[2:])
+	if err != nil {
+		return fmt.Errorf("unable to unpack the URL: %w", err)
+	}
+	if len(rest) != 0 {
+		return fmt.Errorf("unexpected %d trailing bytes: %X", len(rest), rest)
+	}
+	c.Enabled = b[0] != 0
+	c.Platform = BroadcastPlatform(b[1])
+	c.URL = url
+	return nil
 }
 
 func NewBroadcastMessage(enabled bool, url string) *Message {
@@ -35,8 +66,12 @@ func NewBroadcastMessage(enabled bool, url string) *Message {
 	}
 	return &Message{
 		Interface: InterfaceIDAppToCamera,
-		ID:        MessageIDStartStreaming,
+		AutoID:    true,
 		Type:      MessageTypeOsmoBroadcastConfig,
 		Payload:   config.Payload(),
 	}
 }
+
+func init() {
+	RegisterPayload(MessageTypeOsmoBroadcastConfig, func() Payload { return &BroadcastConfig{} })
+}
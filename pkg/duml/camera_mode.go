@@ -0,0 +1,86 @@
+package duml
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CameraMode is the capture mode of the camera.
+type CameraMode int
+
+const (
+	CameraModeUndefined = CameraMode(iota)
+	CameraModeVideo
+	CameraModePhoto
+	CameraModeSlowMotion
+	CameraModeTimelapse
+	CameraModeHyperlapse
+	endOfCameraMode
+)
+
+func (m CameraMode) String() string {
+	switch m {
+	case CameraModeVideo:
+		return "video"
+	case CameraModePhoto:
+		return "photo"
+	case CameraModeSlowMotion:
+		return "slow-motion"
+	case CameraModeTimelapse:
+		return "timelapse"
+	case CameraModeHyperlapse:
+		return "hyperlapse"
+	default:
+		return "<undefined>"
+	}
+}
+
+// CameraModeFromString is the reverse of CameraMode.String (case-insensitive).
+func CameraModeFromString(s string) CameraMode {
+	s = strings.ToLower(s)
+	for m := CameraModeUndefined + 1; m < endOfCameraMode; m++ {
+		if m.String() == s {
+			return m
+		}
+	}
+	return CameraModeUndefined
+}
+
+// defaultCameraModeBytes is the encoding of the camera modes used if the
+// profile of the device does not define one (see DeviceProfile.CameraModes).
+//
+// The values are experimental (see KeyValueKey.IsExperimental).
+var defaultCameraModeBytes = map[CameraMode]byte{
+	CameraModePhoto:      0x00,
+	CameraModeVideo:      0x01,
+	CameraModeTimelapse:  0x02,
+	CameraModeSlowMotion: 0x03,
+	CameraModeHyperlapse: 0x04,
+}
+
+func (t DeviceType) cameraModeBytes() map[CameraMode]byte {
+	p, _ := t.Profile()
+	return profileEncoding(p.CameraModes, CameraModeFromString, defaultCameraModeBytes)
+}
+
+// CameraModes returns the camera modes supported by the device type, in the
+// order of their values; all the modes, if the supported modes are not known.
+func (t DeviceType) CameraModes() []CameraMode {
+	return sortedKeys(t.cameraModeBytes())
+}
+
+// EncodeCameraMode returns the value of KeyValueKeyCameraMode selecting the mode,
+// or an error if the mode is not supported by the device type.
+func (t DeviceType) EncodeCameraMode(m CameraMode) (byte, error) {
+	b, ok := t.cameraModeBytes()[m]
+	if !ok {
+		return 0, fmt.Errorf("camera mode %s is not supported by %s (supported: %v)", m, t, t.CameraModes())
+	}
+	return b, nil
+}
+
+// DecodeCameraMode is the reverse of EncodeCameraMode; it returns
+// CameraModeUndefined if the value is not known.
+func (t DeviceType) DecodeCameraMode(b byte) CameraMode {
+	return decodeEnum(t.cameraModeBytes(), b)
+}
@@ -0,0 +1,56 @@
+package duml
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCameraModeEncoding(t *testing.T) {
+	for m := CameraModeUndefined + 1; m < endOfCameraMode; m++ {
+		require.Equal(t, m, CameraModeFromString(m.String()))
+	}
+	require.Equal(t, CameraModeUndefined, CameraModeFromString("panorama"))
+
+	for _, typ := range []DeviceType{DeviceTypeOsmoAction4, DeviceTypeOsmoAction5Pro, DeviceTypeOsmoPocket3, DeviceTypeMavic3} {
+		for _, m := range typ.CameraModes() {
+			b, err := typ.EncodeCameraMode(m)
+			require.NoError(t, err)
+			require.Equal(t, m, typ.DecodeCameraMode(b), "%s: %s", typ, m)
+		}
+	}
+
+	b, err := DeviceTypeOsmoAction5Pro.EncodeCameraMode(CameraModeVideo)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x00), b)
+	b, err = DeviceTypeOsmoAction4.EncodeCameraMode(CameraModeVideo)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x01), b)
+
+	require.NotContains(t, DeviceTypeOsmoPocket3.CameraModes(), CameraModeHyperlapse)
+	_, err = DeviceTypeOsmoPocket3.EncodeCameraMode(CameraModeHyperlapse)
+	require.Error(t, err)
+
+	// the modes of the models without a known list are not restricted
+	require.Len(t, DeviceTypeMavic3.CameraModes(), int(endOfCameraMode-1))
+}
+
+func TestCameraModeGoldenFrame(t *testing.T) {
+	b, err := DeviceTypeOsmoPocket3.EncodeCameraMode(CameraModeSlowMotion)
+	require.NoError(t, err)
+	msg, err := NewMessage(InterfaceIDAppToCamera, 0x1234, MessageTypeStartStopStreaming, NewKeyValueSetRequest(KeyValueKeyCameraMode, b))
+	require.NoError(t, err)
+	// set (0x01), 1 item: key 0x0002, 1 byte: 0x03
+	require.Equal(t, "551304030201123440028e0101020001032c38", hex.EncodeToString(msg.Bytes()))
+}
+
+func TestLoadDeviceProfilesInvalidCameraModes(t *testing.T) {
+	_, err := LoadDeviceProfiles(strings.NewReader(`
+- name: test-camera-modes
+  magic: "7e00"
+  camera_modes: {video: "01", photo: "01"}
+`))
+	require.Error(t, err)
+}
@@ -0,0 +1,37 @@
+package duml
+
+import (
+	"context"
+)
+
+// Conn is a connection to a DJI device, independent of the transport (BLE, WiFi, serial, ...).
+type Conn interface {
+	// SendMessage sends the message without waiting for a response; if
+	// Message.AutoID is set, then an ID is allocated (and set in the message).
+	SendMessage(ctx context.Context, msg *Message) error
+
+	// Request sends the message and waits for the response to it.
+	Request(ctx context.Context, msg *Message, opts ...RequestOption) (*Message, error)
+
+	// Subscribe returns an independent buffered stream of the received messages passing
+	// the filter. The Subscription is closed when the context is cancelled or the
+	// connection is lost.
+	Subscribe(ctx context.Context, filter MessageFilter, opts ...SubscribeOption) *Subscription
+
+	// DeviceType returns the model of the device (DeviceTypeUndefined if unknown).
+	DeviceType() DeviceType
+}
+
+// ReceiveMessage waits for the next message of the given type received by the connection.
+//
+// Messages received before the call are not returned; use Conn.Subscribe
+// to not miss them.
+func ReceiveMessage(
+	ctx context.Context,
+	conn Conn,
+	msgType MessageType,
+) (*Message, error) {
+	sub := conn.Subscribe(ctx, FilterType(msgType))
+	defer sub.Close()
+	return sub.Receive(ctx)
+}
@@ -0,0 +1,160 @@
+package duml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// headerLength is the length of the part covered by the header CRC8
+	// (magic + length/version) plus the CRC8 itself.
+	headerLength = 4
+
+	maxMessageLength = 0x3FF
+)
+
+// Decoder splits an arbitrary byte stream into DUML frames.
+//
+// Unlike ParseMessage it does not give up on garbage: it skips bytes until
+// the next 0x55 magic byte that starts a frame with a valid header CRC8
+// and a valid CRC16, so it could be used on top of any transport that
+// delivers bytes (BLE notifications, UDP datagrams, a serial port, ...).
+//
+// A Decoder is not safe for concurrent use.
+type Decoder struct {
+	buf          []byte
+	droppedBytes uint64
+}
+
+// NewDecoder returns a new Decoder with an empty buffer.
+func NewDecoder() *Decoder {
+	return &Decoder{}
+}
+
+// Write appends the given bytes to the internal buffer. The frames could
+// be extracted afterwards using Next.
+//
+// It never returns an error; it implements io.Writer.
+func (d *Decoder) Write(b []byte) (int, error) {
+	d.buf = append(d.buf, b...)
+	return len(b), nil
+}
+
+// Decode appends the given bytes to the internal buffer and returns
+// all the frames that became complete.
+func (d *Decoder) Decode(b []byte) []*Message {
+	must(d.Write(b))
+	var result []*Message
+	for {
+		msg := d.Next()
+		if msg == nil {
+			return result
+		}
+		result = append(result, msg)
+	}
+}
+
+// Next extracts the next complete frame from the internal buffer.
+// It returns nil if more data is required.
+func (d *Decoder) Next() *Message {
+	for {
+		idx := bytes.IndexByte(d.buf, MessageStartMagicByte)
+		if idx < 0 {
+			d.drop(len(d.buf))
+			return nil
+		}
+		d.drop(idx)
+
+		if len(d.buf) < headerLength {
+			return nil
+		}
+		totalLength, ok := checkHeader(d.buf[:headerLength])
+		if !ok {
+			d.drop(1)
+			continue
+		}
+
+		if len(d.buf) < totalLength {
+			return nil
+		}
+
+		msg, err := ParseMessage(d.buf[:totalLength])
+		if err != nil {
+			d.drop(1)
+			continue
+		}
+		d.consume(totalLength)
+		return msg
+	}
+}
+
+// checkHeader validates the first 4 bytes of a frame, and returns the total length
+// of the frame if the header is valid.
+func checkHeader(hdr []byte) (int, bool) {
+	totalLength := int(hdr[1]) | (int(hdr[2]&0x03) << 8)
+	if totalLength < totalLengthHeadersAndTail {
+		return 0, false
+	}
+	if hdr[2]>>2 != ProtocolVersion {
+		return 0, false
+	}
+	if crc8(hdr[:3]) != hdr[3] {
+		return 0, false
+	}
+	return totalLength, true
+}
+
+// ReadMessages reads the stream until an error (including io.EOF) and calls
+// the callback for each decoded frame. If the callback returns an error, the
+// reading stops and the error is returned.
+func (d *Decoder) ReadMessages(
+	r io.Reader,
+	callback func(*Message) error,
+) error {
+	buf := make([]byte, maxMessageLength+1)
+	for {
+		n, err := r.Read(buf)
+		for _, msg := range d.Decode(buf[:n]) {
+			if err := callback(msg); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return err
+			}
+			return fmt.Errorf("unable to read: %w", err)
+		}
+	}
+}
+
+// DroppedBytes returns the total amount of bytes skipped so far
+// while looking for valid frames.
+func (d *Decoder) DroppedBytes() uint64 {
+	return d.droppedBytes
+}
+
+// Buffered returns the amount of bytes waiting for the rest of the frame.
+func (d *Decoder) Buffered() int {
+	return len(d.buf)
+}
+
+// Reset drops the internal buffer (without counting it as dropped bytes).
+func (d *Decoder) Reset() {
+	d.buf = d.buf[:0]
+}
+
+func (d *Decoder) drop(n int) {
+	d.droppedBytes += uint64(n)
+	d.consume(n)
+}
+
+func (d *Decoder) consume(n int) {
+	if n == len(d.buf) {
+		d.buf = d.buf[:0]
+		return
+	}
+	d.buf = append(d.buf[:0], d.buf[n:]...)
+}
@@ -0,0 +1,77 @@
+package duml
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func TestDecoder(t *testing.T) {
+	frame0 := mustDecodeHex(t, "551204c70402f6010004270000080000299d")
+	frame1 := mustDecodeHex(t, "550d04330207ea94400707242b")
+	frame2 := mustDecodeHex(t, "551f044e0702ea94c0070700104f736d6f506f636b6574332d36303934ccc8")
+
+	t.Run("multiple_frames_in_one_chunk", func(t *testing.T) {
+		d := NewDecoder()
+		msgs := d.Decode(bytes.Join([][]byte{frame0, frame1, frame2}, nil))
+		require.Len(t, msgs, 3)
+		assert.Equal(t, MessageTypeKeepAlive, msgs[0].Type)
+		assert.Equal(t, InterfaceIDAppToWiFiGroundStation, msgs[1].Interface)
+		assert.Equal(t, MessageTypeCameraAPInfoResultSSID, msgs[2].Type)
+		assert.Zero(t, d.DroppedBytes())
+		assert.Zero(t, d.Buffered())
+	})
+
+	t.Run("fragmented", func(t *testing.T) {
+		d := NewDecoder()
+		stream := append(append([]byte{}, frame2...), frame1...)
+		var msgs []*Message
+		for _, b := range stream {
+			msgs = append(msgs, d.Decode([]byte{b})...)
+		}
+		require.Len(t, msgs, 2)
+		assert.Equal(t, MessageTypeCameraAPInfoResultSSID, msgs[0].Type)
+		assert.Equal(t, MessageID(0xea94), msgs[1].ID)
+		assert.Zero(t, d.DroppedBytes())
+	})
+
+	t.Run("garbage", func(t *testing.T) {
+		d := NewDecoder()
+		garbage := []byte{0x00, 0x55, 0x01, 0x55, 0x55, 0xFF}
+		msgs := d.Decode(append(append([]byte{}, garbage...), frame1...))
+		require.Len(t, msgs, 1)
+		assert.Equal(t, uint64(len(garbage)), d.DroppedBytes())
+	})
+
+	t.Run("corrupted_crc16", func(t *testing.T) {
+		d := NewDecoder()
+		corrupted := append([]byte{}, frame0...)
+		corrupted[len(corrupted)-1] ^= 0xFF
+		msgs := d.Decode(bytes.Join([][]byte{corrupted, frame1}, nil))
+		require.Len(t, msgs, 1)
+		assert.Equal(t, MessageID(0xea94), msgs[0].ID)
+		assert.Equal(t, uint64(len(corrupted)), d.DroppedBytes())
+	})
+
+	t.Run("read_messages", func(t *testing.T) {
+		d := NewDecoder()
+		var msgs []*Message
+		err := d.ReadMessages(bytes.NewReader(bytes.Join([][]byte{frame0, {0x01, 0x02}, frame1}, nil)), func(msg *Message) error {
+			msgs = append(msgs, msg)
+			return nil
+		})
+		require.ErrorIs(t, err, io.EOF)
+		require.Len(t, msgs, 2)
+		assert.Equal(t, uint64(2), d.DroppedBytes())
+	})
+}
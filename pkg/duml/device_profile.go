@@ -0,0 +1,357 @@
+package duml
+
+import (
+	"bytes"
+	"cmp"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed profiles/*.yaml
+var embeddedDeviceProfiles embed.FS
+
+const (
+	defaultStartStreamingByte       = 0x2A
+	defaultSetImageStabilizationKey = 0x08
+)
+
+// HexBytes is a byte slice represented as a hex string in YAML and JSON.
+type HexBytes []byte
+
+// MarshalText implements encoding.TextMarshaler.
+func (b HexBytes) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(b)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *HexBytes) UnmarshalText(text []byte) error {
+	v, err := hex.DecodeString(string(text))
+	if err != nil {
+		return fmt.Errorf("unable to decode hex '%s': %w", text, err)
+	}
+	*b = v
+	return nil
+}
+
+// BitrateRange is an inclusive range of bitrates; a zero bound is not restricted.
+type BitrateRange struct {
+	Min uint16 `yaml:"min,omitempty" json:"min,omitempty"`
+	Max uint16 `yaml:"max,omitempty" json:"max,omitempty"`
+}
+
+// DeviceProfile describes the quirks and the capabilities of a device model.
+//
+// The empty capability lists mean the capabilities are not known (and
+// so not restricted); the empty quirk bytes mean the defaults.
+type DeviceProfile struct {
+	// Name is the DeviceType.String of the model, e.g. "osmo-pocket-3".
+	Name string `yaml:"name" json:"name"`
+
+	// Magic is the 2 bytes of the BLE advertisement manufacturer data
+	// following the DJI prefix (see IdentifyDeviceType).
+	Magic HexBytes `yaml:"magic" json:"magic"`
+
+	// StartStreamingByte is the device-specific byte of LiveStreamConfig (0x2A by default).
+	StartStreamingByte HexBytes `yaml:"start_streaming_byte,omitempty" json:"start_streaming_byte,omitempty"`
+
+	// SetImageStabilizationKey is the KeyValueKey of the image stabilization (0x08 by default).
+	SetImageStabilizationKey HexBytes `yaml:"set_image_stabilization_key,omitempty" json:"set_image_stabilization_key,omitempty"`
+
+	Resolutions         []string     `yaml:"resolutions,omitempty" json:"resolutions,omitempty"`
+	FPS                 []uint       `yaml:"fps,omitempty" json:"fps,omitempty"`
+	ImageStabilizations []string     `yaml:"image_stabilizations,omitempty" json:"image_stabilizations,omitempty"`
+	BitrateKbps         BitrateRange `yaml:"bitrate_kbps,omitempty" json:"bitrate_kbps,omitempty"`
+
+	// DefaultImageStabilization is set before starting a live stream (if not empty).
+	DefaultImageStabilization string `yaml:"default_image_stabilization,omitempty" json:"default_image_stabilization,omitempty"`
+
+	// CameraModes are the supported camera modes and their values of KeyValueKeyCameraMode.
+	CameraModes map[string]HexBytes `yaml:"camera_modes,omitempty" json:"camera_modes,omitempty"`
+
+	// VideoResolutions, VideoFPS, AspectRatios and Orientations are the supported
+	// values of the fields of VideoFormat and their encodings.
+	VideoResolutions map[string]HexBytes `yaml:"video_resolutions,omitempty" json:"video_resolutions,omitempty"`
+	VideoFPS         map[string]HexBytes `yaml:"video_fps,omitempty" json:"video_fps,omitempty"`
+	AspectRatios     map[string]HexBytes `yaml:"aspect_ratios,omitempty" json:"aspect_ratios,omitempty"`
+	Orientations     map[string]HexBytes `yaml:"orientations,omitempty" json:"orientations,omitempty"`
+
+	// ColorProfiles are the supported color profiles (see ColorProfile).
+	ColorProfiles []string `yaml:"color_profiles,omitempty" json:"color_profiles,omitempty"`
+
+	// BatteryStatusLayout is the name of the layout of MessageTypeBatteryStatus:
+	// "long" (21 bytes) or "short" (13 bytes); if empty, then the layout is
+	// guessed by the length of the payload.
+	BatteryStatusLayout string `yaml:"battery_status_layout,omitempty" json:"battery_status_layout,omitempty"`
+
+	// Gimbal is true if the model has a gimbal controlled via InterfaceIDAppToGimbal
+	// and pushing GimbalStatus.
+	Gimbal bool `yaml:"gimbal,omitempty" json:"gimbal,omitempty"`
+}
+
+// Validate checks the lengths of the bytes and the names of the capabilities.
+func (p *DeviceProfile) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("the name is empty")
+	}
+	if len(p.Magic) != 2 {
+		return fmt.Errorf("the magic should be 2 bytes, got %d", len(p.Magic))
+	}
+	if len(p.StartStreamingByte) > 1 {
+		return fmt.Errorf("start_streaming_byte should be 1 byte, got %d", len(p.StartStreamingByte))
+	}
+	if len(p.SetImageStabilizationKey) > 1 {
+		return fmt.Errorf("set_image_stabilization_key should be 1 byte, got %d", len(p.SetImageStabilizationKey))
+	}
+	for _, s := range p.Resolutions {
+		if ResolutionFromString(s) == UndefinedResolution {
+			return fmt.Errorf("unknown resolution '%s'", s)
+		}
+	}
+	for _, v := range p.FPS {
+		if FPSFromUint(v) == UndefinedFPS {
+			return fmt.Errorf("unsupported FPS %d", v)
+		}
+	}
+	for _, s := range append(slices.Clone(p.ImageStabilizations), p.DefaultImageStabilization) {
+		if s != "" && ImageStabilizationFromString(s) == ImageStabilizationUndefined {
+			return fmt.Errorf("unknown image stabilization '%s'", s)
+		}
+	}
+	if _, ok := batteryStatusLayouts[p.BatteryStatusLayout]; p.BatteryStatusLayout != "" && !ok {
+		return fmt.Errorf("unknown battery status layout '%s'", p.BatteryStatusLayout)
+	}
+	for _, s := range p.ColorProfiles {
+		if _, err := ColorProfileFromString(s); err != nil {
+			return err
+		}
+	}
+	for _, enc := range []struct {
+		Kind   string
+		Values map[string]HexBytes
+		Known  func(string) bool
+	}{
+		{"camera mode", p.CameraModes, func(s string) bool { return CameraModeFromString(s) != CameraModeUndefined }},
+		{"video resolution", p.VideoResolutions, func(s string) bool { return VideoResolutionFromString(s) != VideoResolutionUndefined }},
+		{"video FPS", p.VideoFPS, func(s string) bool { return VideoFPSFromString(s) != 0 }},
+		{"aspect ratio", p.AspectRatios, func(s string) bool { return AspectRatioFromString(s) != AspectRatioUndefined }},
+		{"orientation", p.Orientations, func(s string) bool { return OrientationFromString(s) != OrientationUndefined }},
+	} {
+		if err := validateEncoding(enc.Kind, enc.Values, enc.Known); err != nil {
+			return err
+		}
+	}
+	if p.BitrateKbps.Max != 0 && p.BitrateKbps.Min > p.BitrateKbps.Max {
+		return fmt.Errorf("the minimal bitrate %d is greater than the maximal %d", p.BitrateKbps.Min, p.BitrateKbps.Max)
+	}
+	return nil
+}
+
+// validateEncoding checks that the names are known and the values are single and unique bytes.
+func validateEncoding(kind string, values map[string]HexBytes, known func(string) bool) error {
+	names := map[byte]string{}
+	for name, b := range values {
+		if !known(name) {
+			return fmt.Errorf("unknown %s '%s'", kind, name)
+		}
+		if len(b) != 1 {
+			return fmt.Errorf("the value of %s '%s' should be 1 byte, got %d", kind, name, len(b))
+		}
+		if other, ok := names[b[0]]; ok {
+			return fmt.Errorf("%ss '%s' and '%s' have the same value 0x%02X", kind, other, name, b[0])
+		}
+		names[b[0]] = name
+	}
+	return nil
+}
+
+// profileEncoding returns the encoding defined by the profile, or the default
+// one if the profile does not define it.
+func profileEncoding[T comparable](values map[string]HexBytes, parse func(string) T, defaults map[T]byte) map[T]byte {
+	if len(values) == 0 {
+		return defaults
+	}
+	result := make(map[T]byte, len(values))
+	for name, b := range values {
+		result[parse(name)] = b[0]
+	}
+	return result
+}
+
+// decodeEnum returns the value encoded as b, or the zero value if b is not known.
+func decodeEnum[T comparable](values map[T]byte, b byte) T {
+	for v, vb := range values {
+		if vb == b {
+			return v
+		}
+	}
+	var zero T
+	return zero
+}
+
+func sortedKeys[T cmp.Ordered](m map[T]byte) []T {
+	keys := make([]T, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// CheckLiveStreamConfig returns an error if the live stream parameters
+// are known to be not supported by the model.
+func (p *DeviceProfile) CheckLiveStreamConfig(
+	resolution Resolution,
+	bitrateKbps uint16,
+	fps FPS,
+) error {
+	if len(p.Resolutions) > 0 && !slices.ContainsFunc(p.Resolutions, func(s string) bool {
+		return ResolutionFromString(s) == resolution
+	}) {
+		return fmt.Errorf("resolution %s is not supported by %s (supported: %s)", resolution, p.Name, strings.Join(p.Resolutions, ", "))
+	}
+	if len(p.FPS) > 0 && !slices.ContainsFunc(p.FPS, func(v uint) bool {
+		return FPSFromUint(v) == fps
+	}) {
+		return fmt.Errorf("FPS %s is not supported by %s (supported: %v)", fps, p.Name, p.FPS)
+	}
+	if (p.BitrateKbps.Min != 0 && bitrateKbps < p.BitrateKbps.Min) || (p.BitrateKbps.Max != 0 && bitrateKbps > p.BitrateKbps.Max) {
+		return fmt.Errorf("bitrate %dKbps is out of the range [%d, %d] of %s", bitrateKbps, p.BitrateKbps.Min, p.BitrateKbps.Max, p.Name)
+	}
+	return nil
+}
+
+// SupportsImageStabilization returns false if the image stabilization mode is
+// known to be not supported by the model.
+func (p *DeviceProfile) SupportsImageStabilization(v ImageStabilization) bool {
+	return len(p.ImageStabilizations) == 0 || slices.ContainsFunc(p.ImageStabilizations, func(s string) bool {
+		return ImageStabilizationFromString(s) == v
+	})
+}
+
+// SupportsColorProfile returns false if the color profile is known to be not
+// supported by the model.
+func (p *DeviceProfile) SupportsColorProfile(v ColorProfile) bool {
+	return len(p.ColorProfiles) == 0 || slices.ContainsFunc(p.ColorProfiles, func(s string) bool {
+		return strings.EqualFold(s, v.String())
+	})
+}
+
+var (
+	deviceProfilesLocker sync.RWMutex
+	deviceProfiles       = map[DeviceType]*DeviceProfile{}
+	nextDeviceType       = EndOfDeviceType
+)
+
+// RegisterDeviceProfile adds the profile to the registry (replacing the profile
+// with the same name, if any) and returns the DeviceType of the model; a model
+// unknown to this package gets a new DeviceType.
+func RegisterDeviceProfile(p DeviceProfile) (DeviceType, error) {
+	if err := p.Validate(); err != nil {
+		return DeviceTypeUndefined, fmt.Errorf("invalid profile '%s': %w", p.Name, err)
+	}
+
+	t := DeviceTypeFromString(p.Name)
+
+	deviceProfilesLocker.Lock()
+	defer deviceProfilesLocker.Unlock()
+	if t == DeviceTypeUndefined {
+		t = nextDeviceType
+		nextDeviceType++
+	}
+	deviceProfiles[t] = &p
+	return t, nil
+}
+
+// LoadDeviceProfiles reads a YAML (or JSON) list of profiles and registers them.
+func LoadDeviceProfiles(r io.Reader) ([]DeviceType, error) {
+	var profiles []DeviceProfile
+	if err := yaml.NewDecoder(r).Decode(&profiles); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("unable to decode the profiles: %w", err)
+	}
+	types := make([]DeviceType, 0, len(profiles))
+	for _, p := range profiles {
+		t, err := RegisterDeviceProfile(p)
+		if err != nil {
+			return types, err
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// LoadDeviceProfilesFile is the same as LoadDeviceProfiles, but reads the file;
+// a file with the ".json" extension is decoded as JSON.
+func LoadDeviceProfilesFile(path string) ([]DeviceType, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the profiles: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var profiles []DeviceProfile
+		if err := json.Unmarshal(b, &profiles); err != nil {
+			return nil, fmt.Errorf("unable to decode the profiles: %w", err)
+		}
+		if b, err = yaml.Marshal(profiles); err != nil {
+			return nil, fmt.Errorf("unable to convert the profiles: %w", err)
+		}
+	}
+	types, err := LoadDeviceProfiles(bytes.NewReader(b))
+	if err != nil {
+		return types, fmt.Errorf("unable to load '%s': %w", path, err)
+	}
+	return types, nil
+}
+
+// Profile returns the profile of the device type.
+func (t DeviceType) Profile() (DeviceProfile, bool) {
+	deviceProfilesLocker.RLock()
+	defer deviceProfilesLocker.RUnlock()
+	p, ok := deviceProfiles[t]
+	if !ok {
+		return DeviceProfile{}, false
+	}
+	return *p, true
+}
+
+// DeviceTypes returns all the device types that have a profile, in the order of their values.
+func DeviceTypes() []DeviceType {
+	deviceProfilesLocker.RLock()
+	defer deviceProfilesLocker.RUnlock()
+	types := make([]DeviceType, 0, len(deviceProfiles))
+	for t := range deviceProfiles {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
+func init() {
+	err := fs.WalkDir(embeddedDeviceProfiles, "profiles", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		f, err := embeddedDeviceProfiles.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err := LoadDeviceProfiles(f); err != nil {
+			return fmt.Errorf("unable to load '%s': %w", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,81 @@
+package duml
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedDeviceProfiles(t *testing.T) {
+	for typ := DeviceTypeUndefined + 2; typ < EndOfDeviceType; typ++ {
+		p, ok := typ.Profile()
+		require.True(t, ok, typ)
+		require.Equal(t, typ.String(), p.Name)
+	}
+	require.Equal(t, [2]byte{0x20, 0x00}, DeviceTypeOsmoPocket3.Magic())
+	require.Equal(t, [1]byte{0x2E}, DeviceTypeOsmoAction5Pro.BytesFixedStartStreaming())
+	require.Equal(t, [1]byte{0x1A}, DeviceTypeOsmoAction5Pro.BytesFixedSetImageStabilization())
+	require.Equal(t, [1]byte{0x2A}, DeviceTypeMavic3.BytesFixedStartStreaming())
+	require.Equal(t, [1]byte{0x2A}, DeviceTypeUnknown.BytesFixedStartStreaming())
+	require.Equal(t, [1]byte{0x08}, DeviceTypeUnknown.BytesFixedSetImageStabilization())
+	require.Equal(t, 21, DeviceTypeOsmoPocket3.BatteryStatusLength())
+	require.Equal(t, 13, DeviceTypeMavic3.BatteryStatusLength())
+
+	p, _ := DeviceTypeOsmoAction4.Profile()
+	require.Equal(t, ImageStabilizationRockSteadyPlus, ImageStabilizationFromString(p.DefaultImageStabilization))
+	require.True(t, p.SupportsImageStabilization(ImageStabilizationHorizonSteady))
+	require.NoError(t, p.CheckLiveStreamConfig(Resolution1080p, 6000, FPS30))
+	require.Error(t, p.CheckLiveStreamConfig(Resolution1080p, 6000, FPS24))
+	require.Error(t, p.CheckLiveStreamConfig(Resolution1080p, 60000, FPS30))
+}
+
+func TestLoadDeviceProfiles(t *testing.T) {
+	types, err := LoadDeviceProfiles(strings.NewReader(`
+- name: test-new-model
+  magic: "7f00"
+  start_streaming_byte: "33"
+  resolutions: [720p]
+`))
+	require.NoError(t, err)
+	require.Len(t, types, 1)
+	typ := types[0]
+	require.GreaterOrEqual(t, typ, EndOfDeviceType)
+	require.Equal(t, "test-new-model", typ.String())
+	require.Equal(t, typ, DeviceTypeFromString("Test-New-Model"))
+	require.Equal(t, typ, IdentifyDeviceType(typ.ManufacturerData()))
+	require.Equal(t, [1]byte{0x33}, typ.BytesFixedStartStreaming())
+	require.Equal(t, [1]byte{0x08}, typ.BytesFixedSetImageStabilization())
+	p, _ := typ.Profile()
+	require.Error(t, p.CheckLiveStreamConfig(Resolution1080p, 6000, FPS30))
+	require.True(t, p.SupportsImageStabilization(ImageStabilizationOff))
+	require.Zero(t, typ.BatteryStatusLength())
+
+	// a profile of a known model replaces the embedded one
+	orig, _ := DeviceTypeOsmoPocket3.Profile()
+	defer func() {
+		_, err := RegisterDeviceProfile(orig)
+		require.NoError(t, err)
+	}()
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"name": "osmo-pocket-3", "magic": "2000", "start_streaming_byte": "44"}]`), 0644))
+	types, err = LoadDeviceProfilesFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []DeviceType{DeviceTypeOsmoPocket3}, types)
+	require.Equal(t, [1]byte{0x44}, DeviceTypeOsmoPocket3.BytesFixedStartStreaming())
+
+	for _, invalid := range []string{
+		`[{name: x}]`,
+		`[{name: x, magic: "01"}]`,
+		`[{name: x, magic: "0100", resolutions: [4k]}]`,
+		`[{name: x, magic: "0100", image_stabilizations: [wobbly]}]`,
+		`[{name: x, magic: "0100", fps: [7]}]`,
+		`[{name: x, magic: "0100", battery_status_layout: medium}]`,
+		`not a list`,
+	} {
+		_, err := LoadDeviceProfiles(strings.NewReader(invalid))
+		require.Error(t, err, invalid)
+	}
+}
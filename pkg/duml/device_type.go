@@ -1,6 +1,10 @@
 package duml
 
-import "bytes"
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
 
 type DeviceType int
 
@@ -17,40 +21,83 @@ const (
 	EndOfDeviceType
 )
 
-func (t DeviceType) Magic() [2]byte {
+func (t DeviceType) String() string {
 	switch t {
+	case DeviceTypeUndefined:
+		return "<undefined>"
+	case DeviceTypeUnknown:
+		return "unknown"
 	case DeviceTypeOsmoAction3:
-		return [2]byte{0x12, 0x00}
+		return "osmo-action-3"
 	case DeviceTypeOsmoAction4:
-		return [2]byte{0x14, 0x00}
+		return "osmo-action-4"
 	case DeviceTypeOsmoAction5Pro:
-		return [2]byte{0x15, 0x00}
+		return "osmo-action-5-pro"
 	case DeviceTypeOsmoPocket3:
-		return [2]byte{0x20, 0x00}
-	case DeviceTypeAir2S:
-		return [2]byte{0x17, 0x00}
+		return "osmo-pocket-3"
 	case DeviceTypeMiniSE:
-		return [2]byte{0x19, 0x00}
+		return "mini-se"
+	case DeviceTypeAir2S:
+		return "air-2s"
 	case DeviceTypeMavic3:
-		return [2]byte{0x1C, 0x00}
+		return "mavic-3"
+	}
+	if p, ok := t.Profile(); ok {
+		return p.Name
+	}
+	return fmt.Sprintf("<unexpected:%d>", int(t))
+}
+
+// DeviceTypeFromString is the reverse of DeviceType.String (case-insensitive);
+// it also finds the device types added by RegisterDeviceProfile.
+func DeviceTypeFromString(s string) DeviceType {
+	s = strings.ToLower(s)
+	for t := DeviceTypeUndefined + 2; t < EndOfDeviceType; t++ {
+		if t.String() == s {
+			return t
+		}
+	}
+	for _, t := range DeviceTypes() {
+		if t.String() == s {
+			return t
+		}
+	}
+	return DeviceTypeUndefined
+}
+
+// ManufacturerData returns the BLE advertisement manufacturer data identifying the device type
+// (see IdentifyDeviceType).
+func (t DeviceType) ManufacturerData() []byte {
+	magic := t.Magic()
+	return append(append([]byte{}, djiMagic...), magic[:]...)
+}
+
+// Magic returns the bytes of the advertisement manufacturer data identifying the device type
+// (see DeviceProfile.Magic).
+func (t DeviceType) Magic() [2]byte {
+	var magic [2]byte
+	if p, ok := t.Profile(); ok {
+		copy(magic[:], p.Magic)
 	}
-	return [2]byte{0, 0}
+	return magic
 }
 
+// BytesFixedStartStreaming returns the device-specific byte of LiveStreamConfig
+// (see DeviceProfile.StartStreamingByte).
 func (t DeviceType) BytesFixedStartStreaming() [1]byte {
-	switch t {
-	case DeviceTypeOsmoAction5Pro:
-		return [1]byte{0x2E}
+	if p, ok := t.Profile(); ok && len(p.StartStreamingByte) == 1 {
+		return [1]byte(p.StartStreamingByte)
 	}
-	return [1]byte{0x2A}
+	return [1]byte{defaultStartStreamingByte}
 }
 
+// BytesFixedSetImageStabilization returns the KeyValueKey of the image stabilization
+// (see DeviceProfile.SetImageStabilizationKey).
 func (t DeviceType) BytesFixedSetImageStabilization() [1]byte {
-	switch t {
-	case DeviceTypeOsmoAction5Pro:
-		return [1]byte{0x1A}
+	if p, ok := t.Profile(); ok && len(p.SetImageStabilizationKey) == 1 {
+		return [1]byte(p.SetImageStabilizationKey)
 	}
-	return [1]byte{0x08}
+	return [1]byte{defaultSetImageStabilizationKey}
 }
 
 var djiMagic = []byte{0xAA, 0x08}
@@ -59,8 +106,11 @@ func IdentifyDeviceType(manufacturerData []byte) DeviceType {
 	if !bytes.HasPrefix(manufacturerData, djiMagic) {
 		return DeviceTypeUndefined
 	}
+	if len(manufacturerData) < len(djiMagic)+2 {
+		return DeviceTypeUnknown
+	}
 
-	for t := DeviceTypeUndefined + 2; t < EndOfDeviceType; t++ {
+	for _, t := range DeviceTypes() {
 		magic := t.Magic()
 		if bytes.Equal(manufacturerData[2:4], magic[:]) {
 			return t
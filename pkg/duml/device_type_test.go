@@ -0,0 +1,16 @@
+package duml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceTypeStringAndManufacturerData(t *testing.T) {
+	for typ := DeviceTypeUndefined + 2; typ < EndOfDeviceType; typ++ {
+		require.Equal(t, typ, DeviceTypeFromString(typ.String()))
+		require.Equal(t, typ, IdentifyDeviceType(typ.ManufacturerData()))
+	}
+	require.Equal(t, DeviceTypeOsmoPocket3, DeviceTypeFromString("Osmo-Pocket-3"))
+	require.Equal(t, DeviceTypeUndefined, DeviceTypeFromString("unknown"))
+}
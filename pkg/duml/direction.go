@@ -0,0 +1,48 @@
+package duml
+
+import (
+	"fmt"
+)
+
+// Direction is the direction of a frame as seen by the app.
+type Direction uint8
+
+const (
+	DirectionUndefined = Direction(iota)
+
+	// DirectionSent is a frame sent by the app to the device.
+	DirectionSent
+
+	// DirectionReceived is a frame received by the app from the device.
+	DirectionReceived
+)
+
+func (d Direction) String() string {
+	switch d {
+	case DirectionUndefined:
+		return "<undefined>"
+	case DirectionSent:
+		return "sent"
+	case DirectionReceived:
+		return "received"
+	}
+	return fmt.Sprintf("<unexpected:%d>", int(d))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Direction) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Direction) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "sent":
+		*d = DirectionSent
+	case "received":
+		*d = DirectionReceived
+	default:
+		return fmt.Errorf("unknown direction '%s'", b)
+	}
+	return nil
+}
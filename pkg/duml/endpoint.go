@@ -0,0 +1,181 @@
+package duml
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+)
+
+// Endpoint is the transport-independent part of a Conn: it allocates message IDs,
+// matches responses to requests, fans out the received messages to subscribers
+// and acknowledges the messages that require it.
+//
+// A transport provides the function to send a message and passes each received
+// message to HandleMessage.
+type Endpoint struct {
+	Local         ComponentID
+	Sequencer     *Sequencer
+	Requests      *RequestTable
+	Subscriptions *Subscriptions
+
+	// Parameters are the last values received in MessageTypeParameterPush.
+	Parameters *ParameterCache
+
+	// NoAutoACK disables acknowledging the received messages in HandleMessage
+	// (e.g. to study how a device reacts to missing ACKs).
+	NoAutoACK atomic.Bool
+
+	send func(ctx context.Context, msg *Message) error
+}
+
+// NewEndpoint returns an Endpoint that uses the given function to send messages.
+func NewEndpoint(
+	firstID MessageID,
+	send func(ctx context.Context, msg *Message) error,
+) *Endpoint {
+	return &Endpoint{
+		Local:         ComponentIDApp,
+		Sequencer:     NewSequencer(firstID),
+		Requests:      NewRequestTable(),
+		Subscriptions: NewSubscriptions(),
+		Parameters:    NewParameterCache(),
+		send:          send,
+	}
+}
+
+// SendMessage sends the message; if Message.AutoID is set, then the ID
+// is allocated by the Sequencer (and set in the message).
+func (e *Endpoint) SendMessage(
+	ctx context.Context,
+	msg *Message,
+) error {
+	e.Sequencer.Assign(msg)
+	return e.send(ctx, msg)
+}
+
+// Request sends the message and waits for the response to it.
+//
+// If there is no response within the timeout (see RequestTimeout), the
+// message is retransmitted with the same ID (see RequestRetries). The
+// errors are ErrTimeout, ErrDisconnected or the error of the context.
+func (e *Endpoint) Request(
+	ctx context.Context,
+	msg *Message,
+	opts ...RequestOption,
+) (_ret *Message, _err error) {
+	logger.Tracef(ctx, "Request")
+	defer func() { logger.Tracef(ctx, "/Request: %v", _err) }()
+
+	if msg.Type.Flags&MessageTypeFlagAckRequired == 0 {
+		return nil, fmt.Errorf("Request() called for a message that does not require a response; use SendMessage() instead")
+	}
+
+	e.Sequencer.Assign(msg)
+	attempt := 0
+	resp, err := e.Requests.Do(
+		ctx,
+		NewResponseKey(msg, e.Local),
+		NewRequestConfig(opts...),
+		func(ctx context.Context) error {
+			attempt++
+			if attempt > 1 {
+				logger.Debugf(ctx, "no response to %s (ID %v), retransmitting (attempt #%d)", msg.Type, msg.ID, attempt)
+			}
+			return e.send(ctx, msg)
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("request %s (ID %v) failed: %w", msg.Type, msg.ID, err)
+	}
+	return resp, nil
+}
+
+// Subscribe returns an independent buffered stream of the received messages passing
+// the filter. The Subscription is closed when the context is cancelled or the
+// Endpoint is closed.
+//
+// To not miss a response, subscribe before sending the request.
+func (e *Endpoint) Subscribe(
+	ctx context.Context,
+	filter MessageFilter,
+	opts ...SubscribeOption,
+) *Subscription {
+	sub := e.Subscriptions.Subscribe(filter, opts...)
+	sub.closeWithContext(ctx)
+	return sub
+}
+
+// ReceiveMessage waits for the next message of the given type.
+//
+// Messages received before the call are not returned; use Subscribe
+// to not miss them.
+func (e *Endpoint) ReceiveMessage(
+	ctx context.Context,
+	msgType MessageType,
+) (_ret *Message, _err error) {
+	logger.Tracef(ctx, "ReceiveMessage")
+	defer func() { logger.Tracef(ctx, "/ReceiveMessage: %v", _err) }()
+	sub := e.Subscribe(ctx, FilterType(msgType))
+	defer sub.Close()
+	return sub.Receive(ctx)
+}
+
+// SendACK acknowledges the received message.
+func (e *Endpoint) SendACK(
+	ctx context.Context,
+	msg *Message,
+) error {
+	ack := &Message{
+		Interface: InterfaceID{
+			Sender:   msg.Interface.Receiver,
+			Receiver: msg.Interface.Sender,
+		},
+		ID:      msg.ID,
+		Type:    MessageTypeResponse(msg.Type.CmdSet, msg.Type.CmdID),
+		Payload: []byte{0x00},
+	}
+	// an ACK must use the ID of the acknowledged message, even if it is zero
+	return e.send(ctx, ack)
+}
+
+// HandleMessage processes a message received by the transport: it sends an ACK
+// if required (unless NoAutoACK is set), resolves the pending request (if it is a response),
+// updates the Parameters (if it is a parameter push), and publishes the message to the subscribers.
+func (e *Endpoint) HandleMessage(
+	ctx context.Context,
+	msg *Message,
+) {
+	logger.Debugf(ctx, "received duml.Message: %#+v", msg)
+	logger.Tracef(ctx, "payload: %X", msg.Payload)
+
+	if msg.Type.Flags&MessageTypeFlagAckRequired != 0 && !e.NoAutoACK.Load() {
+		logger.Debugf(ctx, "sending ACK for message %v", msg.Type)
+		if err := e.SendACK(ctx, msg); err != nil {
+			logger.Errorf(ctx, "unable to send ACK for message %v: %v", msg.Type, err)
+		}
+	}
+
+	isResponse := msg.Type.Flags&MessageTypeFlagResponse != 0
+	if isResponse {
+		key := NewResponseKey(msg, e.Local)
+		if !e.Requests.Resolve(key, msg) {
+			logger.Tracef(ctx, "nobody waits for response %+v, skipping", key)
+		}
+	}
+
+	e.Parameters.HandleMessage(ctx, msg)
+
+	if e.Subscriptions.Publish(msg) == 0 && !isResponse {
+		logger.Debugf(ctx, "nobody is subscribed to this message (%v), skipping", msg.Type)
+	}
+}
+
+// Close fails all the pending requests and closes all the subscriptions
+// with the given reason (e.g. ErrDisconnected).
+func (e *Endpoint) Close(reason error) {
+	e.Requests.Close(reason)
+	e.Subscriptions.CloseAll(reason)
+	e.Parameters.Close()
+}
@@ -0,0 +1,111 @@
+package duml
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointRequest(t *testing.T) {
+	ctx := context.Background()
+
+	var e *Endpoint
+	var sent []*Message
+	e = NewEndpoint(1, func(ctx context.Context, msg *Message) error {
+		sent = append(sent, msg)
+		if msg.Type.Flags&MessageTypeFlagResponse != 0 {
+			return nil
+		}
+		go e.HandleMessage(ctx, &Message{
+			Interface: InterfaceID{Sender: msg.Interface.Receiver, Receiver: msg.Interface.Sender},
+			ID:        msg.ID,
+			Type:      MessageTypeResponse(msg.Type.CmdSet, msg.Type.CmdID),
+			Payload:   []byte{0x00, 0x2A},
+		})
+		return nil
+	})
+
+	sub := e.Subscribe(ctx, FilterResponses(true))
+	resp, err := e.Request(ctx, &Message{
+		Interface: InterfaceIDAppToCamera,
+		Type:      MessageTypeRequest(CommandSetCamera, 1),
+		AutoID:    true,
+	}, RequestTimeout(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x00, 0x2A}, resp.Payload)
+	require.Len(t, sent, 1)
+	require.Equal(t, MessageID(1), sent[0].ID)
+
+	published, err := sub.Receive(ctx)
+	require.NoError(t, err)
+	require.Equal(t, resp, published)
+
+	_, err = e.Request(ctx, &Message{
+		Interface: InterfaceIDAppToCamera,
+		Type:      MessageTypeNotification(CommandSetCamera, 1),
+	})
+	require.Error(t, err)
+}
+
+func TestEndpointACKAndClose(t *testing.T) {
+	ctx := context.Background()
+
+	var sent []*Message
+	e := NewEndpoint(1, func(ctx context.Context, msg *Message) error {
+		sent = append(sent, msg)
+		return nil
+	})
+
+	e.HandleMessage(ctx, &Message{
+		Interface: InterfaceID{Sender: ComponentIDCamera, Receiver: ComponentIDApp},
+		ID:        0,
+		Type:      MessageTypeRequest(CommandSetCamera, 2),
+	})
+	require.Len(t, sent, 1)
+	require.Equal(t, InterfaceID{Sender: ComponentIDApp, Receiver: ComponentIDCamera}, sent[0].Interface)
+	require.Equal(t, MessageID(0), sent[0].ID)
+	require.Equal(t, MessageTypeResponse(CommandSetCamera, 2), sent[0].Type)
+
+	e.NoAutoACK.Store(true)
+	e.HandleMessage(ctx, &Message{
+		Interface: InterfaceID{Sender: ComponentIDCamera, Receiver: ComponentIDApp},
+		ID:        1,
+		Type:      MessageTypeRequest(CommandSetCamera, 2),
+	})
+	require.Len(t, sent, 1)
+
+	sub := e.Subscribe(ctx, nil)
+	e.Close(ErrDisconnected)
+	_, err := sub.Receive(ctx)
+	require.ErrorIs(t, err, ErrDisconnected)
+
+	late := e.Subscribe(ctx, nil)
+	_, err = late.Receive(ctx)
+	require.ErrorIs(t, err, ErrDisconnected, "a subscription after the close must be closed right away")
+	require.Equal(t, 0, e.Subscriptions.Len())
+
+	_, err = e.Request(ctx, &Message{
+		Interface: InterfaceIDAppToCamera,
+		Type:      MessageTypeRequest(CommandSetCamera, 1),
+	})
+	require.ErrorIs(t, err, ErrDisconnected)
+}
+
+func TestEndpointSubscribeContext(t *testing.T) {
+	e := NewEndpoint(1, func(ctx context.Context, msg *Message) error { return nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := e.Subscribe(ctx, nil)
+	sub.Close()
+	// the context watch is released on Close
+	require.False(t, sub.stopCtxWatch())
+	cancel()
+
+	ctx, cancel = context.WithCancel(context.Background())
+	sub = e.Subscribe(ctx, nil)
+	cancel()
+	_, err := sub.Receive(context.Background())
+	require.ErrorIs(t, err, ErrSubscriptionClosed)
+}
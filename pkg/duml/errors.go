@@ -0,0 +1,45 @@
+package duml
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrTimeout is returned when no response was received in time (including all the retransmissions).
+	ErrTimeout = errors.New("timed out waiting for a response")
+
+	// ErrDisconnected is returned for requests that were pending when the connection was lost.
+	ErrDisconnected = errors.New("disconnected")
+)
+
+// RejectedError is returned when the device responded with a non-success result.
+type RejectedError struct {
+	Response *Message
+	Code     ResultCode
+	Reason   string
+}
+
+func (e *RejectedError) Error() string {
+	msg := fmt.Sprintf("the device rejected the request (%s)", e.Code)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	if e.Response != nil {
+		msg += fmt.Sprintf("; response payload: %X", e.Response.Payload)
+	}
+	return msg
+}
+
+// NewRejectedError returns a RejectedError for the given response, the result code is
+// taken from the first byte of the payload (if any).
+func NewRejectedError(resp *Message, reasonFormat string, args ...any) *RejectedError {
+	err := &RejectedError{
+		Response: resp,
+		Reason:   fmt.Sprintf(reasonFormat, args...),
+	}
+	if resp != nil && len(resp.Payload) > 0 {
+		err.Code = ResultCode(resp.Payload[0])
+	}
+	return err
+}
@@ -0,0 +1,486 @@
+package duml
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EVCompensation is the exposure compensation, in tenths of EV.
+type EVCompensation int8
+
+const (
+	// MinEVCompensation and MaxEVCompensation are the limits of EVCompensation (-3.0 and +3.0 EV).
+	MinEVCompensation = EVCompensation(-30)
+	MaxEVCompensation = EVCompensation(30)
+)
+
+// EVCompensationFromFloat returns the EVCompensation closest to the given EV.
+func EVCompensationFromFloat(ev float64) (EVCompensation, error) {
+	v := math.Round(ev * 10)
+	if v < float64(MinEVCompensation) || v > float64(MaxEVCompensation) {
+		return 0, fmt.Errorf("EV compensation %v is out of the range [%s, %s]", ev, MinEVCompensation, MaxEVCompensation)
+	}
+	return EVCompensation(v), nil
+}
+
+func (ev EVCompensation) Float() float64 {
+	return float64(ev) / 10
+}
+
+func (ev EVCompensation) String() string {
+	return fmt.Sprintf("%+.1f", ev.Float())
+}
+
+func (ev *EVCompensation) MarshalDUML() ([]byte, error) {
+	return []byte{uint8(*ev)}, nil
+}
+
+func (ev *EVCompensation) UnmarshalDUML(b []byte) error {
+	if len(b) != 1 {
+		return fmt.Errorf("expected 1 byte, got %d: %X", len(b), b)
+	}
+	*ev = EVCompensation(int8(b[0]))
+	return nil
+}
+
+// ISOMode is whether the ISO is chosen by the camera.
+type ISOMode uint8
+
+const (
+	ISOModeAuto   = ISOMode(0x00)
+	ISOModeManual = ISOMode(0x01)
+)
+
+// MinISO and MaxISO are the limits of the ISO values accepted by ISOSetting.Validate.
+const (
+	MinISO = 100
+	MaxISO = 25600
+)
+
+// ISOSetting is the ISO mode and value.
+//
+// The layout is experimental (see KeyValueKey.IsExperimental):
+//
+//	[0]    - ISOMode
+//	[1:3]  - the ISO in ISOModeManual (little-endian)
+//	[3:5]  - the maximal ISO in ISOModeAuto, zero if not limited (little-endian)
+type ISOSetting struct {
+	Mode  ISOMode
+	Value uint16
+	Max   uint16
+}
+
+const isoSettingLength = 5
+
+// Validate checks the values are in the range [MinISO, MaxISO].
+func (s *ISOSetting) Validate() error {
+	switch s.Mode {
+	case ISOModeAuto:
+		if s.Max != 0 && (s.Max < MinISO || s.Max > MaxISO) {
+			return fmt.Errorf("the maximal ISO %d is out of the range [%d, %d]", s.Max, MinISO, MaxISO)
+		}
+	case ISOModeManual:
+		if s.Value < MinISO || s.Value > MaxISO {
+			return fmt.Errorf("ISO %d is out of the range [%d, %d]", s.Value, MinISO, MaxISO)
+		}
+	default:
+		return fmt.Errorf("unknown ISO mode 0x%02X", uint8(s.Mode))
+	}
+	return nil
+}
+
+func (s *ISOSetting) MarshalDUML() ([]byte, error) {
+	b := make([]byte, isoSettingLength)
+	b[0] = uint8(s.Mode)
+	binaryOrder.PutUint16(b[1:], s.Value)
+	binaryOrder.PutUint16(b[3:], s.Max)
+	return b, nil
+}
+
+func (s *ISOSetting) UnmarshalDUML(b []byte) error {
+	if len(b) != isoSettingLength {
+		return fmt.Errorf("expected %d bytes, got %d: %X", isoSettingLength, len(b), b)
+	}
+	s.Mode = ISOMode(b[0])
+	s.Value = binaryOrder.Uint16(b[1:])
+	s.Max = binaryOrder.Uint16(b[3:])
+	return nil
+}
+
+func (s ISOSetting) String() string {
+	switch s.Mode {
+	case ISOModeAuto:
+		if s.Max == 0 {
+			return "auto"
+		}
+		return fmt.Sprintf("auto (max %d)", s.Max)
+	case ISOModeManual:
+		return strconv.Itoa(int(s.Value))
+	default:
+		return fmt.Sprintf("<unknown mode 0x%02X>", uint8(s.Mode))
+	}
+}
+
+// ShutterSpeed is the exposure time, in microseconds.
+type ShutterSpeed uint32
+
+// ParseShutterSpeed parses a fraction of a second (e.g. "1/120") or a duration (e.g. "0.5s").
+func ParseShutterSpeed(s string) (ShutterSpeed, error) {
+	if num, den, ok := strings.Cut(s, "/"); ok {
+		n, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid shutter speed '%s': %w", s, err)
+		}
+		d, err := strconv.ParseFloat(den, 64)
+		if err != nil || d <= 0 {
+			return 0, fmt.Errorf("invalid shutter speed '%s': invalid denominator", s)
+		}
+		return shutterSpeedFromSeconds(s, n/d)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid shutter speed '%s' (expected e.g. 1/120 or 0.5s): %w", s, err)
+	}
+	return shutterSpeedFromSeconds(s, d.Seconds())
+}
+
+func shutterSpeedFromSeconds(s string, seconds float64) (ShutterSpeed, error) {
+	us := math.Round(seconds * 1e6)
+	if us < 1 || us > math.MaxUint32 {
+		return 0, fmt.Errorf("shutter speed '%s' is out of range", s)
+	}
+	return ShutterSpeed(us), nil
+}
+
+func (s ShutterSpeed) Duration() time.Duration {
+	return time.Duration(s) * time.Microsecond
+}
+
+func (s ShutterSpeed) String() string {
+	if s >= 1e6 || s == 0 {
+		return s.Duration().String()
+	}
+	return fmt.Sprintf("1/%d", int(math.Round(1e6/float64(s))))
+}
+
+// ShutterMode is whether the shutter speed is chosen by the camera.
+type ShutterMode uint8
+
+const (
+	ShutterModeAuto   = ShutterMode(0x00)
+	ShutterModeManual = ShutterMode(0x01)
+)
+
+// ShutterSetting is the shutter mode and speed.
+//
+// The layout is experimental:
+//
+//	[0]    - ShutterMode
+//	[1:5]  - the ShutterSpeed in ShutterModeManual (little-endian)
+type ShutterSetting struct {
+	Mode  ShutterMode
+	Speed ShutterSpeed
+}
+
+const shutterSettingLength = 5
+
+func (s *ShutterSetting) MarshalDUML() ([]byte, error) {
+	b := make([]byte, shutterSettingLength)
+	b[0] = uint8(s.Mode)
+	binaryOrder.PutUint32(b[1:], uint32(s.Speed))
+	return b, nil
+}
+
+func (s *ShutterSetting) UnmarshalDUML(b []byte) error {
+	if len(b) != shutterSettingLength {
+		return fmt.Errorf("expected %d bytes, got %d: %X", shutterSettingLength, len(b), b)
+	}
+	s.Mode = ShutterMode(b[0])
+	s.Speed = ShutterSpeed(binaryOrder.Uint32(b[1:]))
+	return nil
+}
+
+func (s ShutterSetting) String() string {
+	switch s.Mode {
+	case ShutterModeAuto:
+		return "auto"
+	case ShutterModeManual:
+		return s.Speed.String()
+	default:
+		return fmt.Sprintf("<unknown mode 0x%02X>", uint8(s.Mode))
+	}
+}
+
+// WhiteBalanceMode is the white balance preset, or WhiteBalanceModeKelvin
+// for a manually set color temperature.
+type WhiteBalanceMode uint8
+
+const (
+	WhiteBalanceModeAuto         = WhiteBalanceMode(0x00)
+	WhiteBalanceModeSunny        = WhiteBalanceMode(0x01)
+	WhiteBalanceModeCloudy       = WhiteBalanceMode(0x02)
+	WhiteBalanceModeIncandescent = WhiteBalanceMode(0x03)
+	WhiteBalanceModeFluorescent  = WhiteBalanceMode(0x04)
+	WhiteBalanceModeKelvin       = WhiteBalanceMode(0x05)
+)
+
+func (m WhiteBalanceMode) String() string {
+	switch m {
+	case WhiteBalanceModeAuto:
+		return "auto"
+	case WhiteBalanceModeSunny:
+		return "sunny"
+	case WhiteBalanceModeCloudy:
+		return "cloudy"
+	case WhiteBalanceModeIncandescent:
+		return "incandescent"
+	case WhiteBalanceModeFluorescent:
+		return "fluorescent"
+	case WhiteBalanceModeKelvin:
+		return "kelvin"
+	default:
+		return fmt.Sprintf("0x%02X", uint8(m))
+	}
+}
+
+// MinWhiteBalanceKelvin and MaxWhiteBalanceKelvin are the limits of WhiteBalance.Kelvin.
+const (
+	MinWhiteBalanceKelvin = 2000
+	MaxWhiteBalanceKelvin = 10000
+)
+
+// WhiteBalance is the white balance mode and the color temperature.
+//
+// The layout is experimental:
+//
+//	[0]    - WhiteBalanceMode
+//	[1:3]  - the color temperature in WhiteBalanceModeKelvin (little-endian)
+type WhiteBalance struct {
+	Mode   WhiteBalanceMode
+	Kelvin uint16
+}
+
+const whiteBalanceLength = 3
+
+// ParseWhiteBalance parses a preset name (e.g. "auto" or "cloudy") or a color
+// temperature (e.g. "5600K").
+func ParseWhiteBalance(s string) (WhiteBalance, error) {
+	s = strings.ToLower(s)
+	for m := WhiteBalanceModeAuto; m < WhiteBalanceModeKelvin; m++ {
+		if m.String() == s {
+			return WhiteBalance{Mode: m}, nil
+		}
+	}
+	v, err := strconv.ParseUint(strings.TrimSuffix(s, "k"), 10, 16)
+	if err != nil {
+		return WhiteBalance{}, fmt.Errorf("invalid white balance '%s' (expected auto, sunny, cloudy, incandescent, fluorescent or e.g. 5600K)", s)
+	}
+	wb := WhiteBalance{Mode: WhiteBalanceModeKelvin, Kelvin: uint16(v)}
+	if err := wb.Validate(); err != nil {
+		return WhiteBalance{}, err
+	}
+	return wb, nil
+}
+
+// Validate checks the color temperature is in the range [MinWhiteBalanceKelvin, MaxWhiteBalanceKelvin].
+func (wb *WhiteBalance) Validate() error {
+	if wb.Mode > WhiteBalanceModeKelvin {
+		return fmt.Errorf("unknown white balance mode %s", wb.Mode)
+	}
+	if wb.Mode == WhiteBalanceModeKelvin && (wb.Kelvin < MinWhiteBalanceKelvin || wb.Kelvin > MaxWhiteBalanceKelvin) {
+		return fmt.Errorf("color temperature %dK is out of the range [%dK, %dK]", wb.Kelvin, MinWhiteBalanceKelvin, MaxWhiteBalanceKelvin)
+	}
+	return nil
+}
+
+func (wb *WhiteBalance) MarshalDUML() ([]byte, error) {
+	b := make([]byte, whiteBalanceLength)
+	b[0] = uint8(wb.Mode)
+	binaryOrder.PutUint16(b[1:], wb.Kelvin)
+	return b, nil
+}
+
+func (wb *WhiteBalance) UnmarshalDUML(b []byte) error {
+	if len(b) != whiteBalanceLength {
+		return fmt.Errorf("expected %d bytes, got %d: %X", whiteBalanceLength, len(b), b)
+	}
+	wb.Mode = WhiteBalanceMode(b[0])
+	wb.Kelvin = binaryOrder.Uint16(b[1:])
+	return nil
+}
+
+func (wb WhiteBalance) String() string {
+	if wb.Mode == WhiteBalanceModeKelvin {
+		return fmt.Sprintf("%dK", wb.Kelvin)
+	}
+	return wb.Mode.String()
+}
+
+// ColorProfile is the color profile of the recording.
+type ColorProfile uint8
+
+const (
+	ColorProfileNormal = ColorProfile(0x00)
+	ColorProfileDLogM  = ColorProfile(0x01) // experimental
+)
+
+func (p ColorProfile) String() string {
+	switch p {
+	case ColorProfileNormal:
+		return "normal"
+	case ColorProfileDLogM:
+		return "d-log-m"
+	default:
+		return fmt.Sprintf("0x%02X", uint8(p))
+	}
+}
+
+// ColorProfileFromString is the reverse of ColorProfile.String (case-insensitive).
+func ColorProfileFromString(s string) (ColorProfile, error) {
+	for _, p := range []ColorProfile{ColorProfileNormal, ColorProfileDLogM} {
+		if strings.EqualFold(p.String(), s) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown color profile '%s' (allowed values: normal, d-log-m)", s)
+}
+
+func (p *ColorProfile) MarshalDUML() ([]byte, error) {
+	return []byte{uint8(*p)}, nil
+}
+
+func (p *ColorProfile) UnmarshalDUML(b []byte) error {
+	if len(b) != 1 {
+		return fmt.Errorf("expected 1 byte, got %d: %X", len(b), b)
+	}
+	*p = ColorProfile(b[0])
+	return nil
+}
+
+// ExposureSettings are the exposure-related settings of the camera; a nil
+// field is not known (or not to be changed).
+type ExposureSettings struct {
+	EV           *EVCompensation
+	ISO          *ISOSetting
+	Shutter      *ShutterSetting
+	WhiteBalance *WhiteBalance
+	ColorProfile *ColorProfile
+}
+
+// ExposureKeys are the keys of the values of ExposureSettings.
+var ExposureKeys = []KeyValueKey{
+	KeyValueKeyEVCompensation,
+	KeyValueKeyISO,
+	KeyValueKeyShutter,
+	KeyValueKeyWhiteBalance,
+	KeyValueKeyColorProfile,
+}
+
+// Items returns the values of the non-nil fields.
+func (s *ExposureSettings) Items() ([]KeyValueItem, error) {
+	var items []KeyValueItem
+	add := func(key KeyValueKey, value Payload) error {
+		b, err := value.MarshalDUML()
+		if err != nil {
+			return fmt.Errorf("unable to marshal the value of key 0x%04X: %w", key, err)
+		}
+		items = append(items, KeyValueItem{Key: key, Value: b})
+		return nil
+	}
+	if s.EV != nil {
+		if err := add(KeyValueKeyEVCompensation, s.EV); err != nil {
+			return nil, err
+		}
+	}
+	if s.ISO != nil {
+		if err := add(KeyValueKeyISO, s.ISO); err != nil {
+			return nil, err
+		}
+	}
+	if s.Shutter != nil {
+		if err := add(KeyValueKeyShutter, s.Shutter); err != nil {
+			return nil, err
+		}
+	}
+	if s.WhiteBalance != nil {
+		if err := add(KeyValueKeyWhiteBalance, s.WhiteBalance); err != nil {
+			return nil, err
+		}
+	}
+	if s.ColorProfile != nil {
+		if err := add(KeyValueKeyColorProfile, s.ColorProfile); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// Update sets the fields of the exposure-related keys found in the items.
+func (s *ExposureSettings) Update(items ...KeyValueItem) error {
+	for _, item := range items {
+		var err error
+		switch item.Key {
+		case KeyValueKeyEVCompensation:
+			s.EV = new(EVCompensation)
+			err = s.EV.UnmarshalDUML(item.Value)
+		case KeyValueKeyISO:
+			s.ISO = &ISOSetting{}
+			err = s.ISO.UnmarshalDUML(item.Value)
+		case KeyValueKeyShutter:
+			s.Shutter = &ShutterSetting{}
+			err = s.Shutter.UnmarshalDUML(item.Value)
+		case KeyValueKeyWhiteBalance:
+			s.WhiteBalance = &WhiteBalance{}
+			err = s.WhiteBalance.UnmarshalDUML(item.Value)
+		case KeyValueKeyColorProfile:
+			s.ColorProfile = new(ColorProfile)
+			err = s.ColorProfile.UnmarshalDUML(item.Value)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to parse the value of key 0x%04X: %w", item.Key, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the non-nil fields.
+func (s *ExposureSettings) Validate() error {
+	if s.EV != nil && (*s.EV < MinEVCompensation || *s.EV > MaxEVCompensation) {
+		return fmt.Errorf("EV compensation %s is out of the range [%s, %s]", *s.EV, MinEVCompensation, MaxEVCompensation)
+	}
+	if s.ISO != nil {
+		if err := s.ISO.Validate(); err != nil {
+			return err
+		}
+	}
+	if s.Shutter != nil && s.Shutter.Mode == ShutterModeManual && s.Shutter.Speed == 0 {
+		return fmt.Errorf("the manual shutter speed is not set")
+	}
+	if s.WhiteBalance != nil {
+		if err := s.WhiteBalance.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s ExposureSettings) String() string {
+	parts := []string{
+		"EV: " + stringOrUnknown(s.EV),
+		"ISO: " + stringOrUnknown(s.ISO),
+		"shutter: " + stringOrUnknown(s.Shutter),
+		"white balance: " + stringOrUnknown(s.WhiteBalance),
+		"color profile: " + stringOrUnknown(s.ColorProfile),
+	}
+	return strings.Join(parts, "; ")
+}
+
+func stringOrUnknown[T fmt.Stringer](v *T) string {
+	if v == nil {
+		return "<unknown>"
+	}
+	return (*v).String()
+}
@@ -0,0 +1,89 @@
+package duml
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExposureSettings(t *testing.T) {
+	ev, err := EVCompensationFromFloat(-0.7)
+	require.NoError(t, err)
+	require.Equal(t, "-0.7", ev.String())
+	_, err = EVCompensationFromFloat(3.3)
+	require.Error(t, err)
+
+	speed, err := ParseShutterSpeed("1/120")
+	require.NoError(t, err)
+	require.Equal(t, "1/120", speed.String())
+	long, err := ParseShutterSpeed("2s")
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Second, long.Duration())
+	_, err = ParseShutterSpeed("1/0")
+	require.Error(t, err)
+
+	wb, err := ParseWhiteBalance("5600K")
+	require.NoError(t, err)
+	require.Equal(t, WhiteBalance{Mode: WhiteBalanceModeKelvin, Kelvin: 5600}, wb)
+	wb, err = ParseWhiteBalance("Cloudy")
+	require.NoError(t, err)
+	require.Equal(t, "cloudy", wb.String())
+	_, err = ParseWhiteBalance("1500K")
+	require.Error(t, err)
+
+	profile, err := ColorProfileFromString("D-Log-M")
+	require.NoError(t, err)
+	require.Equal(t, ColorProfileDLogM, profile)
+
+	settings := ExposureSettings{
+		EV:           &ev,
+		ISO:          &ISOSetting{Mode: ISOModeAuto, Max: 3200},
+		Shutter:      &ShutterSetting{Mode: ShutterModeManual, Speed: speed},
+		WhiteBalance: &WhiteBalance{Mode: WhiteBalanceModeKelvin, Kelvin: 5600},
+		ColorProfile: &profile,
+	}
+	require.NoError(t, settings.Validate())
+	require.Equal(t, "EV: -0.7; ISO: auto (max 3200); shutter: 1/120; white balance: 5600K; color profile: d-log-m", settings.String())
+
+	items, err := settings.Items()
+	require.NoError(t, err)
+	require.Len(t, items, len(ExposureKeys))
+	require.Equal(t, KeyValueItem{Key: KeyValueKeyISO, Value: []byte{0x00, 0x00, 0x00, 0x80, 0x0C}}, items[1])
+
+	var decoded ExposureSettings
+	require.NoError(t, decoded.Update(items...))
+	require.Equal(t, settings, decoded)
+
+	partial := ExposureSettings{ISO: &ISOSetting{Mode: ISOModeManual, Value: 50}}
+	require.Error(t, partial.Validate())
+	items, err = partial.Items()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, "EV: <unknown>; ISO: 50; shutter: <unknown>; white balance: <unknown>; color profile: <unknown>", partial.String())
+
+	require.Error(t, decoded.Update(KeyValueItem{Key: KeyValueKeyShutter, Value: []byte{0x01}}))
+
+	p, _ := DeviceTypeOsmoAction3.Profile()
+	require.False(t, p.SupportsColorProfile(ColorProfileDLogM))
+	p, _ = DeviceTypeOsmoPocket3.Profile()
+	require.True(t, p.SupportsColorProfile(ColorProfileDLogM))
+}
+
+// There is no capture of the exposure settings yet, so the bytes below are
+// derived from the experimental encodings; they pin the frame sent to the device.
+func TestExposureGoldenFrame(t *testing.T) {
+	settings := ExposureSettings{
+		ISO:          &ISOSetting{Mode: ISOModeAuto, Max: 3200},
+		WhiteBalance: &WhiteBalance{Mode: WhiteBalanceModeKelvin, Kelvin: 5600},
+	}
+	items, err := settings.Items()
+	require.NoError(t, err)
+	msg, err := NewMessage(InterfaceIDAppToCamera, 0x1234, MessageTypeStartStopStreaming, &KeyValueRequest{Op: KeyValueOpSet, Items: items})
+	require.NoError(t, err)
+	// set (0x01), 2 items:
+	//   key 0x0005 (ISO), 5 bytes: auto (0x00), value 0, max 3200
+	//   key 0x0007 (white balance), 3 bytes: kelvin (0x05), 5600
+	require.Equal(t, "551d04df0201123440028e0102050005000000800c07000305e0159ff3", hex.EncodeToString(msg.Bytes()))
+}
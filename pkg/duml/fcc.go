@@ -1,12 +1,37 @@
 package duml
 
-func NewFCCEnableMessage(value bool) *Message {
-	payload := uint8(0x00)
-	if value {
-		payload = uint8(0x01)
+import "fmt"
+
+// FCCSupport is the payload of MessageTypeFCCSupport.
+type FCCSupport struct {
+	Enabled bool
+}
+
+var _ Payload = (*FCCSupport)(nil)
+
+func (p *FCCSupport) MarshalDUML() ([]byte, error) {
+	if p.Enabled {
+		return []byte{0x01}, nil // Simple enable flag
 	}
+	return []byte{0x00}, nil
+}
+
+func (p *FCCSupport) UnmarshalDUML(b []byte) error {
+	if len(b) != 1 {
+		return fmt.Errorf("expected exactly 1 byte, but received %d", len(b))
+	}
+	p.Enabled = b[0] != 0
+	return nil
+}
+
+func NewFCCEnableMessage(value bool) *Message {
 	return &Message{
 		Type:    MessageTypeFCCSupport,
-		Payload: []byte{payload}, // Simple enable flag
+		Payload: must((&FCCSupport{Enabled: value}).MarshalDUML()),
+		AutoID:  true,
 	}
 }
+
+func init() {
+	RegisterPayload(MessageTypeFCCSupport, func() Payload { return &FCCSupport{} })
+}
@@ -0,0 +1,111 @@
+package duml
+
+// MessageFilter selects messages, e.g. for a Subscription.
+//
+// A nil MessageFilter matches any message.
+type MessageFilter func(msg *Message) bool
+
+// Match returns true if the message passes the filter.
+func (f MessageFilter) Match(msg *Message) bool {
+	if f == nil {
+		return true
+	}
+	return f(msg)
+}
+
+// FilterAny matches any message.
+func FilterAny() MessageFilter {
+	return nil
+}
+
+// FilterType matches messages of the given command and the same direction
+// (request or response) as the given MessageType; other flags (e.g. the ACK
+// flag) are ignored, since the devices are not consistent in setting them.
+func FilterType(t MessageType) MessageFilter {
+	key := newPayloadKey(t)
+	return func(msg *Message) bool {
+		return newPayloadKey(msg.Type) == key
+	}
+}
+
+// FilterTypes matches messages that match FilterType of any of the given MessageTypes.
+func FilterTypes(types ...MessageType) MessageFilter {
+	filters := make([]MessageFilter, 0, len(types))
+	for _, t := range types {
+		filters = append(filters, FilterType(t))
+	}
+	return FilterOr(filters...)
+}
+
+// FilterCommand matches messages of the given command in any direction.
+func FilterCommand(cmdSet CommandSet, cmdID CommandID) MessageFilter {
+	return func(msg *Message) bool {
+		return msg.Type.CmdSet == cmdSet && msg.Type.CmdID == cmdID
+	}
+}
+
+// FilterCommandSet matches messages of any command of the given command set.
+func FilterCommandSet(cmdSet CommandSet) MessageFilter {
+	return func(msg *Message) bool {
+		return msg.Type.CmdSet == cmdSet
+	}
+}
+
+// FilterInterface matches messages with exactly the given InterfaceID.
+func FilterInterface(iface InterfaceID) MessageFilter {
+	return func(msg *Message) bool {
+		return msg.Interface == iface
+	}
+}
+
+// FilterSender matches messages sent by the given component.
+func FilterSender(sender ComponentID) MessageFilter {
+	return func(msg *Message) bool {
+		return msg.Interface.Sender == sender
+	}
+}
+
+// FilterReceiver matches messages addressed to the given component.
+func FilterReceiver(receiver ComponentID) MessageFilter {
+	return func(msg *Message) bool {
+		return msg.Interface.Receiver == receiver
+	}
+}
+
+// FilterResponses matches responses (if isResponse is true) or requests/pushes (otherwise).
+func FilterResponses(isResponse bool) MessageFilter {
+	return func(msg *Message) bool {
+		return (msg.Type.Flags&MessageTypeFlagResponse != 0) == isResponse
+	}
+}
+
+// FilterAnd matches messages that pass all the filters.
+func FilterAnd(filters ...MessageFilter) MessageFilter {
+	return func(msg *Message) bool {
+		for _, f := range filters {
+			if !f.Match(msg) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// FilterOr matches messages that pass any of the filters.
+func FilterOr(filters ...MessageFilter) MessageFilter {
+	return func(msg *Message) bool {
+		for _, f := range filters {
+			if f.Match(msg) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FilterNot inverts the filter.
+func FilterNot(filter MessageFilter) MessageFilter {
+	return func(msg *Message) bool {
+		return !filter.Match(msg)
+	}
+}
@@ -12,7 +12,7 @@ const (
 func (f FPS) BytesFixed() [1]byte {
 	switch f {
 	case FPS24:
-		return [1]byte{0x01} // assumed, not confirmed
+		return [1]byte{0x01} // experimental (see KeyValueKey.IsExperimental)
 	case FPS25:
 		return [1]byte{0x02}
 	case FPS30:
@@ -34,3 +34,25 @@ func FPSFromUint(v uint) FPS {
 		return UndefinedFPS
 	}
 }
+
+func FPSFromBytesFixed(b [1]byte) FPS {
+	for f := FPS24; f <= FPS30; f++ {
+		if f.BytesFixed() == b {
+			return f
+		}
+	}
+	return UndefinedFPS
+}
+
+func (f FPS) String() string {
+	switch f {
+	case FPS24:
+		return "24"
+	case FPS25:
+		return "25"
+	case FPS30:
+		return "30"
+	default:
+		return "<undefined>"
+	}
+}
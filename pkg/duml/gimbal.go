@@ -0,0 +1,271 @@
+package duml
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Decidegrees is an angle in tenths of a degree.
+type Decidegrees int16
+
+// DecidegreesFromFloat returns the Decidegrees closest to the given angle in degrees.
+func DecidegreesFromFloat(deg float64) (Decidegrees, error) {
+	v := math.Round(deg * 10)
+	if v < math.MinInt16 || v > math.MaxInt16 {
+		return 0, fmt.Errorf("angle %v° is out of range", deg)
+	}
+	return Decidegrees(v), nil
+}
+
+func (a Decidegrees) Degrees() float64 {
+	return float64(a) / 10
+}
+
+func (a Decidegrees) String() string {
+	return fmt.Sprintf("%.1f°", a.Degrees())
+}
+
+// GimbalMode is the way the gimbal follows the movements of the handle.
+//
+// The values are experimental (see KeyValueKey.IsExperimental).
+type GimbalMode uint8
+
+const (
+	GimbalModeFollow     = GimbalMode(0x00)
+	GimbalModeTiltLocked = GimbalMode(0x01)
+	GimbalModeFPV        = GimbalMode(0x02)
+
+	// GimbalModeKeep keeps the current mode (see GimbalSetModeRequest).
+	GimbalModeKeep = GimbalMode(0xFF)
+)
+
+func (m GimbalMode) String() string {
+	switch m {
+	case GimbalModeFollow:
+		return "follow"
+	case GimbalModeTiltLocked:
+		return "tilt-locked"
+	case GimbalModeFPV:
+		return "fpv"
+	case GimbalModeKeep:
+		return "keep"
+	default:
+		return fmt.Sprintf("0x%02X", uint8(m))
+	}
+}
+
+// GimbalModeFromString is the reverse of GimbalMode.String (case-insensitive).
+func GimbalModeFromString(s string) (GimbalMode, error) {
+	for _, m := range []GimbalMode{GimbalModeFollow, GimbalModeTiltLocked, GimbalModeFPV} {
+		if strings.EqualFold(m.String(), s) {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown gimbal mode '%s' (allowed values: follow, tilt-locked, fpv)", s)
+}
+
+// GimbalAttitude is the orientation of the gimbal.
+type GimbalAttitude struct {
+	Yaw   Decidegrees
+	Pitch Decidegrees
+	Roll  Decidegrees
+}
+
+func (a GimbalAttitude) String() string {
+	return fmt.Sprintf("yaw: %s; pitch: %s; roll: %s", a.Yaw, a.Pitch, a.Roll)
+}
+
+const gimbalStatusLength = 7
+
+// GimbalStatus is the payload of MessageTypeUnknown0MaybeStatus ("gimbal_status").
+//
+// The layout is experimental:
+//
+//	[0:2]  - pitch (little-endian)
+//	[2:4]  - roll (little-endian)
+//	[4:6]  - yaw (little-endian)
+//	[6]    - GimbalMode
+//
+// the rest (if any) is ignored.
+type GimbalStatus struct {
+	Attitude GimbalAttitude
+	Mode     GimbalMode
+}
+
+var _ Payload = (*GimbalStatus)(nil)
+
+func (s *GimbalStatus) MarshalDUML() ([]byte, error) {
+	b := make([]byte, gimbalStatusLength)
+	binaryOrder.PutUint16(b[0:], uint16(s.Attitude.Pitch))
+	binaryOrder.PutUint16(b[2:], uint16(s.Attitude.Roll))
+	binaryOrder.PutUint16(b[4:], uint16(s.Attitude.Yaw))
+	b[6] = uint8(s.Mode)
+	return b, nil
+}
+
+func (s *GimbalStatus) UnmarshalDUML(b []byte) error {
+	if len(b) < gimbalStatusLength {
+		return fmt.Errorf("payload is too short: %d < %d", len(b), gimbalStatusLength)
+	}
+	s.Attitude.Pitch = Decidegrees(binaryOrder.Uint16(b[0:]))
+	s.Attitude.Roll = Decidegrees(binaryOrder.Uint16(b[2:]))
+	s.Attitude.Yaw = Decidegrees(binaryOrder.Uint16(b[4:]))
+	s.Mode = GimbalMode(b[6])
+	return nil
+}
+
+func (s GimbalStatus) String() string {
+	return fmt.Sprintf("%s; mode: %s", s.Attitude, s.Mode)
+}
+
+// GimbalSetModeRequest is the payload of MessageTypeGimbalSetMode.
+//
+// The layout is experimental:
+//
+//	[0]  - GimbalMode (GimbalModeKeep to keep the current one)
+//	[1]  - 0x01 to recenter the gimbal, 0x00 otherwise
+type GimbalSetModeRequest struct {
+	Mode     GimbalMode
+	Recenter bool
+}
+
+var _ Payload = (*GimbalSetModeRequest)(nil)
+
+func (r *GimbalSetModeRequest) MarshalDUML() ([]byte, error) {
+	b := []byte{uint8(r.Mode), 0x00}
+	if r.Recenter {
+		b[1] = 0x01
+	}
+	return b, nil
+}
+
+func (r *GimbalSetModeRequest) UnmarshalDUML(b []byte) error {
+	if len(b) != 2 {
+		return fmt.Errorf("expected 2 bytes, got %d: %X", len(b), b)
+	}
+	r.Mode = GimbalMode(b[0])
+	r.Recenter = b[1] != 0x00
+	return nil
+}
+
+// GimbalMoveFlags are the flags of GimbalMoveRequest.
+//
+// The values are experimental.
+type GimbalMoveFlags uint8
+
+const (
+	// GimbalMoveFlagRelative makes the angles relative to the current attitude.
+	GimbalMoveFlagRelative = GimbalMoveFlags(0x01)
+
+	// GimbalMoveFlagIgnoreYaw, GimbalMoveFlagIgnoreRoll and GimbalMoveFlagIgnorePitch
+	// keep the current angle of the axis.
+	GimbalMoveFlagIgnoreYaw   = GimbalMoveFlags(0x02)
+	GimbalMoveFlagIgnoreRoll  = GimbalMoveFlags(0x04)
+	GimbalMoveFlagIgnorePitch = GimbalMoveFlags(0x08)
+)
+
+// MaxGimbalMoveDuration is the maximal duration of a GimbalMoveRequest.
+const MaxGimbalMoveDuration = math.MaxUint8 * 100 * time.Millisecond
+
+const gimbalMoveRequestLength = 8
+
+// GimbalMoveRequest is the payload of MessageTypeGimbalMove.
+//
+// The layout is experimental:
+//
+//	[0:2]  - yaw (little-endian)
+//	[2:4]  - roll (little-endian)
+//	[4:6]  - pitch (little-endian)
+//	[6]    - GimbalMoveFlags
+//	[7]    - the duration of the movement, in tenths of a second
+type GimbalMoveRequest struct {
+	Yaw   Decidegrees
+	Roll  Decidegrees
+	Pitch Decidegrees
+	Flags GimbalMoveFlags
+
+	// Duration is rounded to tenths of a second.
+	Duration time.Duration
+}
+
+var _ Payload = (*GimbalMoveRequest)(nil)
+
+// Validate checks the angles are within a turn and the duration is within MaxGimbalMoveDuration.
+func (r *GimbalMoveRequest) Validate() error {
+	for _, angle := range []Decidegrees{r.Yaw, r.Roll, r.Pitch} {
+		if angle < -1800 || angle > 1800 {
+			return fmt.Errorf("angle %s is out of the range [-180°, 180°]", angle)
+		}
+	}
+	if r.Duration < 0 || r.Duration > MaxGimbalMoveDuration {
+		return fmt.Errorf("duration %s is out of the range [0, %s]", r.Duration, MaxGimbalMoveDuration)
+	}
+	return nil
+}
+
+func (r *GimbalMoveRequest) MarshalDUML() ([]byte, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	b := make([]byte, gimbalMoveRequestLength)
+	binaryOrder.PutUint16(b[0:], uint16(r.Yaw))
+	binaryOrder.PutUint16(b[2:], uint16(r.Roll))
+	binaryOrder.PutUint16(b[4:], uint16(r.Pitch))
+	b[6] = uint8(r.Flags)
+	b[7] = uint8(r.Duration.Round(100*time.Millisecond) / (100 * time.Millisecond))
+	return b, nil
+}
+
+func (r *GimbalMoveRequest) UnmarshalDUML(b []byte) error {
+	if len(b) != gimbalMoveRequestLength {
+		return fmt.Errorf("expected %d bytes, got %d: %X", gimbalMoveRequestLength, len(b), b)
+	}
+	r.Yaw = Decidegrees(binaryOrder.Uint16(b[0:]))
+	r.Roll = Decidegrees(binaryOrder.Uint16(b[2:]))
+	r.Pitch = Decidegrees(binaryOrder.Uint16(b[4:]))
+	r.Flags = GimbalMoveFlags(b[6])
+	r.Duration = time.Duration(b[7]) * 100 * time.Millisecond
+	return nil
+}
+
+// Apply returns the attitude after the movement from the given one
+// (ignoring the mechanical limits of the gimbal).
+func (r *GimbalMoveRequest) Apply(a GimbalAttitude) GimbalAttitude {
+	move := func(cur, target Decidegrees, ignore GimbalMoveFlags) Decidegrees {
+		switch {
+		case r.Flags&ignore != 0:
+			return cur
+		case r.Flags&GimbalMoveFlagRelative != 0:
+			return normalizeDecidegrees(cur + target)
+		default:
+			return target
+		}
+	}
+	return GimbalAttitude{
+		Yaw:   move(a.Yaw, r.Yaw, GimbalMoveFlagIgnoreYaw),
+		Pitch: move(a.Pitch, r.Pitch, GimbalMoveFlagIgnorePitch),
+		Roll:  move(a.Roll, r.Roll, GimbalMoveFlagIgnoreRoll),
+	}
+}
+
+// normalizeDecidegrees returns the angle in the range (-180°, 180°].
+func normalizeDecidegrees(a Decidegrees) Decidegrees {
+	v := int(a) % 3600
+	switch {
+	case v > 1800:
+		v -= 3600
+	case v <= -1800:
+		v += 3600
+	}
+	return Decidegrees(v)
+}
+
+func init() {
+	RegisterPayload(MessageTypeUnknown0MaybeStatus, func() Payload { return &GimbalStatus{} })
+	RegisterPayload(MessageTypeGimbalSetMode, func() Payload { return &GimbalSetModeRequest{} })
+	RegisterPayload(MessageTypeGimbalSetModeResult, func() Payload { return &Result{} })
+	RegisterPayload(MessageTypeGimbalMove, func() Payload { return &GimbalMoveRequest{} })
+	RegisterPayload(MessageTypeGimbalMoveResult, func() Payload { return &Result{} })
+}
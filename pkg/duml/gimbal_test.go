@@ -0,0 +1,51 @@
+package duml
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGimbal(t *testing.T) {
+	mode, err := GimbalModeFromString("Tilt-Locked")
+	require.NoError(t, err)
+	require.Equal(t, GimbalModeTiltLocked, mode)
+	_, err = GimbalModeFromString("keep")
+	require.Error(t, err)
+
+	angle, err := DecidegreesFromFloat(-12.34)
+	require.NoError(t, err)
+	require.Equal(t, "-12.3°", angle.String())
+
+	var status GimbalStatus
+	require.NoError(t, status.UnmarshalDUML([]byte{0x9B, 0x00, 0xFD, 0xFF, 0x7C, 0xFC, 0x02, 0xAA}))
+	require.Equal(t, "yaw: -90.0°; pitch: 15.5°; roll: -0.3°; mode: fpv", status.String())
+	require.Error(t, status.UnmarshalDUML([]byte{0x00, 0x00}))
+
+	current := GimbalAttitude{Yaw: 1700, Pitch: -300, Roll: 10}
+	require.Equal(t, GimbalAttitude{Yaw: 450, Pitch: -300, Roll: 0}, (&GimbalMoveRequest{Yaw: 450, Flags: GimbalMoveFlagIgnorePitch}).Apply(current))
+	require.Equal(t, GimbalAttitude{Yaw: -1700, Pitch: -250, Roll: 10}, (&GimbalMoveRequest{Yaw: 200, Pitch: 50, Flags: GimbalMoveFlagRelative | GimbalMoveFlagIgnoreRoll}).Apply(current))
+
+	b, err := (&GimbalMoveRequest{Pitch: -900, Duration: 2 * time.Second}).MarshalDUML()
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x00, 0x00, 0x00, 0x00, 0x7C, 0xFC, 0x00, 0x14}, b)
+	_, err = (&GimbalMoveRequest{Yaw: 1900}).MarshalDUML()
+	require.Error(t, err)
+	_, err = (&GimbalMoveRequest{Duration: time.Minute}).MarshalDUML()
+	require.Error(t, err)
+}
+
+func TestGimbalMoveGoldenFrame(t *testing.T) {
+	msg, err := NewMessage(InterfaceIDAppToGimbal, 0x1234, MessageTypeGimbalMove, &GimbalMoveRequest{
+		Yaw:      300,
+		Pitch:    -155,
+		Flags:    GimbalMoveFlagIgnoreRoll,
+		Duration: time.Second,
+	})
+	require.NoError(t, err)
+	// yaw 300, pitch -155, roll 0 (int16, little endian), flags: ignore roll (0x04),
+	// duration: 10 tenths of a second
+	require.Equal(t, "551504a90203123440040a2c01000065ff040a23fe", hex.EncodeToString(msg.Bytes()))
+}
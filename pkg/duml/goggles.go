@@ -1,7 +1,6 @@
 package duml
 
-// Goggles mode switching
-const ()
+import "fmt"
 
 type GogglesMode uint8
 
@@ -10,9 +9,33 @@ const (
 	GogglesModeUSB    GogglesMode = 1
 )
 
+// GogglesModeRequest is the payload of MessageTypeGogglesMode.
+type GogglesModeRequest struct {
+	Mode GogglesMode
+}
+
+var _ Payload = (*GogglesModeRequest)(nil)
+
+func (r *GogglesModeRequest) MarshalDUML() ([]byte, error) {
+	return []byte{uint8(r.Mode)}, nil
+}
+
+func (r *GogglesModeRequest) UnmarshalDUML(b []byte) error {
+	if len(b) != 1 {
+		return fmt.Errorf("expected exactly 1 byte, but received %d", len(b))
+	}
+	r.Mode = GogglesMode(b[0])
+	return nil
+}
+
 func NewGogglesModeMessage(mode GogglesMode) *Message {
 	return &Message{
 		Type:    MessageTypeGogglesMode,
-		Payload: []byte{uint8(mode)},
+		Payload: must((&GogglesModeRequest{Mode: mode}).MarshalDUML()),
+		AutoID:  true,
 	}
 }
+
+func init() {
+	RegisterPayload(MessageTypeGogglesMode, func() Payload { return &GogglesModeRequest{} })
+}
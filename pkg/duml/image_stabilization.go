@@ -1,5 +1,7 @@
 package duml
 
+import "strings"
+
 type ImageStabilization int
 
 const (
@@ -26,3 +28,31 @@ func (v ImageStabilization) BytesFixed() [1]byte {
 	}
 	return [1]byte{0x00}
 }
+
+func (v ImageStabilization) String() string {
+	switch v {
+	case ImageStabilizationOff:
+		return "off"
+	case ImageStabilizationRockSteady:
+		return "rock-steady"
+	case ImageStabilizationRockSteadyPlus:
+		return "rock-steady-plus"
+	case ImageStabilizationHorizonBalancing:
+		return "horizon-balancing"
+	case ImageStabilizationHorizonSteady:
+		return "horizon-steady"
+	default:
+		return "<undefined>"
+	}
+}
+
+// ImageStabilizationFromString is the reverse of ImageStabilization.String (case-insensitive).
+func ImageStabilizationFromString(s string) ImageStabilization {
+	s = strings.ToLower(s)
+	for v := ImageStabilizationOff; v <= ImageStabilizationHorizonSteady; v++ {
+		if v.String() == s {
+			return v
+		}
+	}
+	return ImageStabilizationUndefined
+}
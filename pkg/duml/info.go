@@ -0,0 +1,126 @@
+package duml
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FirmwareVersion is a version of 4 numbers, e.g. "01.02.03.04"; the most
+// significant number is the first one.
+type FirmwareVersion uint32
+
+// NewFirmwareVersion returns the FirmwareVersion "a.b.c.d".
+func NewFirmwareVersion(a, b, c, d uint8) FirmwareVersion {
+	return FirmwareVersion(uint32(a)<<24 | uint32(b)<<16 | uint32(c)<<8 | uint32(d))
+}
+
+// ParseFirmwareVersion is the reverse of FirmwareVersion.String.
+func ParseFirmwareVersion(s string) (FirmwareVersion, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 4 {
+		return 0, fmt.Errorf("invalid firmware version '%s', expected 4 numbers separated by dots", s)
+	}
+	var numbers [4]uint8
+	for idx, part := range parts {
+		v, err := strconv.ParseUint(part, 10, 8)
+		if err != nil {
+			return 0, fmt.Errorf("invalid firmware version '%s': %w", s, err)
+		}
+		numbers[idx] = uint8(v)
+	}
+	return NewFirmwareVersion(numbers[0], numbers[1], numbers[2], numbers[3]), nil
+}
+
+// Numbers returns the numbers of the version, the most significant first.
+func (v FirmwareVersion) Numbers() [4]uint8 {
+	return [4]uint8{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}
+}
+
+func (v FirmwareVersion) String() string {
+	n := v.Numbers()
+	return fmt.Sprintf("%02d.%02d.%02d.%02d", n[0], n[1], n[2], n[3])
+}
+
+const (
+	versionInfoHardwareVersionLength = 16
+	versionInfoMinLength             = 1 + versionInfoHardwareVersionLength + 4 + 4
+)
+
+// VersionInfo is the payload of MessageTypeGetVersionResult.
+//
+// The layout is experimental (see KeyValueKey.IsExperimental):
+//
+//	[0]      - the ResultCode
+//	[1:17]   - the hardware version (NUL-padded ASCII)
+//	[17:21]  - the loader version (little-endian)
+//	[21:25]  - the firmware version (little-endian)
+//	[25:]    - not yet understood
+type VersionInfo struct {
+	Result          ResultCode
+	HardwareVersion string
+	LoaderVersion   FirmwareVersion
+	FirmwareVersion FirmwareVersion
+
+	// Extra are the trailing bytes that are not yet understood.
+	Extra []byte
+}
+
+var _ Payload = (*VersionInfo)(nil)
+
+func (v *VersionInfo) MarshalDUML() ([]byte, error) {
+	if len(v.HardwareVersion) > versionInfoHardwareVersionLength {
+		return nil, fmt.Errorf("the hardware version is too long: %d > %d", len(v.HardwareVersion), versionInfoHardwareVersionLength)
+	}
+	b := make([]byte, versionInfoMinLength, versionInfoMinLength+len(v.Extra))
+	b[0] = uint8(v.Result)
+	copy(b[1:], v.HardwareVersion)
+	binaryOrder.PutUint32(b[17:], uint32(v.LoaderVersion))
+	binaryOrder.PutUint32(b[21:], uint32(v.FirmwareVersion))
+	return append(b, v.Extra...), nil
+}
+
+func (v *VersionInfo) UnmarshalDUML(b []byte) error {
+	if len(b) < versionInfoMinLength {
+		return fmt.Errorf("payload is too short: %d < %d", len(b), versionInfoMinLength)
+	}
+	v.Result = ResultCode(b[0])
+	v.HardwareVersion = string(bytes.TrimRight(b[1:1+versionInfoHardwareVersionLength], "\x00"))
+	v.LoaderVersion = FirmwareVersion(binaryOrder.Uint32(b[17:]))
+	v.FirmwareVersion = FirmwareVersion(binaryOrder.Uint32(b[21:]))
+	v.Extra = nil
+	if len(b) > versionInfoMinLength {
+		v.Extra = append([]byte{}, b[versionInfoMinLength:]...)
+	}
+	return nil
+}
+
+// StringResult is the payload of MessageTypeGetSerialNumResult and MessageTypeGetProductIDResult.
+//
+// The layout is experimental: a ResultCode followed by a NUL-padded ASCII string.
+type StringResult struct {
+	Result ResultCode
+	Value  string
+}
+
+var _ Payload = (*StringResult)(nil)
+
+func (r *StringResult) MarshalDUML() ([]byte, error) {
+	return append([]byte{uint8(r.Result)}, r.Value...), nil
+}
+
+func (r *StringResult) UnmarshalDUML(b []byte) error {
+	if len(b) < 1 {
+		return fmt.Errorf("the payload is empty")
+	}
+	r.Result = ResultCode(b[0])
+	r.Value = string(bytes.TrimRight(b[1:], "\x00"))
+	return nil
+}
+
+func init() {
+	RegisterPayload(MessageTypeGetVersionResult, func() Payload { return &VersionInfo{} })
+	RegisterPayload(MessageTypeGetSerialNumResult, func() Payload { return &StringResult{} })
+	RegisterPayload(MessageTypeGetProductIDResult, func() Payload { return &StringResult{} })
+}
@@ -0,0 +1,36 @@
+package duml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirmwareVersion(t *testing.T) {
+	v := NewFirmwareVersion(1, 2, 30, 4)
+	require.Equal(t, "01.02.30.04", v.String())
+	require.Equal(t, [4]uint8{1, 2, 30, 4}, v.Numbers())
+	require.Less(t, v, NewFirmwareVersion(1, 3, 0, 0))
+
+	parsed, err := ParseFirmwareVersion(v.String())
+	require.NoError(t, err)
+	require.Equal(t, v, parsed)
+
+	_, err = ParseFirmwareVersion("1.2.3")
+	require.Error(t, err)
+	_, err = ParseFirmwareVersion("1.2.3.256")
+	require.Error(t, err)
+}
+
+func TestVersionInfoPadding(t *testing.T) {
+	var info VersionInfo
+	b := append([]byte{0x00}, "WM240\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"...)
+	b = append(b, 0x07, 0x00, 0x00, 0x01, 0x04, 0x03, 0x02, 0x01)
+	require.NoError(t, info.UnmarshalDUML(b))
+	require.Equal(t, "WM240", info.HardwareVersion)
+	require.Equal(t, "01.00.00.07", info.LoaderVersion.String())
+	require.Equal(t, "01.02.03.04", info.FirmwareVersion.String())
+	require.Nil(t, info.Extra)
+
+	require.Error(t, info.UnmarshalDUML(b[:24]))
+}
@@ -50,10 +50,15 @@ func (id ComponentID) String() string {
 	case ComponentIDPairer:
 		return "pairer"
 	default:
-		return fmt.Sprintf("%02X", uint8(id))
+		return fmt.Sprintf("0x%02X", uint8(id))
 	}
 }
 
+// isNamed returns false if String returns the number of the component.
+func (id ComponentID) isNamed() bool {
+	return id.String() != fmt.Sprintf("0x%02X", uint8(id))
+}
+
 var (
 	InterfaceIDAppToApp               = InterfaceID{Sender: ComponentIDApp, Receiver: ComponentIDApp}
 	InterfaceIDAppToCamera            = InterfaceID{Sender: ComponentIDApp, Receiver: ComponentIDCamera}
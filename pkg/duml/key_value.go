@@ -0,0 +1,221 @@
+package duml
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+// KeyValueOp is the first byte of the payload of MessageTypeStartStopStreaming.
+//
+// Despite the name of the message type, the payload looks like a generic
+// "get/set a camera setting by key" request.
+type KeyValueOp uint8
+
+const (
+	KeyValueOpGet = KeyValueOp(0x00) // experimental (see KeyValueKey.IsExperimental)
+	KeyValueOpSet = KeyValueOp(0x01)
+)
+
+func (op KeyValueOp) String() string {
+	switch op {
+	case KeyValueOpGet:
+		return "get"
+	case KeyValueOpSet:
+		return "set"
+	default:
+		return fmt.Sprintf("0x%02X", uint8(op))
+	}
+}
+
+// KeyValueKey is a camera setting key.
+type KeyValueKey uint16
+
+const (
+	// KeyValueKeyLiveStream is used to start/stop live streaming,
+	// it is also used to set image stabilization on DeviceTypeOsmoAction5Pro.
+	KeyValueKeyLiveStream = KeyValueKey(0x001A)
+
+	// KeyValueKeyImageStabilization is used to set image stabilization.
+	KeyValueKeyImageStabilization = KeyValueKey(0x0008)
+
+	// KeyValueKeyPrepareToLiveStream is requested when preparing to live stream.
+	KeyValueKeyPrepareToLiveStream = KeyValueKey(0x001C)
+
+	// KeyValueKeyCameraMode is the camera mode (see DeviceType.EncodeCameraMode);
+	// experimental.
+	KeyValueKeyCameraMode = KeyValueKey(0x0002)
+
+	// KeyValueKeyVideoFormat is the format of the local recording (see
+	// DeviceType.EncodeVideoFormat); experimental.
+	KeyValueKeyVideoFormat = KeyValueKey(0x0003)
+
+	// The exposure-related settings (see ExposureSettings); experimental.
+	KeyValueKeyEVCompensation = KeyValueKey(0x0004)
+	KeyValueKeyISO            = KeyValueKey(0x0005)
+	KeyValueKeyShutter        = KeyValueKey(0x0006)
+	KeyValueKeyWhiteBalance   = KeyValueKey(0x0007)
+	KeyValueKeyColorProfile   = KeyValueKey(0x0009)
+)
+
+// IsExperimental returns true if the key (or the encoding of its value) is experimental.
+//
+// An experimental encoding (a key, a value, a command ID or a payload layout)
+// is assumed, not confirmed by a capture of the traffic of a real device, so
+// a real device may interpret it as something else. Such encodings are marked
+// as experimental across this package, and djiapi does not send them unless
+// allowed explicitly (see djiapi.ErrExperimental).
+func (k KeyValueKey) IsExperimental() bool {
+	switch k {
+	case KeyValueKeyCameraMode, KeyValueKeyVideoFormat,
+		KeyValueKeyEVCompensation, KeyValueKeyISO, KeyValueKeyShutter, KeyValueKeyWhiteBalance, KeyValueKeyColorProfile:
+		return true
+	}
+	return false
+}
+
+// KeyValueItem is a single key (and value if KeyValueOpSet) of a KeyValueRequest.
+type KeyValueItem struct {
+	Key   KeyValueKey
+	Value []byte
+}
+
+// KeyValueRequest is the payload of MessageTypeStartStopStreaming.
+//
+// Layout:
+//
+//	[0]     - KeyValueOp
+//	[1]     - the amount of items
+//	[2:...] - items:
+//	          [0:2] - key (little endian)
+//	          [2]   - the length of the value (only if KeyValueOpSet)
+//	          [3:]  - the value (only if KeyValueOpSet)
+type KeyValueRequest struct {
+	Op    KeyValueOp
+	Items []KeyValueItem
+}
+
+var _ Payload = (*KeyValueRequest)(nil)
+
+func (r *KeyValueRequest) MarshalDUML() ([]byte, error) {
+	if len(r.Items) > math.MaxUint8 {
+		return nil, fmt.Errorf("too many items: %d > %d", len(r.Items), math.MaxUint8)
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(uint8(r.Op))
+	buf.WriteByte(uint8(len(r.Items)))
+	for _, item := range r.Items {
+		var key [2]byte
+		BinaryOrder().PutUint16(key[:], uint16(item.Key))
+		must(buf.Write(key[:]))
+		if r.Op == KeyValueOpGet {
+			continue
+		}
+		if len(item.Value) > math.MaxUint8 {
+			return nil, fmt.Errorf("too long value of key 0x%04X: %d > %d", item.Key, len(item.Value), math.MaxUint8)
+		}
+		buf.WriteByte(uint8(len(item.Value)))
+		must(buf.Write(item.Value))
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *KeyValueRequest) UnmarshalDUML(b []byte) error {
+	if len(b) < 2 {
+		return fmt.Errorf("the payload is too short: %d < 2", len(b))
+	}
+	r.Op = KeyValueOp(b[0])
+	count := int(b[1])
+	b = b[2:]
+	r.Items = make([]KeyValueItem, 0, count)
+	for i := 0; i < count; i++ {
+		if len(b) < 2 {
+			return fmt.Errorf("the payload is too short for item #%d", i)
+		}
+		item := KeyValueItem{
+			Key: KeyValueKey(BinaryOrder().Uint16(b[:2])),
+		}
+		b = b[2:]
+		if r.Op != KeyValueOpGet {
+			if len(b) < 1 {
+				return fmt.Errorf("the payload is too short for the value length of item #%d", i)
+			}
+			length := int(b[0])
+			if len(b) < 1+length {
+				return fmt.Errorf("the payload is too short for the value of item #%d: %d < %d", i, len(b)-1, length)
+			}
+			item.Value = append([]byte{}, b[1:1+length]...)
+			b = b[1+length:]
+		}
+		r.Items = append(r.Items, item)
+	}
+	if len(b) != 0 {
+		return fmt.Errorf("unexpected %d trailing bytes: %X", len(b), b)
+	}
+	return nil
+}
+
+// NewKeyValueSetRequest returns a KeyValueRequest setting a single key.
+func NewKeyValueSetRequest(key KeyValueKey, value ...byte) *KeyValueRequest {
+	return &KeyValueRequest{
+		Op: KeyValueOpSet,
+		Items: []KeyValueItem{{
+			Key:   key,
+			Value: value,
+		}},
+	}
+}
+
+// NewKeyValueGetRequest returns a KeyValueRequest getting the given keys.
+func NewKeyValueGetRequest(keys ...KeyValueKey) *KeyValueRequest {
+	r := &KeyValueRequest{
+		Op: KeyValueOpGet,
+	}
+	for _, key := range keys {
+		r.Items = append(r.Items, KeyValueItem{Key: key})
+	}
+	return r
+}
+
+// KeyValuePush is the payload of MessageTypeKeyValuePush: the current values
+// of the settings, pushed when they change or are requested with KeyValueOpGet.
+//
+// The layout is experimental: the same as of KeyValueRequest with
+// KeyValueOpSet, but without the KeyValueOp byte.
+type KeyValuePush struct {
+	Items []KeyValueItem
+}
+
+var _ Payload = (*KeyValuePush)(nil)
+
+func (p *KeyValuePush) MarshalDUML() ([]byte, error) {
+	b, err := (&KeyValueRequest{Op: KeyValueOpSet, Items: p.Items}).MarshalDUML()
+	if err != nil {
+		return nil, err
+	}
+	return b[1:], nil
+}
+
+func (p *KeyValuePush) UnmarshalDUML(b []byte) error {
+	var req KeyValueRequest
+	if err := req.UnmarshalDUML(append([]byte{uint8(KeyValueOpSet)}, b...)); err != nil {
+		return err
+	}
+	p.Items = req.Items
+	return nil
+}
+
+// Get returns the value of the key, if the push contains it.
+func (p *KeyValuePush) Get(key KeyValueKey) ([]byte, bool) {
+	for _, item := range p.Items {
+		if item.Key == key {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+func init() {
+	RegisterPayload(MessageTypeStartStopStreaming, func() Payload { return &KeyValueRequest{} })
+	RegisterPayload(MessageTypeKeyValuePush, func() Payload { return &KeyValuePush{} })
+}
@@ -0,0 +1,71 @@
+package duml
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// LiveStreamConfig is the payload of MessageTypeConfigureStreaming.
+//
+// Layout:
+//
+//	[0]     - 0x00
+//	[1]     - a device-specific byte (see DeviceType.BytesFixedStartStreaming)
+//	[2]     - 0x00
+//	[3]     - Resolution
+//	[4:6]   - bitrate in Kbps (little endian)
+//	[6:8]   - 0x02 0x00
+//	[8]     - FPS
+//	[9:12]  - 0x00 0x00 0x00
+//	[12:]   - the URL (see PackURL)
+type LiveStreamConfig struct {
+	DeviceSpecificByte uint8
+	Resolution         Resolution
+	BitrateKbps        uint16
+	FPS                FPS
+	URL                string
+}
+
+var _ Payload = (*LiveStreamConfig)(nil)
+
+const liveStreamConfigHeaderLength = 12
+
+func (c *LiveStreamConfig) MarshalDUML() ([]byte, error) {
+	if err := checkStringLength(c.URL, math.MaxUint16); err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	var buf bytes.Buffer
+	must(buf.Write([]byte{0x00, c.DeviceSpecificByte, 0x00}))
+	must(buf.Write(array1ToSlice(c.Resolution.BytesFixed())))
+	cannotFail(binary.Write(&buf, BinaryOrder(), c.BitrateKbps))
+	must(buf.Write([]byte{0x02, 0x00}))
+	must(buf.Write(array1ToSlice(c.FPS.BytesFixed())))
+	must(buf.Write([]byte{0x00, 0x00, 0x00}))
+	must(buf.Write(PackURL(c.URL)))
+	return buf.Bytes(), nil
+}
+
+func (c *LiveStreamConfig) UnmarshalDUML(b []byte) error {
+	if len(b) < liveStreamConfigHeaderLength {
+		return fmt.Errorf("the payload is too short: %d < %d", len(b), liveStreamConfigHeaderLength)
+	}
+	c.DeviceSpecificByte = b[1]
+	c.Resolution = ResolutionFromBytesFixed([1]byte{b[3]})
+	c.BitrateKbps = BinaryOrder().Uint16(b[4:6])
+	c.FPS = FPSFromBytesFixed([1]byte{b[8]})
+	url, rest, err := UnpackURL(b[liveStreamConfigHeaderLength:])
+	if err != nil {
+		return fmt.Errorf("unable to unpack the URL: %w", err)
+	}
+	if len(rest) != 0 {
+		return fmt.Errorf("unexpected %d trailing bytes: %X", len(rest), rest)
+	}
+	c.URL = url
+	return nil
+}
+
+func init() {
+	RegisterPayload(MessageTypeConfigureStreaming, func() Payload { return &LiveStreamConfig{} })
+}
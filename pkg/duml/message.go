@@ -24,6 +24,10 @@ type Message struct {
 	ID        MessageID
 	Type      MessageType
 	Payload   []byte
+
+	// AutoID makes the connection allocate the ID when sending the message
+	// (see Sequencer.Assign); if unset, the ID is sent as is.
+	AutoID bool
 }
 
 func ParseMessage(b []byte) (_ret *Message, _err error) {
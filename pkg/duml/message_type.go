@@ -140,12 +140,14 @@ const (
 
 	// --- Video / Camera (Set 0x02) ---
 	CommandIDTakeRecord             CommandID = 0x02
+	CommandIDRecordingStatus        CommandID = 0x82 // experimental (see KeyValueKey.IsExperimental)
 	CommandIDGogglesModeToggle      CommandID = 0x06
 	CommandIDOsmoBroadcastConfig    CommandID = 0x08
 	CommandIDVideoStreamSubscribe   CommandID = 0x3C
 	CommandIDVideoStreamUnsubscribe CommandID = 0x3D
 	CommandIDPairingStarted         CommandID = 0x80
 	CommandIDStartStopStreaming     CommandID = 0x8E
+	CommandIDKeyValuePush           CommandID = 0x8F // experimental
 	CommandIDPrepareToLiveStream    CommandID = 0xE1
 
 	// --- Flight Control (Set 0x03) ---
@@ -154,8 +156,10 @@ const (
 	CommandIDGogglesMode     CommandID = 0x3D
 
 	// --- Gimbal (Set 0x04) ---
-	CommandIDMaybeStatus CommandID = 0x05
-	CommandIDKeepAlive   CommandID = 0x27
+	CommandIDMaybeStatus   CommandID = 0x05
+	CommandIDGimbalMove    CommandID = 0x0A // experimental
+	CommandIDKeepAlive     CommandID = 0x27
+	CommandIDGimbalSetMode CommandID = 0x4C // experimental
 
 	// --- Remote Controller (Set 0x06) ---
 	CommandIDRemoteControllerSimulatorData CommandID = 0x24
@@ -181,17 +185,23 @@ var (
 	// See: https://github.com/xaionaro/reverse-engineering-dji
 
 	// --- Common / Config (Set 0x00) ---
-	MessageTypeGetSerialNum  = MessageTypeRequest(CommandSetCore, CommandIDGetSerialNum)
-	MessageTypeHeartbeat     = MessageTypeRequest(CommandSetCore, CommandIDHeartbeat)
-	MessageTypePairingStage2 = MessageTypeRequest(CommandSetCore, CommandIDPairingStage2)
-	MessageTypeParameterPush = MessageTypeNotification(CommandSetCore, CommandIDParameterPush)
-	MessageTypeFCCSupport    = MessageTypeRequest(CommandSetCore, CommandIDFCCSupport)
+	MessageTypeGetSerialNum       = MessageTypeRequest(CommandSetCore, CommandIDGetSerialNum)
+	MessageTypeGetSerialNumResult = MessageTypeResponse(CommandSetCore, CommandIDGetSerialNum)
+	MessageTypeHeartbeat          = MessageTypeRequest(CommandSetCore, CommandIDHeartbeat)
+	MessageTypePairingStage2      = MessageTypeRequest(CommandSetCore, CommandIDPairingStage2)
+	MessageTypeParameterPush      = MessageTypeNotification(CommandSetCore, CommandIDParameterPush)
+	MessageTypeFCCSupport         = MessageTypeRequest(CommandSetCore, CommandIDFCCSupport)
 
 	// --- General / Info (Set 0x01) ---
-	MessageTypeGetProductID = MessageTypeRequest(CommandSetInfo, CommandIDGetProductID)
-	MessageTypeGetVersion   = MessageTypeRequest(CommandSetInfo, CommandIDGetVersion)
+	MessageTypeGetProductID       = MessageTypeRequest(CommandSetInfo, CommandIDGetProductID)
+	MessageTypeGetProductIDResult = MessageTypeResponse(CommandSetInfo, CommandIDGetProductID)
+	MessageTypeGetVersion         = MessageTypeRequest(CommandSetInfo, CommandIDGetVersion)
+	MessageTypeGetVersionResult   = MessageTypeResponse(CommandSetInfo, CommandIDGetVersion)
 
 	// --- Video / Camera (Set 0x02) (also suspected to be reusable for Goggles 2 / USB) ---
+	MessageTypeTakeRecord                = MessageTypeRequest(CommandSetCamera, CommandIDTakeRecord)
+	MessageTypeTakeRecordResult          = MessageTypeResponse(CommandSetCamera, CommandIDTakeRecord)
+	MessageTypeRecordingStatus           = MessageTypeNotification(CommandSetCamera, CommandIDRecordingStatus)
 	MessageTypeGogglesModeToggle         = MessageTypeRequest(CommandSetCamera, CommandIDGogglesModeToggle)
 	MessageTypeOsmoBroadcastConfig       = MessageTypeRequest(CommandSetCamera, CommandIDOsmoBroadcastConfig)
 	MessageTypeVideoStreamSubscribe      = MessageTypeRequest(CommandSetCamera, CommandIDVideoStreamSubscribe)
@@ -199,6 +209,7 @@ var (
 	MessageTypePairingStarted            = MessageTypeNotification(CommandSetCamera, CommandIDPairingStarted)
 	MessageTypeStartStopStreaming        = MessageTypeRequest(CommandSetCamera, CommandIDStartStopStreaming)
 	MessageTypeStartStopStreamingResult  = MessageTypeResponse(CommandSetCamera, CommandIDStartStopStreaming)
+	MessageTypeKeyValuePush              = MessageTypeNotification(CommandSetCamera, CommandIDKeyValuePush)
 	MessageTypePrepareToLiveStream       = MessageTypeRequest(CommandSetCamera, CommandIDPrepareToLiveStream)
 	MessageTypePrepareToLiveStreamResult = MessageTypeResponse(CommandSetCamera, CommandIDPrepareToLiveStream, MessageTypeFlagAckRequired)
 
@@ -208,9 +219,16 @@ var (
 	MessageTypeGogglesMode     = MessageTypeRequest(CommandSetFlightController, CommandIDGogglesMode)
 
 	// --- InterfaceID: FlightControllerToApp (0x0402) ---
+	// MessageTypeUnknown0MaybeStatus is the gimbal status push (see GimbalStatus).
 	MessageTypeUnknown0MaybeStatus = MessageTypeNotification(CommandSetUnknown0, CommandIDMaybeStatus)
 	MessageTypeKeepAlive           = MessageTypeNotification(CommandSetUnknown0, CommandIDKeepAlive)
 
+	// --- InterfaceID: AppToGimbal ---
+	MessageTypeGimbalMove          = MessageTypeRequest(CommandSetUnknown0, CommandIDGimbalMove)
+	MessageTypeGimbalMoveResult    = MessageTypeResponse(CommandSetUnknown0, CommandIDGimbalMove)
+	MessageTypeGimbalSetMode       = MessageTypeRequest(CommandSetUnknown0, CommandIDGimbalSetMode)
+	MessageTypeGimbalSetModeResult = MessageTypeResponse(CommandSetUnknown0, CommandIDGimbalSetMode)
+
 	// --- Remote Controller / Simulator (Set 0x06) ---
 	MessageTypeRemoteControllerSimulatorData = MessageTypeNotification(CommandSetRemoteController, CommandIDRemoteControllerSimulatorData)
 
@@ -250,87 +268,75 @@ func (t MessageType) GetCmdID() uint8 {
 	return uint8(t.CmdID)
 }
 
+// messageTypeNames are the names of the known message types, see MessageType.String.
+var messageTypeNames = map[MessageType]string{
+	MessageTypeGetVersion:                    "get_version",
+	MessageTypeGetVersionResult:              "get_version_result",
+	MessageTypeGetProductID:                  "get_product_id",
+	MessageTypeGetProductIDResult:            "get_product_id_result",
+	MessageTypeVideoStreamSubscribe:          "video_stream_subscribe",
+	MessageTypeVideoStreamUnsubscribe:        "video_stream_unsubscribe",
+	MessageTypeGogglesModeToggle:             "goggles_mode_toggle",
+	MessageTypeGogglesMode:                   "goggles_mode",
+	MessageTypeRemoteControllerSimulatorData: "remote_controller_simulator_data",
+	MessageTypeBatteryStatus:                 "battery_status",
+	MessageTypeGetBatteryInfo:                "get_battery_info",
+	MessageTypeFlightStickData:               "flight_stick_data",
+	MessageTypeMotorControl:                  "motor_control",
+	MessageTypeFCCSupport:                    "fcc_support",
+	MessageTypeGetSerialNum:                  "get_serial_num",
+	MessageTypeGetSerialNumResult:            "get_serial_num_result",
+	MessageTypeHeartbeat:                     "heartbeat",
+	MessageTypeParameterPush:                 "parameter_push",
+	MessageTypeUnknown0MaybeStatus:           "gimbal_status",
+	MessageTypeKeepAlive:                     "keep_alive",
+	MessageTypePairingStarted:                "pairing_started",
+	MessageTypeSetPairingPIN:                 "set_pairing_pin",
+	MessageTypePairingStatus:                 "pairing_status",
+	MessageTypePairingPINApproved:            "pairing_pin_approved",
+	MessageTypePairingStage1:                 "pairing_stage1",
+	MessageTypePairingStage2:                 "pairing_stage2",
+	MessageTypeConnectToWiFi:                 "connect_to_wifi",
+	MessageTypePrepareToLiveStream:           "prepare_to_live_stream",
+	MessageTypePrepareToLiveStreamResult:     "prepare_to_live_stream_result",
+	MessageTypeConfigureStreaming:            "configure_stream",
+	MessageTypeConfigureStreamingResult:      "configure_stream_result",
+	MessageTypeStartStopStreaming:            "start_OR_stop_streaming",
+	MessageTypeStartStopStreamingResult:      "start_OR_stop_streaming_result",
+	MessageTypeWiFiScanReport:                "wifi_scan_results",
+	MessageTypeStartScanningWiFi:             "start_scanning_wifi",
+	MessageTypeStartScanningWiFiResult:       "start_scanning_wifi_result",
+	MessageTypeCameraAPInfo:                  "camera_ap_info",
+	MessageTypeGetCameraAPPSK:                "get_camera_ap_psk",
+	MessageTypeCameraAPInfoResultSSID:        "camera_ap_info_result_ssid",
+	MessageTypeCameraAPInfoResultPSK:         "camera_ap_info_result_psk",
+	MessageTypeOsmoBroadcastConfig:           "osmo_broadcast_config",
+	MessageTypeTakeRecord:                    "take_record",
+	MessageTypeTakeRecordResult:              "take_record_result",
+	MessageTypeRecordingStatus:               "recording_status",
+	MessageTypeKeyValuePush:                  "key_value_push",
+	MessageTypeGimbalMove:                    "gimbal_move",
+	MessageTypeGimbalMoveResult:              "gimbal_move_result",
+	MessageTypeGimbalSetMode:                 "gimbal_set_mode",
+	MessageTypeGimbalSetModeResult:           "gimbal_set_mode_result",
+}
+
 func (t MessageType) String() string {
-	switch t {
-	case MessageTypeGetVersion:
-		return "get_version"
-	case MessageTypeGetProductID:
-		return "get_product_id"
-	case MessageTypeVideoStreamSubscribe:
-		return "video_stream_subscribe"
-	case MessageTypeVideoStreamUnsubscribe:
-		return "video_stream_unsubscribe"
-	case MessageTypeGogglesModeToggle:
-		return "goggles_mode_toggle"
-	case MessageTypeGogglesMode:
-		return "goggles_mode"
-	case MessageTypeRemoteControllerSimulatorData:
-		return "remote_controller_simulator_data"
-	case MessageTypeBatteryStatus:
-		return "battery_status"
-	case MessageTypeGetBatteryInfo:
-		return "get_battery_info"
-	case MessageTypeFlightStickData:
-		return "flight_stick_data"
-	case MessageTypeMotorControl:
-		return "motor_control"
-	case MessageTypeFCCSupport:
-		return "fcc_support"
-	case MessageTypeGetSerialNum:
-		return "get_serial_num"
-	case MessageTypeHeartbeat:
-		return "heartbeat"
-	case MessageTypeParameterPush:
-		return "parameter_push"
-	case MessageTypeUnknown0MaybeStatus:
-		return "gimbal_status"
-	case MessageTypeKeepAlive:
-		return "keep_alive"
-	case MessageTypePairingStarted:
-		return "pairing_started"
-	case MessageTypeSetPairingPIN:
-		return "set_pairing_pin"
-	case MessageTypePairingStatus:
-		return "pairing_status"
-	case MessageTypePairingPINApproved:
-		return "pairing_pin_approved"
-	case MessageTypePairingStage1:
-		return "pairing_stage1"
-	case MessageTypePairingStage2:
-		return "pairing_stage2"
-	case MessageTypeConnectToWiFi:
-		return "connect_to_wifi"
-	case MessageTypePrepareToLiveStream:
-		return "prepare_to_live_stream"
-	case MessageTypePrepareToLiveStreamResult:
-		return "prepare_to_live_stream_result"
-	case MessageTypeConfigureStreaming:
-		return "configure_stream"
-	case MessageTypeConfigureStreamingResult:
-		return "configure_stream_result"
-	case MessageTypeStartStopStreaming:
-		return "start_OR_stop_streaming"
-	case MessageTypeStartStopStreamingResult:
-		return "start_OR_stop_streaming_result"
-	case MessageTypeWiFiScanReport:
-		return "wifi_scan_results"
-	case MessageTypeStartScanningWiFi:
-		return "start_scanning_wifi"
-	case MessageTypeStartScanningWiFiResult:
-		return "start_scanning_wifi_result"
-	case MessageTypeCameraAPInfo:
-		return "camera_ap_info"
-	case MessageTypeGetCameraAPPSK:
-		return "get_camera_ap_psk"
-	case MessageTypeCameraAPInfoResultSSID:
-		return "camera_ap_info_result_ssid"
-	case MessageTypeCameraAPInfoResultPSK:
-		return "camera_ap_info_result_psk"
-	case MessageTypeOsmoBroadcastConfig:
-		return "osmo_broadcast_config"
-	default:
-		return fmt.Sprintf("flags:%s set:%s id:%s", t.Flags, t.CmdSet, t.CmdID)
+	if name, ok := messageTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("flags:%s set:%s id:%s", t.Flags, t.CmdSet, t.CmdID)
+}
+
+// IsKnown returns true if the command of the message type (the command set
+// and the command ID, regardless of the flags) has a name, see String.
+func (t MessageType) IsKnown() bool {
+	for known := range messageTypeNames {
+		if known.CmdSet == t.CmdSet && known.CmdID == t.CmdID {
+			return true
+		}
 	}
+	return false
 }
 
 func (t *MessageType) ParseFrom(r io.Reader) error {
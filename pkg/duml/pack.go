@@ -37,3 +37,45 @@ func UnpackStringU16BE(b []byte) (string, error) {
 	}
 	return string(b[2 : 2+length]), nil
 }
+
+// UnpackString is the reverse of PackString; it also returns the rest of the bytes.
+func UnpackString(b []byte) (string, []byte, error) {
+	if len(b) < 1 {
+		return "", nil, fmt.Errorf("too short payload: %d", len(b))
+	}
+	length := int(b[0])
+	if len(b) < 1+length {
+		return "", nil, fmt.Errorf("payload too short for length %d: %d", length, len(b))
+	}
+	return string(b[1 : 1+length]), b[1+length:], nil
+}
+
+// UnpackURL is the reverse of PackURL; it also returns the rest of the bytes.
+func UnpackURL(b []byte) (string, []byte, error) {
+	if len(b) < 2 {
+		return "", nil, fmt.Errorf("too short payload: %d", len(b))
+	}
+	length := int(BinaryOrder().Uint16(b[:2]))
+	if len(b) < 2+length {
+		return "", nil, fmt.Errorf("payload too short for length %d: %d", length, len(b))
+	}
+	return string(b[2 : 2+length]), b[2+length:], nil
+}
+
+// PackStringU16BE is the reverse of UnpackStringU16BE.
+func PackStringU16BE(in string) []byte {
+	if len(in) > math.MaxUint16 {
+		panic(fmt.Errorf("too long string: %d > %d", len(in), math.MaxUint16))
+	}
+	var buf bytes.Buffer
+	cannotFail(binary.Write(&buf, binary.BigEndian, uint16(len(in))))
+	must(buf.WriteString(in))
+	return buf.Bytes()
+}
+
+func checkStringLength(in string, max int) error {
+	if len(in) > max {
+		return fmt.Errorf("too long string: %d > %d", len(in), max)
+	}
+	return nil
+}
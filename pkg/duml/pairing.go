@@ -0,0 +1,88 @@
+package duml
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+// SetPairingPINRequest is the payload of MessageTypeSetPairingPIN.
+type SetPairingPINRequest struct {
+	AppID string
+	PIN   string
+}
+
+var _ Payload = (*SetPairingPINRequest)(nil)
+
+func (r *SetPairingPINRequest) MarshalDUML() ([]byte, error) {
+	if err := checkStringLength(r.AppID, math.MaxUint8); err != nil {
+		return nil, fmt.Errorf("invalid app ID: %w", err)
+	}
+	if err := checkStringLength(r.PIN, math.MaxUint8); err != nil {
+		return nil, fmt.Errorf("invalid PIN: %w", err)
+	}
+	var buf bytes.Buffer
+	must(buf.Write(PackString(r.AppID)))
+	must(buf.Write(PackString(r.PIN)))
+	return buf.Bytes(), nil
+}
+
+func (r *SetPairingPINRequest) UnmarshalDUML(b []byte) error {
+	appID, b, err := UnpackString(b)
+	if err != nil {
+		return fmt.Errorf("unable to unpack the app ID: %w", err)
+	}
+	pin, b, err := UnpackString(b)
+	if err != nil {
+		return fmt.Errorf("unable to unpack the PIN: %w", err)
+	}
+	if len(b) != 0 {
+		return fmt.Errorf("unexpected %d trailing bytes: %X", len(b), b)
+	}
+	r.AppID, r.PIN = appID, pin
+	return nil
+}
+
+type PairingState uint8
+
+const (
+	PairingStateNotPaired     = PairingState(0x00)
+	PairingStateAlreadyPaired = PairingState(0x01)
+)
+
+func (s PairingState) String() string {
+	switch s {
+	case PairingStateNotPaired:
+		return "not_paired"
+	case PairingStateAlreadyPaired:
+		return "already_paired"
+	default:
+		return fmt.Sprintf("0x%02X", uint8(s))
+	}
+}
+
+// PairingStatus is the payload of MessageTypePairingStatus.
+type PairingStatus struct {
+	Code  ResultCode
+	State PairingState
+}
+
+var _ Payload = (*PairingStatus)(nil)
+
+func (s *PairingStatus) MarshalDUML() ([]byte, error) {
+	return []byte{uint8(s.Code), uint8(s.State)}, nil
+}
+
+func (s *PairingStatus) UnmarshalDUML(b []byte) error {
+	if len(b) < 2 {
+		return fmt.Errorf("the payload is too short: %d < 2", len(b))
+	}
+	s.Code = ResultCode(b[0])
+	s.State = PairingState(b[1])
+	return nil
+}
+
+func init() {
+	RegisterPayload(MessageTypeSetPairingPIN, func() Payload { return &SetPairingPINRequest{} })
+	RegisterPayload(MessageTypePairingStatus, func() Payload { return &PairingStatus{} })
+}
@@ -0,0 +1,109 @@
+package duml
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math"
+)
+
+// ParameterValue is the value of a parameter, as is; its type is not
+// transmitted, so the interpretation is up to the consumer (see Uint).
+type ParameterValue []byte
+
+// Uint returns the value as a little-endian unsigned integer, if its length is 1, 2, 4 or 8 bytes.
+func (v ParameterValue) Uint() (uint64, bool) {
+	switch len(v) {
+	case 1:
+		return uint64(v[0]), true
+	case 2:
+		return uint64(binaryOrder.Uint16(v)), true
+	case 4:
+		return uint64(binaryOrder.Uint32(v)), true
+	case 8:
+		return binaryOrder.Uint64(v), true
+	}
+	return 0, false
+}
+
+func (v ParameterValue) String() string {
+	if u, ok := v.Uint(); ok {
+		return fmt.Sprintf("%d (0x%s)", u, hex.EncodeToString(v))
+	}
+	return "0x" + hex.EncodeToString(v)
+}
+
+// ParameterEntry is a named value of a ParameterPush.
+type ParameterEntry struct {
+	Name  string
+	Value ParameterValue
+}
+
+const parameterPushHeaderLength = 11
+
+// ParameterPush is the payload of MessageTypeParameterPush.
+//
+// The layout is experimental (see KeyValueKey.IsExperimental), but it fits the captures seen so far:
+//
+//	[0:11]  - the header, not yet understood
+//	[11:]   - the entries, each is:
+//	          [0:2]  - the length of the rest of the entry (little-endian)
+//	          [2:4]  - the length of the name (little-endian)
+//	          [4:]   - the name (ASCII), followed by the value
+type ParameterPush struct {
+	Header  [parameterPushHeaderLength]byte
+	Entries []ParameterEntry
+}
+
+var _ Payload = (*ParameterPush)(nil)
+
+func (p *ParameterPush) MarshalDUML() ([]byte, error) {
+	var buf bytes.Buffer
+	must(buf.Write(p.Header[:]))
+	for _, entry := range p.Entries {
+		length := 2 + len(entry.Name) + len(entry.Value)
+		if length > math.MaxUint16 {
+			return nil, fmt.Errorf("the entry '%s' is too long: %d > %d", entry.Name, length, math.MaxUint16)
+		}
+		var lengths [4]byte
+		binaryOrder.PutUint16(lengths[0:], uint16(length))
+		binaryOrder.PutUint16(lengths[2:], uint16(len(entry.Name)))
+		must(buf.Write(lengths[:]))
+		must(buf.WriteString(entry.Name))
+		must(buf.Write(entry.Value))
+	}
+	return buf.Bytes(), nil
+}
+
+func (p *ParameterPush) UnmarshalDUML(b []byte) error {
+	if len(b) < parameterPushHeaderLength {
+		return fmt.Errorf("payload is too short: %d < %d", len(b), parameterPushHeaderLength)
+	}
+	copy(p.Header[:], b)
+	b = b[parameterPushHeaderLength:]
+
+	p.Entries = nil
+	for len(b) > 0 {
+		if len(b) < 4 {
+			return fmt.Errorf("unexpected %d trailing bytes: %X", len(b), b)
+		}
+		length := int(binaryOrder.Uint16(b[0:]))
+		nameLength := int(binaryOrder.Uint16(b[2:]))
+		if length < 2+nameLength {
+			return fmt.Errorf("the entry length %d is less than the name length %d", length, nameLength)
+		}
+		if len(b) < 2+length {
+			return fmt.Errorf("payload too short for the entry length %d: %d", length, len(b)-2)
+		}
+		p.Entries = append(p.Entries, ParameterEntry{
+			Name:  string(b[4 : 4+nameLength]),
+			Value: ParameterValue(bytes.Clone(b[4+nameLength : 2+length])),
+		})
+		b = b[2+length:]
+	}
+	return nil
+}
+
+func init() {
+	RegisterPayload(MessageTypeParameterPush, func() Payload { return &ParameterPush{} })
+}
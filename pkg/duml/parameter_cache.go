@@ -0,0 +1,155 @@
+package duml
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+)
+
+// parameterWatchBufferSize is the capacity of the channel returned by ParameterCache.Watch.
+const parameterWatchBufferSize = 64
+
+// Parameter is the last known value of a parameter.
+type Parameter struct {
+	Name      string
+	Value     ParameterValue
+	UpdatedAt time.Time
+}
+
+type parameterWatcher struct {
+	names map[string]struct{}
+	ch    chan Parameter
+
+	// stopCtxWatch stops removing the watcher on the cancellation of its context.
+	stopCtxWatch func() bool
+}
+
+// ParameterCache keeps the last values received in MessageTypeParameterPush.
+type ParameterCache struct {
+	locker   sync.Mutex
+	values   map[string]Parameter
+	watchers map[*parameterWatcher]struct{}
+	closed   bool
+}
+
+// NewParameterCache returns an empty ParameterCache.
+func NewParameterCache() *ParameterCache {
+	return &ParameterCache{
+		values:   map[string]Parameter{},
+		watchers: map[*parameterWatcher]struct{}{},
+	}
+}
+
+// HandleMessage updates the cache if the message is a MessageTypeParameterPush.
+func (c *ParameterCache) HandleMessage(ctx context.Context, msg *Message) {
+	if msg.Type.CmdSet != MessageTypeParameterPush.CmdSet || msg.Type.CmdID != MessageTypeParameterPush.CmdID {
+		return
+	}
+	var push ParameterPush
+	if err := push.UnmarshalDUML(msg.Payload); err != nil {
+		logger.Warnf(ctx, "unable to parse the parameter push: %v", err)
+		return
+	}
+	c.Update(push.Entries...)
+}
+
+// Update sets the values of the parameters and notifies the watchers.
+func (c *ParameterCache) Update(entries ...ParameterEntry) {
+	now := time.Now()
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	for _, entry := range entries {
+		param := Parameter{
+			Name:      entry.Name,
+			Value:     slices.Clone(entry.Value),
+			UpdatedAt: now,
+		}
+		c.values[entry.Name] = param
+		for w := range c.watchers {
+			if len(w.names) != 0 {
+				if _, ok := w.names[entry.Name]; !ok {
+					continue
+				}
+			}
+			select {
+			case w.ch <- param:
+			default:
+				// dropping the oldest update to not block the receiving loop
+				select {
+				case <-w.ch:
+				default:
+				}
+				w.ch <- param
+			}
+		}
+	}
+}
+
+// Get returns the last known value of the parameter.
+func (c *ParameterCache) Get(name string) (Parameter, bool) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	param, ok := c.values[name]
+	return param, ok
+}
+
+// List returns the last known values of all the parameters, sorted by name.
+func (c *ParameterCache) List() []Parameter {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	result := make([]Parameter, 0, len(c.values))
+	for _, param := range c.values {
+		result = append(result, param)
+	}
+	slices.SortFunc(result, func(a, b Parameter) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result
+}
+
+// Watch returns the channel of the updates of the given parameters (of all
+// the parameters, if none are given). The channel is closed when the context
+// is cancelled or the cache is closed; if the consumer is too slow, the
+// oldest updates are dropped.
+func (c *ParameterCache) Watch(ctx context.Context, names ...string) <-chan Parameter {
+	w := &parameterWatcher{
+		names: map[string]struct{}{},
+		ch:    make(chan Parameter, parameterWatchBufferSize),
+	}
+	for _, name := range names {
+		w.names[name] = struct{}{}
+	}
+
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	if c.closed {
+		close(w.ch)
+		return w.ch
+	}
+	c.watchers[w] = struct{}{}
+	w.stopCtxWatch = context.AfterFunc(ctx, func() {
+		c.locker.Lock()
+		defer c.locker.Unlock()
+		if _, ok := c.watchers[w]; ok {
+			delete(c.watchers, w)
+			close(w.ch)
+		}
+	})
+	return w.ch
+}
+
+// Close closes the channels of all the watchers.
+func (c *ParameterCache) Close() {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	c.closed = true
+	for w := range c.watchers {
+		w.stopCtxWatch()
+		close(w.ch)
+	}
+	clear(c.watchers)
+}
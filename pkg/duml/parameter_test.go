@@ -0,0 +1,88 @@
+package duml
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParameterPush(t *testing.T) {
+	// see the WiFi capture in djiwifi/controller_test.go
+	b, err := hex.DecodeString("553704f90228de94400099020200004b3400000000001d00170070726f647563745f736869656c6465645f636f6e666967000000000066")
+	require.NoError(t, err)
+	msg, err := ParseMessage(b)
+	require.NoError(t, err)
+	require.Equal(t, MessageTypeParameterPush.CmdSet, msg.Type.CmdSet)
+	require.Equal(t, MessageTypeParameterPush.CmdID, msg.Type.CmdID)
+
+	payload, err := msg.DecodePayload()
+	require.NoError(t, err)
+	push := payload.(*ParameterPush)
+	require.Equal(t, []ParameterEntry{{
+		Name:  "product_shielded_config",
+		Value: ParameterValue{0x00, 0x00, 0x00, 0x00},
+	}}, push.Entries)
+	v, ok := push.Entries[0].Value.Uint()
+	require.True(t, ok)
+	require.Zero(t, v)
+
+	marshaled, err := push.MarshalDUML()
+	require.NoError(t, err)
+	require.Equal(t, msg.Payload, marshaled)
+
+	require.Error(t, (&ParameterPush{}).UnmarshalDUML(msg.Payload[:len(msg.Payload)-1]))
+}
+
+func TestParameterCache(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var sent []*Message
+	e := NewEndpoint(1, func(ctx context.Context, msg *Message) error {
+		sent = append(sent, msg)
+		return nil
+	})
+	all := e.Parameters.Watch(ctx)
+	onlyB := e.Parameters.Watch(ctx, "b")
+
+	push := func(entries ...ParameterEntry) {
+		msg, err := NewMessage(InterfaceID{Sender: ComponentIDCamera, Receiver: ComponentIDApp}, 1, MessageTypeParameterPush, &ParameterPush{Entries: entries})
+		require.NoError(t, err)
+		e.HandleMessage(ctx, msg)
+	}
+	push(ParameterEntry{Name: "b", Value: ParameterValue{0x01}}, ParameterEntry{Name: "a", Value: ParameterValue{0x02, 0x00}})
+	push(ParameterEntry{Name: "b", Value: ParameterValue{0x03}})
+
+	param, ok := e.Parameters.Get("b")
+	require.True(t, ok)
+	require.Equal(t, ParameterValue{0x03}, param.Value)
+	_, ok = e.Parameters.Get("nonexistent")
+	require.False(t, ok)
+
+	list := e.Parameters.List()
+	require.Len(t, list, 2)
+	require.Equal(t, "a", list[0].Name)
+	require.Equal(t, "2 (0x0200)", list[0].Value.String())
+	require.Equal(t, "b", list[1].Name)
+
+	for _, name := range []string{"b", "a", "b"} {
+		require.Equal(t, name, (<-all).Name)
+	}
+	require.Equal(t, ParameterValue{0x01}, (<-onlyB).Value)
+	require.Equal(t, ParameterValue{0x03}, (<-onlyB).Value)
+
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	cancelled := e.Parameters.Watch(watchCtx)
+	watchCancel()
+	_, ok = <-cancelled
+	require.False(t, ok)
+
+	e.Close(ErrDisconnected)
+	_, ok = <-all
+	require.False(t, ok)
+	_, ok = <-e.Parameters.Watch(ctx)
+	require.False(t, ok)
+}
@@ -0,0 +1,111 @@
+package duml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseComponentID is the reverse of ComponentID.String (case-insensitive);
+// a decimal number is accepted as well.
+func ParseComponentID(s string) (ComponentID, error) {
+	if v, err := strconv.ParseUint(s, 0, 8); err == nil {
+		return ComponentID(v), nil
+	}
+	for v := 0; v <= 0xFF; v++ {
+		id := ComponentID(v)
+		if !id.isNamed() {
+			continue
+		}
+		if strings.EqualFold(id.String(), s) {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown component '%s'", s)
+}
+
+// ParseInterfaceID is the reverse of InterfaceID.String, e.g. "app->camera".
+func ParseInterfaceID(s string) (InterfaceID, error) {
+	sender, receiver, ok := strings.Cut(s, "->")
+	if !ok {
+		return InterfaceID{}, fmt.Errorf("invalid interface '%s', expected 'sender->receiver'", s)
+	}
+	var (
+		id  InterfaceID
+		err error
+	)
+	id.Sender, err = ParseComponentID(strings.TrimSpace(sender))
+	if err != nil {
+		return InterfaceID{}, fmt.Errorf("invalid sender: %w", err)
+	}
+	id.Receiver, err = ParseComponentID(strings.TrimSpace(receiver))
+	if err != nil {
+		return InterfaceID{}, fmt.Errorf("invalid receiver: %w", err)
+	}
+	return id, nil
+}
+
+// ParseCommandSet is the reverse of CommandSet.String (case-insensitive);
+// a 0x-prefixed or a decimal number is accepted as well.
+func ParseCommandSet(s string) (CommandSet, error) {
+	for v := 0; v <= 0xFF; v++ {
+		if cmdSet := CommandSet(v); strings.EqualFold(cmdSet.String(), s) {
+			return cmdSet, nil
+		}
+	}
+	v, err := strconv.ParseUint(s, 0, 8)
+	if err != nil {
+		return 0, fmt.Errorf("unknown command set '%s'", s)
+	}
+	return CommandSet(v), nil
+}
+
+// ParseCommandID is the reverse of CommandID.String; a decimal number is accepted as well.
+func ParseCommandID(s string) (CommandID, error) {
+	v, err := strconv.ParseUint(s, 0, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid command ID '%s': %w", s, err)
+	}
+	return CommandID(v), nil
+}
+
+// ParseMessageTypeFlags is the reverse of MessageTypeFlags.String, e.g.
+// "Request|AckRequired"; a number is accepted as well.
+func ParseMessageTypeFlags(s string) (MessageTypeFlags, error) {
+	if v, err := strconv.ParseUint(s, 0, 8); err == nil {
+		return MessageTypeFlags(v), nil
+	}
+	var flags MessageTypeFlags
+	for _, part := range strings.Split(s, "|") {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "request":
+		case "response":
+			flags |= MessageTypeFlagResponse
+		case "ack", "ackrequired":
+			flags |= MessageTypeFlagAckRequired
+		default:
+			return 0, fmt.Errorf("unknown flag '%s' in '%s'", part, s)
+		}
+	}
+	return flags, nil
+}
+
+// ParseMessageType is the reverse of MessageType.String for the known
+// message types (case-insensitive), e.g. "get_version".
+func ParseMessageType(s string) (MessageType, error) {
+	for t, name := range messageTypeNames {
+		if strings.EqualFold(name, s) {
+			return t, nil
+		}
+	}
+	return MessageType{}, fmt.Errorf("unknown message type '%s'", s)
+}
+
+// MessageTypeNames returns the names of the known message types (see MessageType.String).
+func MessageTypeNames() []string {
+	names := make([]string, 0, len(messageTypeNames))
+	for _, name := range messageTypeNames {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,103 @@
+package duml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMessageType(t *testing.T) {
+	for _, name := range MessageTypeNames() {
+		typ, err := ParseMessageType(name)
+		require.NoError(t, err)
+		require.Equal(t, name, typ.String())
+	}
+
+	typ, err := ParseMessageType("GET_VERSION")
+	require.NoError(t, err)
+	require.Equal(t, MessageTypeGetVersion, typ)
+
+	_, err = ParseMessageType("nonexistent")
+	require.Error(t, err)
+}
+
+func TestParseInterfaceID(t *testing.T) {
+	for _, id := range []InterfaceID{
+		InterfaceIDAppToCamera,
+		InterfaceIDFlightControllerToApp,
+		{Sender: ComponentIDApp, Receiver: ComponentIDWiFiAir},
+		{Sender: ComponentIDApp, Receiver: ComponentID(0x10)},
+	} {
+		parsed, err := ParseInterfaceID(id.String())
+		require.NoError(t, err)
+		require.Equal(t, id, parsed)
+	}
+
+	require.Equal(t, "app->0x10", InterfaceID{Sender: ComponentIDApp, Receiver: ComponentID(0x10)}.String())
+
+	id, err := ParseInterfaceID("app->0x17")
+	require.NoError(t, err)
+	require.Equal(t, InterfaceIDAppToGoggles, id)
+
+	id, err = ParseInterfaceID("0x10->app")
+	require.NoError(t, err)
+	require.Equal(t, InterfaceID{Sender: ComponentID(0x10), Receiver: ComponentIDApp}, id)
+
+	_, err = ParseInterfaceID("app")
+	require.Error(t, err)
+	_, err = ParseInterfaceID("app->nonexistent")
+	require.Error(t, err)
+}
+
+func TestParseComponentID(t *testing.T) {
+	for _, tc := range []struct {
+		Input    string
+		Expected ComponentID
+	}{
+		{Input: "camera", Expected: ComponentIDCamera},
+		{Input: "WiFi_Ground_Station", Expected: ComponentIDWiFiGroundStation},
+		{Input: "10", Expected: ComponentID(10)},
+		{Input: "0x10", Expected: ComponentID(0x10)},
+		{Input: "0x88", Expected: ComponentIDPairer},
+	} {
+		id, err := ParseComponentID(tc.Input)
+		require.NoError(t, err, tc.Input)
+		require.Equal(t, tc.Expected, id, tc.Input)
+	}
+
+	for _, s := range []string{"1A", "0x100", "256", "nonexistent"} {
+		_, err := ParseComponentID(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	cmdSet, err := ParseCommandSet(CommandSetWiFi.String())
+	require.NoError(t, err)
+	require.Equal(t, CommandSetWiFi, cmdSet)
+
+	cmdSet, err = ParseCommandSet(CommandSet(0x33).String())
+	require.NoError(t, err)
+	require.Equal(t, CommandSet(0x33), cmdSet)
+
+	cmdID, err := ParseCommandID(CommandIDGetVersion.String())
+	require.NoError(t, err)
+	require.Equal(t, CommandIDGetVersion, cmdID)
+
+	for _, flags := range []MessageTypeFlags{
+		0,
+		MessageTypeFlagAckRequired,
+		MessageTypeFlagResponse,
+		MessageTypeFlagResponse | MessageTypeFlagAckRequired,
+	} {
+		parsed, err := ParseMessageTypeFlags(flags.String())
+		require.NoError(t, err)
+		require.Equal(t, flags, parsed)
+	}
+
+	flags, err := ParseMessageTypeFlags("0x40")
+	require.NoError(t, err)
+	require.Equal(t, MessageTypeFlagAckRequired, flags)
+	_, err = ParseMessageTypeFlags("Request|Nonexistent")
+	require.Error(t, err)
+}
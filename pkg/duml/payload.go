@@ -0,0 +1,116 @@
+package duml
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Payload is a typed representation of a Message payload.
+//
+// MarshalDUML and UnmarshalDUML are expected to be symmetric: unmarshaling
+// the result of MarshalDUML should give an equal value.
+type Payload interface {
+	MarshalDUML() ([]byte, error)
+	UnmarshalDUML(b []byte) error
+}
+
+// PayloadFactory returns a new zero value of a Payload.
+type PayloadFactory func() Payload
+
+// ErrUnknownPayload is returned when no Payload is registered for a MessageType.
+var ErrUnknownPayload = errors.New("no payload type is registered for the message type")
+
+// payloadKey is the MessageType with only the direction bit preserved from the flags,
+// since the devices are not consistent in setting the ACK flag on responses.
+type payloadKey struct {
+	IsResponse bool
+	CmdSet     CommandSet
+	CmdID      CommandID
+}
+
+func newPayloadKey(t MessageType) payloadKey {
+	return payloadKey{
+		IsResponse: t.Flags&MessageTypeFlagResponse != 0,
+		CmdSet:     t.CmdSet,
+		CmdID:      t.CmdID,
+	}
+}
+
+var (
+	payloadRegistryLocker sync.RWMutex
+	payloadRegistry       = map[payloadKey]PayloadFactory{}
+)
+
+// RegisterPayload sets the Payload type for the given MessageType.
+//
+// Only the direction (request or response) is taken from the flags
+// of the MessageType, other flags are ignored.
+func RegisterPayload(t MessageType, factory PayloadFactory) {
+	payloadRegistryLocker.Lock()
+	defer payloadRegistryLocker.Unlock()
+	payloadRegistry[newPayloadKey(t)] = factory
+}
+
+// NewPayload returns a new zero value of the Payload registered for the given MessageType.
+func NewPayload(t MessageType) (Payload, error) {
+	payloadRegistryLocker.RLock()
+	factory := payloadRegistry[newPayloadKey(t)]
+	payloadRegistryLocker.RUnlock()
+	if factory == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPayload, t)
+	}
+	return factory(), nil
+}
+
+// DecodePayload unmarshals the payload into the Payload registered for the message type.
+func (msg *Message) DecodePayload() (Payload, error) {
+	p, err := NewPayload(msg.Type)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.UnmarshalDUML(msg.Payload); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the payload of %s into %T: %w", msg.Type, p, err)
+	}
+	return p, nil
+}
+
+// NewMessage constructs a Message with the marshaled payload.
+func NewMessage(
+	iface InterfaceID,
+	id MessageID,
+	t MessageType,
+	payload Payload,
+) (*Message, error) {
+	msg := &Message{
+		Interface: iface,
+		ID:        id,
+		Type:      t,
+	}
+	if payload != nil {
+		b, err := payload.MarshalDUML()
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal %T: %w", payload, err)
+		}
+		msg.Payload = b
+	}
+	return msg, nil
+}
+
+// RawPayload is a Payload that keeps the bytes as is.
+type RawPayload []byte
+
+var _ Payload = (*RawPayload)(nil)
+
+func (p RawPayload) MarshalDUML() ([]byte, error) {
+	return p, nil
+}
+
+func (p *RawPayload) UnmarshalDUML(b []byte) error {
+	*p = append((*p)[:0], b...)
+	return nil
+}
+
+func (p RawPayload) String() string {
+	return fmt.Sprintf("%X", []byte(p))
+}
@@ -0,0 +1,98 @@
+package duml
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		Type    MessageType
+		Payload Payload
+	}{
+		{MessageTypeConnectToWiFi, &ConnectToWiFiRequest{SSID: "ssid", PSK: "psk"}},
+		{MessageTypeConnectToWiFiResult, &Result{Code: ResultCodeSuccess, Data: []byte{0x00}}},
+		{MessageTypeCameraAPInfoResultSSID, &CameraAPInfoResult{Value: "OsmoPocket3-6094"}},
+		{MessageTypeSetPairingPIN, &SetPairingPINRequest{AppID: "001749319286102", PIN: "5160"}},
+		{MessageTypePairingStatus, &PairingStatus{State: PairingStateAlreadyPaired}},
+		{MessageTypeStartStopStreaming, NewKeyValueSetRequest(KeyValueKeyLiveStream, 0x01)},
+		{MessageTypeStartStopStreaming, NewKeyValueGetRequest(KeyValueKeyPrepareToLiveStream)},
+		{MessageTypeConfigureStreaming, &LiveStreamConfig{
+			DeviceSpecificByte: 0x2A,
+			Resolution:         Resolution1080p,
+			BitrateKbps:        6000,
+			FPS:                FPS30,
+			URL:                "rtmp://127.0.0.1/live/stream",
+		}},
+		{MessageTypeOsmoBroadcastConfig, &BroadcastConfig{Enabled: true, Platform: BroadcastPlatformRTMP, URL: "rtmp://host/app"}},
+		{MessageTypeFCCSupport, &FCCSupport{Enabled: true}},
+		{MessageTypeGogglesMode, &GogglesModeRequest{Mode: GogglesModeUSB}},
+		{MessageTypeRemoteControllerSimulatorData, &RemoteControllerSimulatorData{RightStickHorizontal: 1024, Buttons: 3}},
+		{MessageTypeBatteryStatus, &BatteryStatus{Capacity: 42, Raw: append(make([]byte, 20), 42)}},
+		{MessageTypeGetVersionResult, &VersionInfo{HardwareVersion: "WM240", LoaderVersion: NewFirmwareVersion(1, 0, 0, 7), FirmwareVersion: NewFirmwareVersion(1, 2, 3, 4), Extra: []byte{0x01}}},
+		{MessageTypeGetSerialNumResult, &StringResult{Value: "3QDSL1234567"}},
+		{MessageTypeTakeRecord, &TakeRecordRequest{Action: RecordActionStart}},
+		{MessageTypeRecordingStatus, &RecordingStatus{Recording: true, Duration: 61}},
+		{MessageTypeKeyValuePush, &KeyValuePush{Items: []KeyValueItem{{Key: KeyValueKeyCameraMode, Value: []byte{0x01}}}}},
+		{MessageTypeUnknown0MaybeStatus, &GimbalStatus{Attitude: GimbalAttitude{Yaw: -900, Pitch: 155, Roll: -3}, Mode: GimbalModeTiltLocked}},
+		{MessageTypeGimbalSetMode, &GimbalSetModeRequest{Mode: GimbalModeKeep, Recenter: true}},
+		{MessageTypeGimbalMove, &GimbalMoveRequest{Yaw: 300, Pitch: -150, Flags: GimbalMoveFlagRelative | GimbalMoveFlagIgnoreRoll, Duration: 1500 * time.Millisecond}},
+	} {
+		t.Run(tc.Type.String(), func(t *testing.T) {
+			msg, err := NewMessage(InterfaceIDAppToCamera, 1, tc.Type, tc.Payload)
+			require.NoError(t, err)
+
+			parsed, err := ParseMessage(msg.Bytes())
+			require.NoError(t, err)
+
+			decoded, err := parsed.DecodePayload()
+			require.NoError(t, err)
+			assert.Equal(t, tc.Payload, decoded)
+		})
+	}
+}
+
+func TestPayloadCompatibility(t *testing.T) {
+	t.Run("configure_streaming", func(t *testing.T) {
+		url := "rtmp://192.168.0.131:1946/test/stream0/"
+		expected := append([]byte{0x00, 0x32, 0x00, 0x0a, 0x70, 0x17, 0x02, 0x00, 0x03, 0x00, 0x00, 0x00, byte(len(url)), 0x00}, url...)
+		b, err := (&LiveStreamConfig{
+			DeviceSpecificByte: 0x32,
+			Resolution:         Resolution1080p,
+			BitrateKbps:        0x1770,
+			FPS:                FPS30,
+			URL:                url,
+		}).MarshalDUML()
+		require.NoError(t, err)
+		assert.Equal(t, expected, b)
+	})
+
+	t.Run("start_streaming", func(t *testing.T) {
+		b, err := NewKeyValueSetRequest(KeyValueKeyLiveStream, 0x01).MarshalDUML()
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0x01, 0x01, 0x1A, 0x00, 0x01, 0x01}, b)
+	})
+
+	t.Run("prepare_to_live_stream_stage2", func(t *testing.T) {
+		b, err := NewKeyValueGetRequest(KeyValueKeyPrepareToLiveStream).MarshalDUML()
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0x00, 0x01, 0x1C, 0x00}, b)
+	})
+
+	t.Run("camera_ap_info_ssid", func(t *testing.T) {
+		msg, err := ParseMessage(mustDecodeHex(t, "551f044e0702ea94c0070700104f736d6f506f636b6574332d36303934ccc8"))
+		require.NoError(t, err)
+		p, err := msg.DecodePayload()
+		require.NoError(t, err)
+		assert.Equal(t, &CameraAPInfoResult{Value: "OsmoPocket3-6094"}, p)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		msg := &Message{Type: MessageTypeHeartbeat}
+		_, err := msg.DecodePayload()
+		assert.ErrorIs(t, err, ErrUnknownPayload)
+	})
+}
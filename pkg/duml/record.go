@@ -0,0 +1,105 @@
+package duml
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RecordAction is the payload of MessageTypeTakeRecord.
+//
+// The values are experimental (see KeyValueKey.IsExperimental).
+type RecordAction uint8
+
+const (
+	RecordActionStop      = RecordAction(0x00)
+	RecordActionStart     = RecordAction(0x01)
+	RecordActionTakePhoto = RecordAction(0x02)
+)
+
+func (a RecordAction) String() string {
+	switch a {
+	case RecordActionStop:
+		return "stop"
+	case RecordActionStart:
+		return "start"
+	case RecordActionTakePhoto:
+		return "photo"
+	default:
+		return fmt.Sprintf("0x%02X", uint8(a))
+	}
+}
+
+// RecordActionFromString is the reverse of RecordAction.String (case-insensitive).
+func RecordActionFromString(s string) (RecordAction, error) {
+	for _, a := range []RecordAction{RecordActionStop, RecordActionStart, RecordActionTakePhoto} {
+		if strings.EqualFold(a.String(), s) {
+			return a, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown record action '%s' (allowed values: start, stop, photo)", s)
+}
+
+// TakeRecordRequest is the payload of MessageTypeTakeRecord.
+type TakeRecordRequest struct {
+	Action RecordAction
+}
+
+var _ Payload = (*TakeRecordRequest)(nil)
+
+func (r *TakeRecordRequest) MarshalDUML() ([]byte, error) {
+	return []byte{uint8(r.Action)}, nil
+}
+
+func (r *TakeRecordRequest) UnmarshalDUML(b []byte) error {
+	if len(b) != 1 {
+		return fmt.Errorf("expected 1 byte, got %d: %X", len(b), b)
+	}
+	r.Action = RecordAction(b[0])
+	return nil
+}
+
+const recordingStatusLength = 3
+
+// RecordingStatus is the payload of MessageTypeRecordingStatus.
+//
+// The layout is experimental:
+//
+//	[0]    - 0x01 if recording, 0x00 otherwise
+//	[1:3]  - the duration of the current recording (little-endian)
+type RecordingStatus struct {
+	Recording bool
+	Duration  Seconds
+}
+
+var _ Payload = (*RecordingStatus)(nil)
+
+func (s *RecordingStatus) MarshalDUML() ([]byte, error) {
+	b := make([]byte, recordingStatusLength)
+	if s.Recording {
+		b[0] = 0x01
+	}
+	binaryOrder.PutUint16(b[1:], uint16(s.Duration))
+	return b, nil
+}
+
+func (s *RecordingStatus) UnmarshalDUML(b []byte) error {
+	if len(b) < recordingStatusLength {
+		return fmt.Errorf("payload is too short: %d < %d", len(b), recordingStatusLength)
+	}
+	s.Recording = b[0] != 0x00
+	s.Duration = Seconds(binaryOrder.Uint16(b[1:]))
+	return nil
+}
+
+func (s RecordingStatus) String() string {
+	if !s.Recording {
+		return "recording: false"
+	}
+	return fmt.Sprintf("recording: true; duration: %s", s.Duration)
+}
+
+func init() {
+	RegisterPayload(MessageTypeTakeRecord, func() Payload { return &TakeRecordRequest{} })
+	RegisterPayload(MessageTypeTakeRecordResult, func() Payload { return &Result{} })
+	RegisterPayload(MessageTypeRecordingStatus, func() Payload { return &RecordingStatus{} })
+}
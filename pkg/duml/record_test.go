@@ -0,0 +1,39 @@
+package duml
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordActionFromString(t *testing.T) {
+	for _, a := range []RecordAction{RecordActionStop, RecordActionStart, RecordActionTakePhoto} {
+		parsed, err := RecordActionFromString(a.String())
+		require.NoError(t, err)
+		require.Equal(t, a, parsed)
+	}
+	_, err := RecordActionFromString("pause")
+	require.Error(t, err)
+}
+
+func TestRecordingStatus(t *testing.T) {
+	var status RecordingStatus
+	require.NoError(t, status.UnmarshalDUML([]byte{0x01, 0x3D, 0x00}))
+	require.Equal(t, RecordingStatus{Recording: true, Duration: 61}, status)
+	require.Equal(t, "recording: true; duration: 1m1s", status.String())
+
+	require.Error(t, status.UnmarshalDUML([]byte{0x01}))
+}
+
+func TestTakeRecordGoldenFrame(t *testing.T) {
+	for action, expected := range map[RecordAction]string{
+		RecordActionStop:      "550e04660201123440020200a6e6",
+		RecordActionStart:     "550e046602011234400202012ff7",
+		RecordActionTakePhoto: "550e04660201123440020202b4c5",
+	} {
+		msg, err := NewMessage(InterfaceIDAppToCamera, 0x1234, MessageTypeTakeRecord, &TakeRecordRequest{Action: action})
+		require.NoError(t, err)
+		require.Equal(t, expected, hex.EncodeToString(msg.Bytes()), "%s", action)
+	}
+}
@@ -0,0 +1,248 @@
+package duml
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRequestTimeout is the default time to wait for a response to a single transmission of a request.
+	DefaultRequestTimeout = 10 * time.Second
+
+	// DefaultRequestRetries is the default amount of retransmissions of a request if there was no response;
+	// a request is not retransmitted by default, since not every request is safe to repeat (e.g. taking a photo).
+	DefaultRequestRetries = 0
+
+	// DefaultQueryRetries is the amount of retransmissions of the queries which are safe to repeat
+	// (e.g. MessageTypeGetVersion), see RequestRetries.
+	DefaultQueryRetries = 1
+)
+
+// RequestConfig is the configuration of a single request.
+type RequestConfig struct {
+	// Timeout is the time to wait for a response to each transmission (zero means no timeout).
+	Timeout time.Duration
+
+	// Retries is the amount of retransmissions (with the same MessageID) on timeouts.
+	Retries uint
+}
+
+// DefaultRequestConfig returns the RequestConfig used if no options are given.
+func DefaultRequestConfig() RequestConfig {
+	return RequestConfig{
+		Timeout: DefaultRequestTimeout,
+		Retries: DefaultRequestRetries,
+	}
+}
+
+// RequestOption changes the RequestConfig of a request.
+type RequestOption func(*RequestConfig)
+
+// RequestTimeout sets the time to wait for a response to each transmission.
+func RequestTimeout(timeout time.Duration) RequestOption {
+	return func(cfg *RequestConfig) {
+		cfg.Timeout = timeout
+	}
+}
+
+// RequestRetries sets the amount of retransmissions on timeouts.
+func RequestRetries(retries uint) RequestOption {
+	return func(cfg *RequestConfig) {
+		cfg.Retries = retries
+	}
+}
+
+// NewRequestConfig applies the options to DefaultRequestConfig.
+func NewRequestConfig(opts ...RequestOption) RequestConfig {
+	cfg := DefaultRequestConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+type requestResult struct {
+	Response *Message
+	Err      error
+}
+
+// PendingRequest is a request waiting for its response in a RequestTable.
+type PendingRequest struct {
+	Key ResponseKey
+
+	table  *RequestTable
+	result chan requestResult
+}
+
+// Wait waits for the response (or for a failure of the connection).
+func (r *PendingRequest) Wait(ctx context.Context) (*Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-r.result:
+		return result.Response, result.Err
+	}
+}
+
+// WaitTimeout is the same as Wait, but returns ErrTimeout if there was
+// no response within the timeout (zero means no timeout).
+func (r *PendingRequest) WaitTimeout(ctx context.Context, timeout time.Duration) (*Message, error) {
+	if timeout <= 0 {
+		return r.Wait(ctx)
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		return nil, ErrTimeout
+	case result := <-r.result:
+		return result.Response, result.Err
+	}
+}
+
+// Close removes the request from the table; it is a no-op if the
+// request was already resolved.
+func (r *PendingRequest) Close() {
+	r.table.remove(r)
+}
+
+// RequestTable is the table of in-flight requests of a connection.
+//
+// Multiple requests could wait for the same ResponseKey (e.g. if the caller
+// pins the same MessageID), they are resolved in the FIFO order.
+// It is safe for concurrent use.
+type RequestTable struct {
+	locker  sync.Mutex
+	pending map[ResponseKey][]*PendingRequest
+	err     error
+}
+
+func NewRequestTable() *RequestTable {
+	return &RequestTable{
+		pending: map[ResponseKey][]*PendingRequest{},
+	}
+}
+
+// Add registers a request waiting for a response with the given key.
+//
+// It fails if the table was closed.
+func (t *RequestTable) Add(key ResponseKey) (*PendingRequest, error) {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	if t.err != nil {
+		return nil, t.err
+	}
+	r := &PendingRequest{
+		Key:    key,
+		table:  t,
+		result: make(chan requestResult, 1),
+	}
+	t.pending[key] = append(t.pending[key], r)
+	return r, nil
+}
+
+// Do registers a request with the given key, transmits it using the send function
+// and waits for the response; if there is no response within cfg.Timeout, the
+// request is transmitted again (up to cfg.Retries times).
+func (t *RequestTable) Do(
+	ctx context.Context,
+	key ResponseKey,
+	cfg RequestConfig,
+	send func(ctx context.Context) error,
+) (*Message, error) {
+	r, err := t.Add(key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	for attempt := uint(0); attempt <= cfg.Retries; attempt++ {
+		if err := send(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := r.WaitTimeout(ctx, cfg.Timeout)
+		if err == ErrTimeout {
+			continue
+		}
+		return resp, err
+	}
+	return nil, fmt.Errorf("%w: %d transmission(s), %v each", ErrTimeout, cfg.Retries+1, cfg.Timeout)
+}
+
+// Resolve delivers the response to the oldest request waiting for the key.
+//
+// Returns false if nobody waits for the response.
+func (t *RequestTable) Resolve(key ResponseKey, resp *Message) bool {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	queue := t.pending[key]
+	if len(queue) == 0 {
+		return false
+	}
+	r := queue[0]
+	if len(queue) == 1 {
+		delete(t.pending, key)
+	} else {
+		t.pending[key] = queue[1:]
+	}
+	r.result <- requestResult{Response: resp}
+	return true
+}
+
+// Len returns the amount of pending requests.
+func (t *RequestTable) Len() int {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	result := 0
+	for _, queue := range t.pending {
+		result += len(queue)
+	}
+	return result
+}
+
+// FailAll fails all the pending requests with the given error.
+func (t *RequestTable) FailAll(err error) {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	t.failAll(err)
+}
+
+// Close fails all the pending requests with the given error (e.g. ErrDisconnected),
+// and makes any further Add fail with it as well.
+func (t *RequestTable) Close(err error) {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	t.err = err
+	t.failAll(err)
+}
+
+func (t *RequestTable) failAll(err error) {
+	for key, queue := range t.pending {
+		for _, r := range queue {
+			r.result <- requestResult{Err: err}
+		}
+		delete(t.pending, key)
+	}
+}
+
+func (t *RequestTable) remove(r *PendingRequest) {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	queue := t.pending[r.Key]
+	for idx, item := range queue {
+		if item != r {
+			continue
+		}
+		queue = append(queue[:idx:idx], queue[idx+1:]...)
+		if len(queue) == 0 {
+			delete(t.pending, r.Key)
+		} else {
+			t.pending[r.Key] = queue
+		}
+		return
+	}
+}
@@ -0,0 +1,103 @@
+package duml
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestTableResolve(t *testing.T) {
+	table := NewRequestTable()
+	key := ResponseKey{ID: 1, CmdSet: CommandSetCamera, CmdID: 1, Peer: ComponentIDCamera}
+
+	first, err := table.Add(key)
+	require.NoError(t, err)
+	second, err := table.Add(key)
+	require.NoError(t, err)
+	require.Equal(t, 2, table.Len())
+
+	require.False(t, table.Resolve(ResponseKey{ID: 2}, &Message{}))
+	require.True(t, table.Resolve(key, &Message{Payload: []byte{1}}))
+	require.True(t, table.Resolve(key, &Message{Payload: []byte{2}}))
+	require.False(t, table.Resolve(key, &Message{Payload: []byte{3}}))
+	require.Equal(t, 0, table.Len())
+
+	ctx := context.Background()
+	resp, err := first.Wait(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1}, resp.Payload)
+	resp, err = second.Wait(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []byte{2}, resp.Payload)
+}
+
+func TestRequestTableDoRetries(t *testing.T) {
+	table := NewRequestTable()
+	key := ResponseKey{ID: 1}
+	ctx := context.Background()
+
+	var sent atomic.Int32
+	_, err := table.Do(ctx, key, RequestConfig{Timeout: 10 * time.Millisecond, Retries: 2}, func(context.Context) error {
+		sent.Add(1)
+		return nil
+	})
+	require.ErrorIs(t, err, ErrTimeout)
+	require.Equal(t, int32(3), sent.Load())
+	require.Equal(t, 0, table.Len())
+
+	sent.Store(0)
+	resp, err := table.Do(ctx, key, RequestConfig{Timeout: 50 * time.Millisecond, Retries: 2}, func(context.Context) error {
+		if sent.Add(1) == 2 {
+			go table.Resolve(key, &Message{Payload: []byte{42}})
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []byte{42}, resp.Payload)
+	require.Equal(t, int32(2), sent.Load())
+
+	sent.Store(0)
+	_, err = table.Do(ctx, key, NewRequestConfig(RequestTimeout(10*time.Millisecond)), func(context.Context) error {
+		sent.Add(1)
+		return nil
+	})
+	require.ErrorIs(t, err, ErrTimeout)
+	require.Equal(t, int32(1), sent.Load(), "a request must not be retransmitted by default")
+
+	sendErr := errors.New("send failed")
+	_, err = table.Do(ctx, key, DefaultRequestConfig(), func(context.Context) error {
+		return sendErr
+	})
+	require.ErrorIs(t, err, sendErr)
+	require.Equal(t, 0, table.Len())
+}
+
+func TestRequestTableClose(t *testing.T) {
+	table := NewRequestTable()
+	ctx := context.Background()
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := table.Do(ctx, ResponseKey{ID: 1}, DefaultRequestConfig(), func(context.Context) error { return nil })
+		errCh <- err
+	}()
+	require.Eventually(t, func() bool { return table.Len() == 1 }, time.Second, time.Millisecond)
+
+	table.Close(ErrDisconnected)
+	require.ErrorIs(t, <-errCh, ErrDisconnected)
+
+	_, err := table.Add(ResponseKey{ID: 2})
+	require.ErrorIs(t, err, ErrDisconnected)
+}
+
+func TestRejectedError(t *testing.T) {
+	err := error(NewRejectedError(&Message{Payload: []byte{0x05, 0x01}}, "unable to do %s", "things"))
+	var rejected *RejectedError
+	require.True(t, errors.As(err, &rejected))
+	require.Equal(t, ResultCode(0x05), rejected.Code)
+	require.Contains(t, err.Error(), "unable to do things")
+}
@@ -36,3 +36,25 @@ func ResolutionFromString(s string) Resolution {
 		return UndefinedResolution
 	}
 }
+
+func ResolutionFromBytesFixed(b [1]byte) Resolution {
+	for r := Resolution480p; r <= Resolution1080p; r++ {
+		if r.BytesFixed() == b {
+			return r
+		}
+	}
+	return UndefinedResolution
+}
+
+func (r Resolution) String() string {
+	switch r {
+	case Resolution480p:
+		return "480p"
+	case Resolution720p:
+		return "720p"
+	case Resolution1080p:
+		return "1080p"
+	default:
+		return "<undefined>"
+	}
+}
@@ -0,0 +1,38 @@
+package duml
+
+// ResponseKey identifies a request/response pair within a connection.
+//
+// Besides the MessageID it includes the command and the peer component,
+// so that responses to concurrent requests that happen to use the same
+// MessageID are not confused.
+type ResponseKey struct {
+	ID     MessageID
+	CmdSet CommandSet
+	CmdID  CommandID
+	Peer   ComponentID
+}
+
+// NewResponseKey returns the ResponseKey of a request or a response, where
+// local is the component on our side of the connection (usually ComponentIDApp).
+//
+// The key of a request equals the key of its response.
+func NewResponseKey(msg *Message, local ComponentID) ResponseKey {
+	return ResponseKey{
+		ID:     msg.ID,
+		CmdSet: msg.Type.CmdSet,
+		CmdID:  msg.Type.CmdID,
+		Peer:   msg.Interface.Peer(local),
+	}
+}
+
+// Peer returns the component on the other side of the interface relatively
+// to the given local component.
+//
+// Some devices do not swap the sender and the receiver in responses, so this
+// does not rely on the direction.
+func (iface InterfaceID) Peer(local ComponentID) ComponentID {
+	if iface.Sender == local {
+		return iface.Receiver
+	}
+	return iface.Sender
+}
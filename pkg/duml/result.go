@@ -0,0 +1,58 @@
+package duml
+
+import "fmt"
+
+// ResultCode is the first byte of the most of responses; zero means success.
+type ResultCode uint8
+
+const (
+	ResultCodeSuccess = ResultCode(0x00)
+)
+
+func (c ResultCode) String() string {
+	if c == ResultCodeSuccess {
+		return "success"
+	}
+	return fmt.Sprintf("error:0x%02X", uint8(c))
+}
+
+// Result is a generic response payload: a ResultCode followed by
+// (usually not yet understood) data.
+type Result struct {
+	Code ResultCode
+	Data []byte
+}
+
+var _ Payload = (*Result)(nil)
+
+func (r *Result) MarshalDUML() ([]byte, error) {
+	return append([]byte{uint8(r.Code)}, r.Data...), nil
+}
+
+func (r *Result) UnmarshalDUML(b []byte) error {
+	if len(b) < 1 {
+		return fmt.Errorf("the payload is empty")
+	}
+	r.Code = ResultCode(b[0])
+	r.Data = nil
+	if len(b) > 1 {
+		r.Data = append([]byte{}, b[1:]...)
+	}
+	return nil
+}
+
+func (r *Result) IsSuccess() bool {
+	return r.Code == ResultCodeSuccess
+}
+
+func init() {
+	for _, t := range []MessageType{
+		MessageTypePrepareToLiveStreamResult,
+		MessageTypeStartStopStreamingResult,
+		MessageTypeConfigureStreamingResult,
+		MessageTypeConnectToWiFiResult,
+		MessageTypeStartScanningWiFiResult,
+	} {
+		RegisterPayload(t, func() Payload { return &Result{} })
+	}
+}
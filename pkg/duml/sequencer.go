@@ -0,0 +1,39 @@
+package duml
+
+import (
+	"sync/atomic"
+)
+
+// Sequencer allocates MessageIDs for outgoing messages of a single connection.
+//
+// The IDs are monotonically increasing (wrapping around 0xFFFF). It is safe
+// for concurrent use.
+type Sequencer struct {
+	next atomic.Uint32
+}
+
+// NewSequencer returns a Sequencer that starts with the given MessageID.
+func NewSequencer(first MessageID) *Sequencer {
+	s := &Sequencer{}
+	s.next.Store(uint32(first))
+	return s
+}
+
+// Next allocates a new MessageID.
+func (s *Sequencer) Next() MessageID {
+	return MessageID(s.next.Add(1) - 1)
+}
+
+// Assign sets the ID of the message to the next allocated MessageID if
+// Message.AutoID is set (and resets the flag, so a retransmission keeps the
+// ID); otherwise the ID is kept as is (pinned by the caller, e.g. to mimic
+// traffic of the official app), including 0.
+//
+// Returns the resulting ID of the message.
+func (s *Sequencer) Assign(msg *Message) MessageID {
+	if msg.AutoID {
+		msg.ID = s.Next()
+		msg.AutoID = false
+	}
+	return msg.ID
+}
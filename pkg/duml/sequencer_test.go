@@ -0,0 +1,76 @@
+package duml
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequencer(t *testing.T) {
+	s := NewSequencer(0xFFFE)
+	require.Equal(t, MessageID(0xFFFE), s.Next())
+	require.Equal(t, MessageID(0xFFFF), s.Next())
+	require.Equal(t, MessageID(0x0000), s.Next())
+
+	msg := &Message{AutoID: true}
+	require.Equal(t, MessageID(0x0001), s.Assign(msg))
+	require.Equal(t, MessageID(0x0001), msg.ID)
+	require.False(t, msg.AutoID)
+	require.Equal(t, MessageID(0x0001), s.Assign(msg), "a retransmission must keep the ID")
+
+	pinned := &Message{ID: MessageIDConnectToWifi}
+	require.Equal(t, MessageIDConnectToWifi, s.Assign(pinned))
+	pinnedZero := &Message{ID: 0}
+	require.Equal(t, MessageID(0), s.Assign(pinnedZero), "0 must stay a valid pinned ID")
+	require.Equal(t, MessageID(0x0002), s.Next(), "pinning must not consume IDs")
+}
+
+func TestSequencerConcurrent(t *testing.T) {
+	s := NewSequencer(1)
+	const count = 1000
+	ids := make(chan MessageID, count)
+	var wg sync.WaitGroup
+	for range count {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids <- s.Next()
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := map[MessageID]struct{}{}
+	for id := range ids {
+		seen[id] = struct{}{}
+	}
+	require.Len(t, seen, count)
+}
+
+func TestResponseKey(t *testing.T) {
+	req := &Message{
+		Interface: InterfaceIDAppToWiFiGroundStation,
+		ID:        MessageIDConnectToWifi,
+		Type:      MessageTypeConnectToWiFi,
+	}
+	resp := &Message{
+		Interface: InterfaceID{Sender: ComponentIDWiFiGroundStation, Receiver: ComponentIDApp},
+		ID:        MessageIDConnectToWifi,
+		Type:      MessageTypeConnectToWiFiResult,
+	}
+	require.Equal(t, NewResponseKey(req, ComponentIDApp), NewResponseKey(resp, ComponentIDApp))
+
+	// some devices reply without swapping the interface
+	unswapped := *resp
+	unswapped.Interface = req.Interface
+	require.Equal(t, NewResponseKey(req, ComponentIDApp), NewResponseKey(&unswapped, ComponentIDApp))
+
+	otherCommand := *resp
+	otherCommand.Type = MessageTypeStartScanningWiFiResult
+	require.NotEqual(t, NewResponseKey(req, ComponentIDApp), NewResponseKey(&otherCommand, ComponentIDApp))
+
+	otherPeer := *resp
+	otherPeer.Interface = InterfaceID{Sender: ComponentIDCamera, Receiver: ComponentIDApp}
+	require.NotEqual(t, NewResponseKey(req, ComponentIDApp), NewResponseKey(&otherPeer, ComponentIDApp))
+}
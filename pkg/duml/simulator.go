@@ -1,6 +1,9 @@
 package duml
 
-import "encoding/binary"
+import (
+	"encoding/binary"
+	"fmt"
+)
 
 const (
 	RemoteControllerSimulatorStickMin    = uint16(364)
@@ -34,11 +37,33 @@ func (d *RemoteControllerSimulatorData) Bytes() []byte {
 	return b
 }
 
+var _ Payload = (*RemoteControllerSimulatorData)(nil)
+
+func (d *RemoteControllerSimulatorData) MarshalDUML() ([]byte, error) {
+	return d.Bytes(), nil
+}
+
+func (d *RemoteControllerSimulatorData) UnmarshalDUML(b []byte) error {
+	if len(b) != RemoteControllerSimulatorDataSize {
+		return fmt.Errorf("expected exactly %d bytes, but received %d", RemoteControllerSimulatorDataSize, len(b))
+	}
+	d.RightStickHorizontal = binary.LittleEndian.Uint16(b[0:2])
+	d.RightStickVertical = binary.LittleEndian.Uint16(b[2:4])
+	d.LeftStickVertical = binary.LittleEndian.Uint16(b[4:6])
+	d.LeftStickHorizontal = binary.LittleEndian.Uint16(b[6:8])
+	d.Buttons = binary.LittleEndian.Uint32(b[8:12])
+	return nil
+}
+
 func NewRemoteControllerSimulatorMessage(data RemoteControllerSimulatorData) *Message {
 	return &Message{
 		Interface: InterfaceIDAppToRemoteController,
-		ID:        MessageID(0), // Initial sequence number
+		AutoID:    true,
 		Type:      MessageTypeRemoteControllerSimulatorData,
 		Payload:   data.Bytes(),
 	}
 }
+
+func init() {
+	RegisterPayload(MessageTypeRemoteControllerSimulatorData, func() Payload { return &RemoteControllerSimulatorData{} })
+}
@@ -0,0 +1,90 @@
+package duml
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"sync"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+)
+
+// StreamConn is a Conn over a byte stream (a serial port, a TCP connection, ...):
+// the received bytes are split into frames by a Decoder and handled by the
+// embedded Endpoint.
+type StreamConn struct {
+	// Type is the model of the device, if known.
+	Type DeviceType
+
+	*Endpoint
+
+	stream      io.ReadWriteCloser
+	writeLocker sync.Mutex
+	cancel      context.CancelFunc
+}
+
+var _ Conn = (*StreamConn)(nil)
+
+// NewStreamConn returns a Conn talking DUML over the given byte stream.
+// The stream is closed on Close.
+func NewStreamConn(
+	ctx context.Context,
+	stream io.ReadWriteCloser,
+) *StreamConn {
+	ctx, cancel := context.WithCancel(ctx)
+	c := &StreamConn{
+		stream: stream,
+		cancel: cancel,
+	}
+	c.Endpoint = NewEndpoint(MessageID(rand.Uint32()), c.writeMessage)
+	go c.receiveLoop(ctx)
+	return c
+}
+
+// DeviceType implements Conn.
+func (c *StreamConn) DeviceType() DeviceType {
+	return c.Type
+}
+
+// Close closes the stream and fails all the pending requests with ErrDisconnected.
+func (c *StreamConn) Close() error {
+	c.cancel()
+	err := c.stream.Close()
+	c.Endpoint.Close(ErrDisconnected)
+	return err
+}
+
+func (c *StreamConn) writeMessage(
+	ctx context.Context,
+	msg *Message,
+) error {
+	logger.Debugf(ctx, "sending duml.Message: %#+v", msg)
+	b := msg.Bytes()
+	c.writeLocker.Lock()
+	defer c.writeLocker.Unlock()
+	if _, err := c.stream.Write(b); err != nil {
+		return fmt.Errorf("unable to write %d bytes: %w", len(b), err)
+	}
+	return nil
+}
+
+func (c *StreamConn) receiveLoop(ctx context.Context) {
+	logger.Tracef(ctx, "receiveLoop")
+	defer func() { logger.Tracef(ctx, "/receiveLoop") }()
+
+	decoder := NewDecoder()
+	err := decoder.ReadMessages(c.stream, func(msg *Message) error {
+		c.HandleMessage(ctx, msg)
+		return nil
+	})
+	if ctx.Err() == nil && !errors.Is(err, io.EOF) && !errors.Is(err, os.ErrClosed) {
+		logger.Errorf(ctx, "unable to read: %v", err)
+	}
+	if dropped := decoder.DroppedBytes(); dropped > 0 {
+		logger.Debugf(ctx, "skipped %d bytes that are not a valid DUML frame", dropped)
+	}
+	c.Endpoint.Close(ErrDisconnected)
+}
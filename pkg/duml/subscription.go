@@ -0,0 +1,250 @@
+package duml
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrSubscriptionClosed is returned when receiving from a closed Subscription.
+var ErrSubscriptionClosed = errors.New("the subscription is closed")
+
+// DefaultSubscriptionBufferSize is the default capacity of a Subscription.
+const DefaultSubscriptionBufferSize = 64
+
+// OverflowPolicy defines what happens to a message that does not fit into
+// the buffer of a Subscription. The dropped messages are counted, see
+// Subscription.Dropped.
+type OverflowPolicy int
+
+const (
+	// OverflowPolicyDropNewest drops the incoming message.
+	OverflowPolicyDropNewest = OverflowPolicy(iota)
+
+	// OverflowPolicyDropOldest drops the oldest buffered message to make room for the incoming one.
+	OverflowPolicyDropOldest
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowPolicyDropNewest:
+		return "drop_newest"
+	case OverflowPolicyDropOldest:
+		return "drop_oldest"
+	default:
+		return "unknown_overflow_policy"
+	}
+}
+
+// SubscribeConfig is the configuration of a Subscription.
+type SubscribeConfig struct {
+	BufferSize     int
+	OverflowPolicy OverflowPolicy
+}
+
+// SubscribeOption changes the SubscribeConfig of a Subscription.
+type SubscribeOption func(*SubscribeConfig)
+
+// SubscribeBufferSize sets the capacity of the Subscription.
+func SubscribeBufferSize(size int) SubscribeOption {
+	return func(cfg *SubscribeConfig) {
+		cfg.BufferSize = size
+	}
+}
+
+// SubscribeOverflowPolicy sets what to do with messages that do not fit into the buffer.
+func SubscribeOverflowPolicy(policy OverflowPolicy) SubscribeOption {
+	return func(cfg *SubscribeConfig) {
+		cfg.OverflowPolicy = policy
+	}
+}
+
+// Subscription is an independent buffered stream of messages matching a filter.
+type Subscription struct {
+	Filter MessageFilter
+	Config SubscribeConfig
+
+	hub     *Subscriptions
+	ch      chan *Message
+	dropped atomic.Uint64
+	err     error
+
+	// stopCtxWatch stops closing the Subscription on the cancellation of the
+	// context it was subscribed with (see Endpoint.Subscribe).
+	stopCtxWatch func() bool
+}
+
+// C returns the channel of the messages; it is closed when the Subscription is closed.
+func (s *Subscription) C() <-chan *Message {
+	return s.ch
+}
+
+// Receive waits for the next message.
+//
+// If the Subscription is closed, then it returns the reason (ErrSubscriptionClosed,
+// or e.g. ErrDisconnected).
+func (s *Subscription) Receive(ctx context.Context) (*Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case msg, ok := <-s.ch:
+		if !ok {
+			return nil, s.Err()
+		}
+		return msg, nil
+	}
+}
+
+// Dropped returns the amount of messages dropped due to the buffer overflow.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Err returns the reason why the Subscription was closed (or nil if it is not closed).
+func (s *Subscription) Err() error {
+	s.hub.locker.RLock()
+	defer s.hub.locker.RUnlock()
+	return s.err
+}
+
+// Close unsubscribes. The messages that are already buffered are still
+// readable from C.
+func (s *Subscription) Close() {
+	s.hub.locker.Lock()
+	defer s.hub.locker.Unlock()
+	s.hub.closeLocked(s, ErrSubscriptionClosed)
+}
+
+// closeWithContext makes the Subscription closed when the context is cancelled;
+// the context is released when the Subscription is closed.
+func (s *Subscription) closeWithContext(ctx context.Context) {
+	stop := context.AfterFunc(ctx, s.Close)
+	s.hub.locker.Lock()
+	defer s.hub.locker.Unlock()
+	if _, ok := s.hub.subs[s]; !ok {
+		stop()
+		return
+	}
+	s.stopCtxWatch = stop
+}
+
+func (s *Subscription) deliver(msg *Message) {
+	for {
+		select {
+		case s.ch <- msg:
+			return
+		default:
+		}
+		switch s.Config.OverflowPolicy {
+		case OverflowPolicyDropOldest:
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		default:
+			s.dropped.Add(1)
+			return
+		}
+	}
+}
+
+// Subscriptions fans out messages to Subscriptions; it is safe for concurrent use.
+type Subscriptions struct {
+	locker sync.RWMutex
+	subs   map[*Subscription]struct{}
+	err    error
+}
+
+func NewSubscriptions() *Subscriptions {
+	return &Subscriptions{
+		subs: map[*Subscription]struct{}{},
+	}
+}
+
+// Subscribe returns a new Subscription to the messages passing the filter
+// (a nil filter passes all the messages).
+//
+// After CloseAll the returned Subscription is already closed with the reason
+// given to CloseAll.
+func (h *Subscriptions) Subscribe(
+	filter MessageFilter,
+	opts ...SubscribeOption,
+) *Subscription {
+	cfg := SubscribeConfig{
+		BufferSize:     DefaultSubscriptionBufferSize,
+		OverflowPolicy: OverflowPolicyDropNewest,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.BufferSize < 1 {
+		cfg.BufferSize = 1
+	}
+	s := &Subscription{
+		Filter: filter,
+		Config: cfg,
+		hub:    h,
+		ch:     make(chan *Message, cfg.BufferSize),
+	}
+	h.locker.Lock()
+	defer h.locker.Unlock()
+	if h.err != nil {
+		s.err = h.err
+		close(s.ch)
+		return s
+	}
+	h.subs[s] = struct{}{}
+	return s
+}
+
+// Publish delivers the message to all the matching Subscriptions, without blocking.
+//
+// Returns the amount of matching Subscriptions.
+func (h *Subscriptions) Publish(msg *Message) int {
+	h.locker.RLock()
+	defer h.locker.RUnlock()
+	count := 0
+	for s := range h.subs {
+		if !s.Filter.Match(msg) {
+			continue
+		}
+		s.deliver(msg)
+		count++
+	}
+	return count
+}
+
+// Len returns the amount of active Subscriptions.
+func (h *Subscriptions) Len() int {
+	h.locker.RLock()
+	defer h.locker.RUnlock()
+	return len(h.subs)
+}
+
+// CloseAll closes all the Subscriptions with the given reason; the
+// Subscriptions made after that are closed right away.
+func (h *Subscriptions) CloseAll(reason error) {
+	if reason == nil {
+		reason = ErrSubscriptionClosed
+	}
+	h.locker.Lock()
+	defer h.locker.Unlock()
+	h.err = reason
+	for s := range h.subs {
+		h.closeLocked(s, reason)
+	}
+}
+
+func (h *Subscriptions) closeLocked(s *Subscription, reason error) {
+	if _, ok := h.subs[s]; !ok {
+		return
+	}
+	delete(h.subs, s)
+	s.err = reason
+	close(s.ch)
+	if s.stopCtxWatch != nil {
+		s.stopCtxWatch()
+	}
+}
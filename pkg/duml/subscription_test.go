@@ -0,0 +1,72 @@
+package duml
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionsFanOut(t *testing.T) {
+	hub := NewSubscriptions()
+	battery := hub.Subscribe(FilterType(MessageTypeBatteryStatus))
+	all := hub.Subscribe(nil)
+	wifi := hub.Subscribe(FilterAnd(
+		FilterCommandSet(CommandSetWiFi),
+		FilterSender(ComponentIDWiFiGroundStation),
+	))
+
+	statusMsg := &Message{Interface: InterfaceID{Sender: ComponentIDBattery, Receiver: ComponentIDApp}, Type: MessageTypeBatteryStatus}
+	wifiMsg := &Message{Interface: InterfaceID{Sender: ComponentIDWiFiGroundStation, Receiver: ComponentIDApp}, Type: MessageTypeConnectToWiFiResult}
+	require.Equal(t, 2, hub.Publish(statusMsg))
+	require.Equal(t, 2, hub.Publish(wifiMsg))
+
+	ctx := context.Background()
+	msg, err := battery.Receive(ctx)
+	require.NoError(t, err)
+	require.Equal(t, statusMsg, msg)
+	msg, err = wifi.Receive(ctx)
+	require.NoError(t, err)
+	require.Equal(t, wifiMsg, msg)
+	require.Len(t, all.C(), 2)
+
+	battery.Close()
+	battery.Close()
+	require.Equal(t, 2, hub.Len())
+	_, err = battery.Receive(ctx)
+	require.ErrorIs(t, err, ErrSubscriptionClosed)
+
+	hub.CloseAll(ErrDisconnected)
+	require.Equal(t, 0, hub.Len())
+	require.Len(t, all.C(), 2, "buffered messages must be still readable")
+	<-all.C()
+	<-all.C()
+	_, err = all.Receive(ctx)
+	require.ErrorIs(t, err, ErrDisconnected)
+}
+
+func TestSubscriptionOverflow(t *testing.T) {
+	hub := NewSubscriptions()
+	dropNewest := hub.Subscribe(nil, SubscribeBufferSize(2))
+	dropOldest := hub.Subscribe(nil, SubscribeBufferSize(2), SubscribeOverflowPolicy(OverflowPolicyDropOldest))
+
+	for id := MessageID(1); id <= 5; id++ {
+		hub.Publish(&Message{ID: id})
+	}
+
+	require.Equal(t, uint64(3), dropNewest.Dropped())
+	require.Equal(t, uint64(3), dropOldest.Dropped())
+	require.Equal(t, MessageID(1), (<-dropNewest.C()).ID)
+	require.Equal(t, MessageID(2), (<-dropNewest.C()).ID)
+	require.Equal(t, MessageID(4), (<-dropOldest.C()).ID)
+	require.Equal(t, MessageID(5), (<-dropOldest.C()).ID)
+}
+
+func TestFilterType(t *testing.T) {
+	f := FilterType(MessageTypeConnectToWiFiResult)
+	require.True(t, f.Match(&Message{Type: MessageTypeConnectToWiFiResult}))
+	require.True(t, f.Match(&Message{Type: MessageTypeResponse(CommandSetWiFi, CommandIDConnectToWiFi)}), "the ACK flag must be ignored")
+	require.False(t, f.Match(&Message{Type: MessageTypeConnectToWiFi}), "the direction must not be ignored")
+	require.False(t, FilterNot(f).Match(&Message{Type: MessageTypeConnectToWiFiResult}))
+	require.True(t, MessageFilter(nil).Match(&Message{}))
+}
@@ -0,0 +1,231 @@
+package duml
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// VideoResolution is the resolution of the local recording (unlike Resolution,
+// which is the resolution of the live stream).
+type VideoResolution int
+
+const (
+	VideoResolutionUndefined = VideoResolution(iota)
+	VideoResolution1080p
+	VideoResolution2_7K
+	VideoResolution4K
+	endOfVideoResolution
+)
+
+func (r VideoResolution) String() string {
+	switch r {
+	case VideoResolution1080p:
+		return "1080p"
+	case VideoResolution2_7K:
+		return "2.7k"
+	case VideoResolution4K:
+		return "4k"
+	default:
+		return "<undefined>"
+	}
+}
+
+// VideoResolutionFromString is the reverse of VideoResolution.String (case-insensitive);
+// the dimensions (e.g. "3840x2160") are also accepted.
+func VideoResolutionFromString(s string) VideoResolution {
+	switch strings.ToLower(s) {
+	case "1080p", "1920x1080":
+		return VideoResolution1080p
+	case "2.7k", "2688x1512":
+		return VideoResolution2_7K
+	case "4k", "3840x2160":
+		return VideoResolution4K
+	default:
+		return VideoResolutionUndefined
+	}
+}
+
+// VideoFPS is the frame rate of the local recording, in frames per second.
+type VideoFPS uint
+
+// The frame rates known to this package.
+var videoFPSes = []VideoFPS{24, 25, 30, 48, 50, 60, 100, 120}
+
+func (f VideoFPS) String() string {
+	if !slices.Contains(videoFPSes, f) {
+		return "<undefined>"
+	}
+	return strconv.FormatUint(uint64(f), 10)
+}
+
+// VideoFPSFromString is the reverse of VideoFPS.String; it returns zero if the frame rate is not known.
+func VideoFPSFromString(s string) VideoFPS {
+	v, err := strconv.ParseUint(strings.TrimSuffix(strings.ToLower(s), "fps"), 10, 16)
+	if err != nil || !slices.Contains(videoFPSes, VideoFPS(v)) {
+		return 0
+	}
+	return VideoFPS(v)
+}
+
+// AspectRatio is the aspect ratio of the local recording.
+type AspectRatio int
+
+const (
+	AspectRatioUndefined = AspectRatio(iota)
+	AspectRatio16x9
+	AspectRatio4x3
+	endOfAspectRatio
+)
+
+func (r AspectRatio) String() string {
+	switch r {
+	case AspectRatio16x9:
+		return "16:9"
+	case AspectRatio4x3:
+		return "4:3"
+	default:
+		return "<undefined>"
+	}
+}
+
+// AspectRatioFromString is the reverse of AspectRatio.String.
+func AspectRatioFromString(s string) AspectRatio {
+	for r := AspectRatioUndefined + 1; r < endOfAspectRatio; r++ {
+		if r.String() == s {
+			return r
+		}
+	}
+	return AspectRatioUndefined
+}
+
+// Orientation is the orientation of the local recording.
+type Orientation int
+
+const (
+	OrientationUndefined = Orientation(iota)
+	OrientationLandscape
+	OrientationPortrait
+	endOfOrientation
+)
+
+func (o Orientation) String() string {
+	switch o {
+	case OrientationLandscape:
+		return "landscape"
+	case OrientationPortrait:
+		return "portrait"
+	default:
+		return "<undefined>"
+	}
+}
+
+// OrientationFromString is the reverse of Orientation.String (case-insensitive).
+func OrientationFromString(s string) Orientation {
+	s = strings.ToLower(s)
+	for o := OrientationUndefined + 1; o < endOfOrientation; o++ {
+		if o.String() == s {
+			return o
+		}
+	}
+	return OrientationUndefined
+}
+
+// VideoFormat is the format of the local recording, the value of KeyValueKeyVideoFormat.
+//
+// The layout of the value is experimental (see KeyValueKey.IsExperimental):
+//
+//	[0]  - the VideoResolution
+//	[1]  - the VideoFPS
+//	[2]  - the AspectRatio
+//	[3]  - the Orientation
+//
+// each is encoded by the profile of the model (see DeviceType.EncodeVideoFormat).
+type VideoFormat struct {
+	Resolution  VideoResolution
+	FPS         VideoFPS
+	AspectRatio AspectRatio
+	Orientation Orientation
+}
+
+const videoFormatLength = 4
+
+func (f VideoFormat) String() string {
+	return fmt.Sprintf("%s, %sfps, %s, %s", f.Resolution, f.FPS, f.AspectRatio, f.Orientation)
+}
+
+// The encodings used if the profile of the device does not define them
+// (see DeviceProfile); the values are experimental.
+var (
+	defaultVideoResolutionBytes = map[VideoResolution]byte{
+		VideoResolution1080p: 0x0A,
+		VideoResolution2_7K:  0x10,
+		VideoResolution4K:    0x16,
+	}
+	defaultVideoFPSBytes = map[VideoFPS]byte{
+		24: 0x01, 25: 0x02, 30: 0x03, 48: 0x04, 50: 0x05, 60: 0x06, 100: 0x07, 120: 0x08,
+	}
+	defaultAspectRatioBytes = map[AspectRatio]byte{
+		AspectRatio16x9: 0x00,
+		AspectRatio4x3:  0x01,
+	}
+	defaultOrientationBytes = map[Orientation]byte{
+		OrientationLandscape: 0x00,
+		OrientationPortrait:  0x01,
+	}
+)
+
+// videoFormatEncoding is the encoding of the fields of VideoFormat of a model.
+type videoFormatEncoding struct {
+	Resolutions  map[VideoResolution]byte
+	FPS          map[VideoFPS]byte
+	AspectRatios map[AspectRatio]byte
+	Orientations map[Orientation]byte
+}
+
+func (t DeviceType) videoFormatEncoding() videoFormatEncoding {
+	p, _ := t.Profile()
+	return videoFormatEncoding{
+		Resolutions:  profileEncoding(p.VideoResolutions, VideoResolutionFromString, defaultVideoResolutionBytes),
+		FPS:          profileEncoding(p.VideoFPS, VideoFPSFromString, defaultVideoFPSBytes),
+		AspectRatios: profileEncoding(p.AspectRatios, AspectRatioFromString, defaultAspectRatioBytes),
+		Orientations: profileEncoding(p.Orientations, OrientationFromString, defaultOrientationBytes),
+	}
+}
+
+// EncodeVideoFormat returns the value of KeyValueKeyVideoFormat, or an error
+// if any of the fields is not supported by the device type.
+func (t DeviceType) EncodeVideoFormat(f VideoFormat) ([]byte, error) {
+	enc := t.videoFormatEncoding()
+	b := make([]byte, videoFormatLength)
+	var ok bool
+	if b[0], ok = enc.Resolutions[f.Resolution]; !ok {
+		return nil, fmt.Errorf("resolution %s is not supported by %s (supported: %v)", f.Resolution, t, sortedKeys(enc.Resolutions))
+	}
+	if b[1], ok = enc.FPS[f.FPS]; !ok {
+		return nil, fmt.Errorf("FPS %s is not supported by %s (supported: %v)", f.FPS, t, sortedKeys(enc.FPS))
+	}
+	if b[2], ok = enc.AspectRatios[f.AspectRatio]; !ok {
+		return nil, fmt.Errorf("aspect ratio %s is not supported by %s (supported: %v)", f.AspectRatio, t, sortedKeys(enc.AspectRatios))
+	}
+	if b[3], ok = enc.Orientations[f.Orientation]; !ok {
+		return nil, fmt.Errorf("orientation %s is not supported by %s (supported: %v)", f.Orientation, t, sortedKeys(enc.Orientations))
+	}
+	return b, nil
+}
+
+// DecodeVideoFormat is the reverse of EncodeVideoFormat; the unknown values are
+// decoded as undefined (zero).
+func (t DeviceType) DecodeVideoFormat(b []byte) (VideoFormat, error) {
+	if len(b) != videoFormatLength {
+		return VideoFormat{}, fmt.Errorf("expected %d bytes, got %d: %X", videoFormatLength, len(b), b)
+	}
+	enc := t.videoFormatEncoding()
+	return VideoFormat{
+		Resolution:  decodeEnum(enc.Resolutions, b[0]),
+		FPS:         decodeEnum(enc.FPS, b[1]),
+		AspectRatio: decodeEnum(enc.AspectRatios, b[2]),
+		Orientation: decodeEnum(enc.Orientations, b[3]),
+	}, nil
+}
@@ -0,0 +1,66 @@
+package duml
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVideoFormatEncoding(t *testing.T) {
+	for r := VideoResolutionUndefined + 1; r < endOfVideoResolution; r++ {
+		require.Equal(t, r, VideoResolutionFromString(r.String()))
+	}
+	require.Equal(t, VideoResolution4K, VideoResolutionFromString("3840x2160"))
+	for _, f := range videoFPSes {
+		require.Equal(t, f, VideoFPSFromString(f.String()))
+	}
+	require.Equal(t, VideoFPS(60), VideoFPSFromString("60fps"))
+	require.Zero(t, VideoFPSFromString("59"))
+	require.Equal(t, AspectRatio4x3, AspectRatioFromString("4:3"))
+	require.Equal(t, OrientationPortrait, OrientationFromString("Portrait"))
+
+	format := VideoFormat{
+		Resolution:  VideoResolution4K,
+		FPS:         60,
+		AspectRatio: AspectRatio4x3,
+		Orientation: OrientationPortrait,
+	}
+	require.Equal(t, "4k, 60fps, 4:3, portrait", format.String())
+	for _, typ := range []DeviceType{DeviceTypeOsmoAction4, DeviceTypeOsmoAction5Pro, DeviceTypeMavic3} {
+		b, err := typ.EncodeVideoFormat(format)
+		require.NoError(t, err, typ)
+		decoded, err := typ.DecodeVideoFormat(b)
+		require.NoError(t, err)
+		require.Equal(t, format, decoded, typ)
+	}
+
+	b, err := DeviceTypeOsmoAction5Pro.EncodeVideoFormat(format)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x18, 0x06, 0x01, 0x01}, b)
+
+	_, err = DeviceTypeOsmoPocket3.EncodeVideoFormat(format)
+	require.ErrorContains(t, err, "aspect ratio 4:3 is not supported by osmo-pocket-3")
+	_, err = DeviceTypeOsmoAction4.EncodeVideoFormat(VideoFormat{Resolution: VideoResolution4K, FPS: 59, AspectRatio: AspectRatio16x9, Orientation: OrientationLandscape})
+	require.Error(t, err)
+
+	_, err = DeviceTypeOsmoAction4.DecodeVideoFormat([]byte{0x16})
+	require.Error(t, err)
+}
+
+func TestVideoFormatGoldenFrame(t *testing.T) {
+	b, err := DeviceTypeOsmoAction5Pro.EncodeVideoFormat(VideoFormat{
+		Resolution:  VideoResolution4K,
+		FPS:         60,
+		AspectRatio: AspectRatio4x3,
+		Orientation: OrientationPortrait,
+	})
+	require.NoError(t, err)
+	msg, err := NewMessage(InterfaceIDAppToCamera, 0x1234, MessageTypeStartStopStreaming, &KeyValueRequest{
+		Op:    KeyValueOpSet,
+		Items: []KeyValueItem{{Key: KeyValueKeyVideoFormat, Value: b}},
+	})
+	require.NoError(t, err)
+	// set (0x01), 1 item: key 0x0003, 4 bytes: 4k, 60fps, 4:3, portrait
+	require.Equal(t, "551604fc0201123440028e010103000418060101f2f9", hex.EncodeToString(msg.Bytes()))
+}
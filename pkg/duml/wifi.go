@@ -0,0 +1,74 @@
+package duml
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+// ConnectToWiFiRequest is the payload of MessageTypeConnectToWiFi.
+type ConnectToWiFiRequest struct {
+	SSID string
+	PSK  string
+}
+
+var _ Payload = (*ConnectToWiFiRequest)(nil)
+
+func (r *ConnectToWiFiRequest) MarshalDUML() ([]byte, error) {
+	if err := checkStringLength(r.SSID, math.MaxUint8); err != nil {
+		return nil, fmt.Errorf("invalid SSID: %w", err)
+	}
+	if err := checkStringLength(r.PSK, math.MaxUint8); err != nil {
+		return nil, fmt.Errorf("invalid PSK: %w", err)
+	}
+	var buf bytes.Buffer
+	must(buf.Write(PackString(r.SSID)))
+	must(buf.Write(PackString(r.PSK)))
+	return buf.Bytes(), nil
+}
+
+func (r *ConnectToWiFiRequest) UnmarshalDUML(b []byte) error {
+	ssid, b, err := UnpackString(b)
+	if err != nil {
+		return fmt.Errorf("unable to unpack SSID: %w", err)
+	}
+	psk, b, err := UnpackString(b)
+	if err != nil {
+		return fmt.Errorf("unable to unpack PSK: %w", err)
+	}
+	if len(b) != 0 {
+		return fmt.Errorf("unexpected %d trailing bytes: %X", len(b), b)
+	}
+	r.SSID, r.PSK = ssid, psk
+	return nil
+}
+
+// CameraAPInfoResult is the payload of MessageTypeCameraAPInfoResultSSID
+// and MessageTypeCameraAPInfoResultPSK.
+type CameraAPInfoResult struct {
+	Value string
+}
+
+var _ Payload = (*CameraAPInfoResult)(nil)
+
+func (r *CameraAPInfoResult) MarshalDUML() ([]byte, error) {
+	if err := checkStringLength(r.Value, math.MaxUint16); err != nil {
+		return nil, err
+	}
+	return PackStringU16BE(r.Value), nil
+}
+
+func (r *CameraAPInfoResult) UnmarshalDUML(b []byte) error {
+	v, err := UnpackStringU16BE(b)
+	if err != nil {
+		return err
+	}
+	r.Value = v
+	return nil
+}
+
+func init() {
+	RegisterPayload(MessageTypeConnectToWiFi, func() Payload { return &ConnectToWiFiRequest{} })
+	RegisterPayload(MessageTypeCameraAPInfoResultSSID, func() Payload { return &CameraAPInfoResult{} })
+	RegisterPayload(MessageTypeCameraAPInfoResultPSK, func() Payload { return &CameraAPInfoResult{} })
+}
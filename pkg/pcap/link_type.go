@@ -0,0 +1,37 @@
+package pcap
+
+import "fmt"
+
+// LinkType is a link-layer header type, see https://www.tcpdump.org/linktypes.html
+type LinkType uint32
+
+const (
+	LinkTypeNull      = LinkType(0)
+	LinkTypeEthernet  = LinkType(1)
+	LinkTypeRaw       = LinkType(101)
+	LinkTypeLinuxSLL  = LinkType(113)
+	LinkTypeIPv4      = LinkType(228)
+	LinkTypeIPv6      = LinkType(229)
+	LinkTypeLinuxSLL2 = LinkType(276)
+)
+
+func (t LinkType) String() string {
+	switch t {
+	case LinkTypeNull:
+		return "null"
+	case LinkTypeEthernet:
+		return "ethernet"
+	case LinkTypeRaw:
+		return "raw"
+	case LinkTypeLinuxSLL:
+		return "linux_sll"
+	case LinkTypeIPv4:
+		return "ipv4"
+	case LinkTypeIPv6:
+		return "ipv6"
+	case LinkTypeLinuxSLL2:
+		return "linux_sll2"
+	default:
+		return fmt.Sprintf("%d", uint32(t))
+	}
+}
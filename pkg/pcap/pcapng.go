@@ -0,0 +1,170 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	blockTypeInterfaceDescription = 0x00000001
+	blockTypeSimplePacket         = 0x00000003
+	blockTypeEnhancedPacket       = 0x00000006
+
+	byteOrderMagic = 0x1A2B3C4D
+
+	optionEndOfOpt   = 0
+	optionIfTSResol  = 9
+	defaultTSResolNs = 1000
+)
+
+type ngInterface struct {
+	linkType    LinkType
+	tsPerSecond uint64
+}
+
+type ngReader struct {
+	r          io.Reader
+	order      binary.ByteOrder
+	interfaces []ngInterface
+}
+
+func newNGReader(r io.Reader) *ngReader {
+	return &ngReader{r: r, order: binary.LittleEndian}
+}
+
+func (r *ngReader) ReadPacket() (*Packet, error) {
+	for {
+		blockType, body, err := r.readBlock()
+		if err != nil {
+			return nil, err
+		}
+		switch blockType {
+		case blockTypeSectionHeader:
+			r.interfaces = r.interfaces[:0]
+		case blockTypeInterfaceDescription:
+			if err := r.parseInterfaceDescription(body); err != nil {
+				return nil, fmt.Errorf("unable to parse an interface description block: %w", err)
+			}
+		case blockTypeEnhancedPacket:
+			return r.parseEnhancedPacket(body)
+		case blockTypeSimplePacket:
+			if len(body) < 4 || len(r.interfaces) == 0 {
+				return nil, fmt.Errorf("invalid simple packet block")
+			}
+			origLen := r.order.Uint32(body[0:4])
+			data := body[4:]
+			if uint32(len(data)) > origLen {
+				data = data[:origLen]
+			}
+			return &Packet{
+				LinkType: r.interfaces[0].linkType,
+				Data:     data,
+			}, nil
+		}
+	}
+}
+
+func (r *ngReader) readBlock() (uint32, []byte, error) {
+	var hdr [8]byte
+	if _, err := io.ReadFull(r.r, hdr[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return 0, nil, fmt.Errorf("unable to read the block header: %w", err)
+		}
+		return 0, nil, err
+	}
+
+	blockType := r.order.Uint32(hdr[0:4])
+	if blockType == blockTypeSectionHeader {
+		// the section header defines the byte order (the block type is a palindrome),
+		// so we need to read the byte-order magic before the length
+		var bom [4]byte
+		if _, err := io.ReadFull(r.r, bom[:]); err != nil {
+			return 0, nil, fmt.Errorf("unable to read the byte-order magic: %w", err)
+		}
+		switch {
+		case binary.LittleEndian.Uint32(bom[:]) == byteOrderMagic:
+			r.order = binary.LittleEndian
+		case binary.BigEndian.Uint32(bom[:]) == byteOrderMagic:
+			r.order = binary.BigEndian
+		default:
+			return 0, nil, fmt.Errorf("invalid byte-order magic: %X", bom)
+		}
+		totalLength := r.order.Uint32(hdr[4:8])
+		if totalLength < 16 {
+			return 0, nil, fmt.Errorf("invalid section header length: %d", totalLength)
+		}
+		if _, err := io.CopyN(io.Discard, r.r, int64(totalLength)-12); err != nil {
+			return 0, nil, fmt.Errorf("unable to read the section header: %w", err)
+		}
+		return blockTypeSectionHeader, nil, nil
+	}
+
+	totalLength := r.order.Uint32(hdr[4:8])
+	if totalLength < 12 || totalLength%4 != 0 {
+		return 0, nil, fmt.Errorf("invalid block length: %d", totalLength)
+	}
+	b := make([]byte, totalLength-8)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		return 0, nil, fmt.Errorf("unable to read a block of length %d: %w", totalLength, err)
+	}
+	return blockType, b[:len(b)-4], nil
+}
+
+func (r *ngReader) parseInterfaceDescription(body []byte) error {
+	if len(body) < 8 {
+		return fmt.Errorf("too short: %d", len(body))
+	}
+	iface := ngInterface{
+		linkType:    LinkType(r.order.Uint16(body[0:2])),
+		tsPerSecond: 1_000_000,
+	}
+	opts := body[8:]
+	for len(opts) >= 4 {
+		code := r.order.Uint16(opts[0:2])
+		length := int(r.order.Uint16(opts[2:4]))
+		if code == optionEndOfOpt {
+			break
+		}
+		if len(opts) < 4+length {
+			return fmt.Errorf("option %d is truncated", code)
+		}
+		value := opts[4 : 4+length]
+		if code == optionIfTSResol && length >= 1 {
+			resol := value[0]
+			if resol&0x80 != 0 {
+				iface.tsPerSecond = 1 << (resol & 0x7F)
+			} else {
+				iface.tsPerSecond = uint64(math.Pow10(int(resol)))
+			}
+		}
+		opts = opts[4+(length+3)/4*4:]
+	}
+	r.interfaces = append(r.interfaces, iface)
+	return nil
+}
+
+func (r *ngReader) parseEnhancedPacket(body []byte) (*Packet, error) {
+	if len(body) < 20 {
+		return nil, fmt.Errorf("enhanced packet block is too short: %d", len(body))
+	}
+	ifaceID := r.order.Uint32(body[0:4])
+	if int(ifaceID) >= len(r.interfaces) {
+		return nil, fmt.Errorf("unknown interface ID: %d", ifaceID)
+	}
+	iface := r.interfaces[ifaceID]
+	ts := uint64(r.order.Uint32(body[4:8]))<<32 | uint64(r.order.Uint32(body[8:12]))
+	capLen := r.order.Uint32(body[12:16])
+	if int(capLen) > len(body)-20 {
+		return nil, fmt.Errorf("captured length is out of the block: %d > %d", capLen, len(body)-20)
+	}
+	sec := ts / iface.tsPerSecond
+	nsec := (ts % iface.tsPerSecond) * uint64(time.Second) / iface.tsPerSecond
+	return &Packet{
+		Timestamp: time.Unix(int64(sec), int64(nsec)),
+		LinkType:  iface.linkType,
+		Data:      body[20 : 20+capLen],
+	}, nil
+}
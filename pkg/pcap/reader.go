@@ -0,0 +1,112 @@
+// Package pcap implements a minimal reader of classic pcap and pcapng
+// capture files, sufficient to extract UDP datagrams sent to/from DJI devices.
+package pcap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	magicMicroseconds        = 0xa1b2c3d4
+	magicNanoseconds         = 0xa1b23c4d
+	magicMicrosecondsSwapped = 0xd4c3b2a1
+	magicNanosecondsSwapped  = 0x4d3cb2a1
+
+	blockTypeSectionHeader = 0x0A0D0D0A
+)
+
+// Packet is a single captured packet.
+type Packet struct {
+	Timestamp time.Time
+	LinkType  LinkType
+	Data      []byte
+}
+
+// Reader reads packets from a capture file.
+type Reader interface {
+	// ReadPacket returns the next packet or io.EOF.
+	ReadPacket() (*Packet, error)
+}
+
+// NewReader detects the format (pcap or pcapng) and returns the Reader for it.
+func NewReader(r io.Reader) (Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(4)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the magic: %w", err)
+	}
+	if binary.LittleEndian.Uint32(magic) == blockTypeSectionHeader {
+		return newNGReader(br), nil
+	}
+	return newClassicReader(br)
+}
+
+// IsPCAP returns true if the given file beginning looks like a pcap or pcapng file.
+func IsPCAP(b []byte) bool {
+	if len(b) < 4 {
+		return false
+	}
+	switch binary.LittleEndian.Uint32(b) {
+	case blockTypeSectionHeader,
+		magicMicroseconds, magicNanoseconds,
+		magicMicrosecondsSwapped, magicNanosecondsSwapped:
+		return true
+	default:
+		return false
+	}
+}
+
+type classicReader struct {
+	r         io.Reader
+	order     binary.ByteOrder
+	nsPerTick int64
+	linkType  LinkType
+}
+
+func newClassicReader(r io.Reader) (*classicReader, error) {
+	var hdr [24]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, fmt.Errorf("unable to read the pcap header: %w", err)
+	}
+	result := &classicReader{r: r}
+	switch binary.LittleEndian.Uint32(hdr[:4]) {
+	case magicMicroseconds:
+		result.order, result.nsPerTick = binary.LittleEndian, 1000
+	case magicNanoseconds:
+		result.order, result.nsPerTick = binary.LittleEndian, 1
+	case magicMicrosecondsSwapped:
+		result.order, result.nsPerTick = binary.BigEndian, 1000
+	case magicNanosecondsSwapped:
+		result.order, result.nsPerTick = binary.BigEndian, 1
+	default:
+		return nil, fmt.Errorf("unknown pcap magic: %X", hdr[:4])
+	}
+	result.linkType = LinkType(result.order.Uint32(hdr[20:24]) & 0x0FFFFFFF)
+	return result, nil
+}
+
+func (r *classicReader) ReadPacket() (*Packet, error) {
+	var hdr [16]byte
+	if _, err := io.ReadFull(r.r, hdr[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("unable to read the packet header: %w", err)
+		}
+		return nil, err
+	}
+	tsSec := r.order.Uint32(hdr[0:4])
+	tsFrac := r.order.Uint32(hdr[4:8])
+	capLen := r.order.Uint32(hdr[8:12])
+	data := make([]byte, capLen)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return nil, fmt.Errorf("unable to read the packet of length %d: %w", capLen, err)
+	}
+	return &Packet{
+		Timestamp: time.Unix(int64(tsSec), int64(tsFrac)*r.nsPerTick),
+		LinkType:  r.linkType,
+		Data:      data,
+	}, nil
+}
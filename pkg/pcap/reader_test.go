@@ -0,0 +1,131 @@
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testEthernetUDPFrame(payload []byte) []byte {
+	udp := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint16(udp[0:2], 50000)
+	binary.BigEndian.PutUint16(udp[2:4], 9004)
+	binary.BigEndian.PutUint16(udp[4:6], uint16(8+len(payload)))
+	udp = append(udp, payload...)
+
+	ip := make([]byte, 20, 20+len(udp))
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:4], uint16(20+len(udp)))
+	ip[8] = 64
+	ip[9] = ipProtocolUDP
+	copy(ip[12:16], net.IPv4(192, 168, 2, 10).To4())
+	copy(ip[16:20], net.IPv4(192, 168, 2, 1).To4())
+	ip = append(ip, udp...)
+
+	eth := make([]byte, 14, 14+len(ip))
+	binary.BigEndian.PutUint16(eth[12:14], etherTypeIPv4)
+	return append(eth, ip...)
+}
+
+func testClassicPCAP(ts time.Time, frame []byte) []byte {
+	var buf bytes.Buffer
+	hdr := make([]byte, 24)
+	binary.LittleEndian.PutUint32(hdr[0:4], magicNanoseconds)
+	binary.LittleEndian.PutUint16(hdr[4:6], 2)
+	binary.LittleEndian.PutUint16(hdr[6:8], 4)
+	binary.LittleEndian.PutUint32(hdr[16:20], 65535)
+	binary.LittleEndian.PutUint32(hdr[20:24], uint32(LinkTypeEthernet))
+	buf.Write(hdr)
+
+	pktHdr := make([]byte, 16)
+	binary.LittleEndian.PutUint32(pktHdr[0:4], uint32(ts.Unix()))
+	binary.LittleEndian.PutUint32(pktHdr[4:8], uint32(ts.Nanosecond()))
+	binary.LittleEndian.PutUint32(pktHdr[8:12], uint32(len(frame)))
+	binary.LittleEndian.PutUint32(pktHdr[12:16], uint32(len(frame)))
+	buf.Write(pktHdr)
+	buf.Write(frame)
+	return buf.Bytes()
+}
+
+func testNGBlock(blockType uint32, body []byte) []byte {
+	for len(body)%4 != 0 {
+		body = append(body, 0)
+	}
+	b := make([]byte, 8, 12+len(body))
+	binary.BigEndian.PutUint32(b[0:4], blockType)
+	binary.BigEndian.PutUint32(b[4:8], uint32(12+len(body)))
+	b = append(b, body...)
+	return binary.BigEndian.AppendUint32(b, uint32(12+len(body)))
+}
+
+// testPCAPNG builds a big-endian pcapng file to also cover the byte order detection.
+func testPCAPNG(ts time.Time, frame []byte) []byte {
+	var buf bytes.Buffer
+
+	shb := make([]byte, 16)
+	binary.BigEndian.PutUint32(shb[0:4], byteOrderMagic)
+	binary.BigEndian.PutUint16(shb[4:6], 1)
+	binary.BigEndian.PutUint64(shb[8:16], ^uint64(0))
+	buf.Write(testNGBlock(blockTypeSectionHeader, shb))
+
+	idb := make([]byte, 8)
+	binary.BigEndian.PutUint16(idb[0:2], uint16(LinkTypeEthernet))
+	idb = append(idb, 0, optionIfTSResol, 0, 1, 9, 0, 0, 0) // nanoseconds
+	idb = append(idb, 0, 0, 0, 0)
+	buf.Write(testNGBlock(blockTypeInterfaceDescription, idb))
+
+	buf.Write(testNGBlock(0x0BAD, []byte{1, 2, 3, 4}))
+
+	tsNs := uint64(ts.UnixNano())
+	epb := make([]byte, 20, 20+len(frame))
+	binary.BigEndian.PutUint32(epb[4:8], uint32(tsNs>>32))
+	binary.BigEndian.PutUint32(epb[8:12], uint32(tsNs))
+	binary.BigEndian.PutUint32(epb[12:16], uint32(len(frame)))
+	binary.BigEndian.PutUint32(epb[16:20], uint32(len(frame)))
+	epb = append(epb, frame...)
+	buf.Write(testNGBlock(blockTypeEnhancedPacket, epb))
+	return buf.Bytes()
+}
+
+func TestReader(t *testing.T) {
+	ts := time.Unix(1700000000, 123456789)
+	payload := []byte{0x55, 0x0D, 0x04}
+	frame := testEthernetUDPFrame(payload)
+
+	for name, file := range map[string][]byte{
+		"pcap":   testClassicPCAP(ts, frame),
+		"pcapng": testPCAPNG(ts, frame),
+	} {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewReader(bytes.NewReader(file))
+			require.NoError(t, err)
+
+			pkt, err := r.ReadPacket()
+			require.NoError(t, err)
+			require.Equal(t, LinkTypeEthernet, pkt.LinkType)
+			require.True(t, ts.Equal(pkt.Timestamp), pkt.Timestamp)
+			require.Equal(t, frame, pkt.Data)
+
+			udp, err := pkt.UDP()
+			require.NoError(t, err)
+			require.Equal(t, "192.168.2.10:50000", udp.Src.String())
+			require.Equal(t, "192.168.2.1:9004", udp.Dst.String())
+			require.Equal(t, payload, udp.Payload)
+
+			_, err = r.ReadPacket()
+			require.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func TestReaderInvalidMagic(t *testing.T) {
+	require.False(t, IsPCAP([]byte("55 1f 04")))
+	require.True(t, IsPCAP(testClassicPCAP(time.Now(), nil)))
+	_, err := NewReader(bytes.NewReader(make([]byte, 24)))
+	require.Error(t, err)
+}
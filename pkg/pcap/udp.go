@@ -0,0 +1,136 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	etherTypeIPv4 = 0x0800
+	etherTypeIPv6 = 0x86DD
+	etherTypeVLAN = 0x8100
+
+	ipProtocolUDP = 17
+)
+
+// UDPDatagram is a UDP datagram extracted from a Packet.
+type UDPDatagram struct {
+	Timestamp time.Time
+	Src       net.UDPAddr
+	Dst       net.UDPAddr
+	Payload   []byte
+}
+
+// UDP extracts the UDP datagram from the packet.
+//
+// IP fragments are not reassembled.
+func (p *Packet) UDP() (*UDPDatagram, error) {
+	ipPacket, err := p.ipPacket()
+	if err != nil {
+		return nil, err
+	}
+	if len(ipPacket) < 1 {
+		return nil, fmt.Errorf("empty IP packet")
+	}
+
+	var (
+		d       = UDPDatagram{Timestamp: p.Timestamp}
+		payload []byte
+	)
+	switch ipPacket[0] >> 4 {
+	case 4:
+		if len(ipPacket) < 20 {
+			return nil, fmt.Errorf("IPv4 packet is too short: %d", len(ipPacket))
+		}
+		ihl := int(ipPacket[0]&0x0F) * 4
+		if ihl < 20 || len(ipPacket) < ihl {
+			return nil, fmt.Errorf("invalid IPv4 header length: %d", ihl)
+		}
+		if ipPacket[9] != ipProtocolUDP {
+			return nil, fmt.Errorf("not a UDP packet: protocol %d", ipPacket[9])
+		}
+		if binary.BigEndian.Uint16(ipPacket[6:8])&0x1FFF != 0 {
+			return nil, fmt.Errorf("a non-first IPv4 fragment")
+		}
+		d.Src.IP = net.IP(ipPacket[12:16])
+		d.Dst.IP = net.IP(ipPacket[16:20])
+		if totalLength := int(binary.BigEndian.Uint16(ipPacket[2:4])); totalLength >= ihl && totalLength <= len(ipPacket) {
+			ipPacket = ipPacket[:totalLength]
+		}
+		payload = ipPacket[ihl:]
+	case 6:
+		if len(ipPacket) < 40 {
+			return nil, fmt.Errorf("IPv6 packet is too short: %d", len(ipPacket))
+		}
+		if ipPacket[6] != ipProtocolUDP {
+			return nil, fmt.Errorf("not a UDP packet (or has extension headers): next header %d", ipPacket[6])
+		}
+		d.Src.IP = net.IP(ipPacket[8:24])
+		d.Dst.IP = net.IP(ipPacket[24:40])
+		payload = ipPacket[40:]
+	default:
+		return nil, fmt.Errorf("unknown IP version: %d", ipPacket[0]>>4)
+	}
+
+	if len(payload) < 8 {
+		return nil, fmt.Errorf("UDP datagram is too short: %d", len(payload))
+	}
+	d.Src.Port = int(binary.BigEndian.Uint16(payload[0:2]))
+	d.Dst.Port = int(binary.BigEndian.Uint16(payload[2:4]))
+	length := int(binary.BigEndian.Uint16(payload[4:6]))
+	if length < 8 || length > len(payload) {
+		length = len(payload)
+	}
+	d.Payload = payload[8:length]
+	return &d, nil
+}
+
+func (p *Packet) ipPacket() ([]byte, error) {
+	b := p.Data
+	switch p.LinkType {
+	case LinkTypeRaw, LinkTypeIPv4, LinkTypeIPv6:
+		return b, nil
+	case LinkTypeNull:
+		if len(b) < 4 {
+			return nil, fmt.Errorf("too short packet: %d", len(b))
+		}
+		return b[4:], nil
+	case LinkTypeEthernet:
+		if len(b) < 14 {
+			return nil, fmt.Errorf("too short Ethernet frame: %d", len(b))
+		}
+		etherType := binary.BigEndian.Uint16(b[12:14])
+		b = b[14:]
+		for etherType == etherTypeVLAN {
+			if len(b) < 4 {
+				return nil, fmt.Errorf("too short VLAN header: %d", len(b))
+			}
+			etherType = binary.BigEndian.Uint16(b[2:4])
+			b = b[4:]
+		}
+		return checkEtherType(etherType, b)
+	case LinkTypeLinuxSLL:
+		if len(b) < 16 {
+			return nil, fmt.Errorf("too short SLL header: %d", len(b))
+		}
+		return checkEtherType(binary.BigEndian.Uint16(b[14:16]), b[16:])
+	case LinkTypeLinuxSLL2:
+		if len(b) < 20 {
+			return nil, fmt.Errorf("too short SLL2 header: %d", len(b))
+		}
+		return checkEtherType(binary.BigEndian.Uint16(b[0:2]), b[20:])
+	default:
+		return nil, fmt.Errorf("unsupported link type: %s", p.LinkType)
+	}
+}
+
+func checkEtherType(etherType uint16, b []byte) ([]byte, error) {
+	switch etherType {
+	case etherTypeIPv4, etherTypeIPv6:
+		return b, nil
+	default:
+		return nil, fmt.Errorf("not an IP packet: ethertype 0x%04X", etherType)
+	}
+}